@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/internal/ui"
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	compareModels []string
+	compareLayout string
+	compareFormat string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <query>",
+	Short: "Ask several models the same question side by side",
+	Long: `Run the same query against several models concurrently and show the
+answers next to each other with each model's latency and source count.
+
+Answers are shown in columns when the terminal is wide enough and stacked
+otherwise. Reasoning models run in reasoning mode and the others in pro mode
+unless --mode is given. All answers are saved to the history as one
+comparison group.
+
+Examples:
+  perplexity compare "What is Go?" --models gpt51,claude45sonnet,gemini30pro
+  perplexity compare "Explain CRDTs" --models gpt51,grok41reasoning --layout stacked
+  perplexity compare "Latest Go release" --models gpt51,claude45sonnet --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCompare,
+}
+
+// compareSearchFunc performs a single non-streaming search.
+type compareSearchFunc func(ctx context.Context, opts models.SearchOptions) (*models.SearchResponse, error)
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	if compareFormat != "text" && compareFormat != "json" {
+		return fmt.Errorf("invalid format: %s (valid: text, json)", compareFormat)
+	}
+	if compareLayout != "auto" && compareLayout != "columns" && compareLayout != "stacked" {
+		return fmt.Errorf("invalid layout: %s (valid: auto, columns, stacked)", compareLayout)
+	}
+
+	modelList, err := parseCompareModels(compareModels)
+	if err != nil {
+		return err
+	}
+
+	// Each answer sets its own model, so default_model is never sent
+	opts := buildSearchOptions(withPromptPrefix(strings.Join(args, " ")))
+	opts.Model = ""
+	opts.Filters, err = buildSearchFilters(time.Now())
+	if err != nil {
+		return err
+	}
+	if err := validateSearchOptions(opts); err != nil {
+		return err
+	}
+	if err := validateCompareModes(modelList, opts.Mode, flagMode != ""); err != nil {
+		return err
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	var done chan struct{}
+	if compareFormat == "text" {
+		render.RenderInfo(fmt.Sprintf("Asking %d models...", len(modelList)))
+		done = make(chan struct{})
+		go func() {
+			frame := 0
+			for {
+				select {
+				case <-done:
+					render.ClearLine()
+					return
+				case <-time.After(100 * time.Millisecond):
+					render.RenderSpinner(frame)
+					frame++
+				}
+			}
+		}()
+	}
+
+	cmp := runComparison(ctx, cli.Search, opts, modelList, flagMode != "")
+	if done != nil {
+		close(done)
+	}
+
+	if !opts.Incognito {
+		cmp.Group = uuid.NewString()
+		saveComparisonHistory(opts, cmp)
+	}
+
+	if compareFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cmp); err != nil {
+			return fmt.Errorf("failed to encode comparison: %v", err)
+		}
+	} else if width := terminalWidth(); useComparisonColumns(compareLayout, len(cmp.Answers), width) {
+		render.RenderComparisonColumns(cmp, width)
+	} else if err := render.RenderComparisonStacked(cmp); err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	for _, a := range cmp.Answers {
+		if a.Error == "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("all %d models failed", len(cmp.Answers))
+}
+
+// parseCompareModels validates and deduplicates the --models values.
+func parseCompareModels(raw []string) ([]models.Model, error) {
+	var list []models.Model
+	seen := make(map[models.Model]bool)
+	for _, r := range raw {
+		name := strings.TrimSpace(r)
+		if name == "" {
+			continue
+		}
+		m, ok := models.ResolveModel(name)
+		if !ok {
+			return nil, validateModel(name)
+		}
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		list = append(list, m)
+	}
+
+	if len(list) < 2 {
+		return nil, fmt.Errorf("at least two models are required (e.g. --models gpt51,claude45sonnet)")
+	}
+	return list, nil
+}
+
+// compareMode returns the mode a model runs in: the first mode it supports
+// in the catalog, or pro mode.
+func compareMode(m models.Model) models.Mode {
+	if info, ok := models.ActiveCatalog().Model(string(m)); ok && len(info.Modes) > 0 {
+		return info.Modes[0]
+	}
+	return models.ModePro
+}
+
+// validateCompareModes rejects models that would not run as asked in the
+// mode they are compared in: mode with keepMode set, their own otherwise.
+func validateCompareModes(modelList []models.Model, mode models.Mode, keepMode bool) error {
+	for _, m := range modelList {
+		modelMode := mode
+		if !keepMode {
+			modelMode = compareMode(m)
+		}
+		if w := modeCompatibilityWarning(m, modelMode); w != "" {
+			return fmt.Errorf("cannot compare %s in %s mode: %s", m, modelMode, w)
+		}
+	}
+	return nil
+}
+
+// runComparison asks every model concurrently and returns the answers in
+// the order of modelList. keepMode uses base.Mode for all models.
+func runComparison(ctx context.Context, search compareSearchFunc, base models.SearchOptions, modelList []models.Model, keepMode bool) *models.Comparison {
+	cmp := &models.Comparison{
+		Query:   base.Query,
+		Answers: make([]models.ComparisonAnswer, len(modelList)),
+	}
+
+	var wg sync.WaitGroup
+	for i, m := range modelList {
+		opts := base
+		opts.Model = m
+		opts.Stream = false
+		if !keepMode {
+			opts.Mode = compareMode(m)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			started := time.Now()
+			resp, err := search(ctx, opts)
+
+			answer := models.ComparisonAnswer{
+				Model:      opts.Model,
+				Mode:       opts.Mode,
+				DurationMS: time.Since(started).Milliseconds(),
+				Status:     models.HistoryStatusOK,
+			}
+			switch {
+			case errors.Is(err, context.Canceled):
+				answer.Status = models.HistoryStatusCancelled
+				answer.Error = "cancelled"
+			case err != nil:
+				answer.Status = models.HistoryStatusError
+				answer.Error = err.Error()
+			case resp != nil:
+				answer.Answer = resp.Text
+				answer.WebResults = resp.WebResults
+				answer.BackendUUID = resp.BackendUUID
+			}
+			cmp.Answers[i] = answer
+		}()
+	}
+	wg.Wait()
+
+	return cmp
+}
+
+// saveComparisonHistory records every answer of a comparison under its group.
+func saveComparisonHistory(base models.SearchOptions, cmp *models.Comparison) {
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		return
+	}
+	hw.SetRetention(historyRetention())
+
+	for _, a := range cmp.Answers {
+		opts := base
+		opts.Model = a.Model
+		opts.Mode = a.Mode
+
+		entry := newHistoryEntry(opts, &searchResult{
+			Text:        a.Answer,
+			WebResults:  a.WebResults,
+			BackendUUID: a.BackendUUID,
+		}, a.Duration(), nil)
+		entry.Status = a.Status
+		if a.Status == models.HistoryStatusError {
+			entry.Error = a.Error
+		}
+		entry.Group = cmp.Group
+
+		if err := hw.Append(entry); err != nil {
+			render.RenderWarning(fmt.Sprintf("Failed to save history: %v", err))
+			return
+		}
+	}
+}
+
+// useComparisonColumns reports whether n answers should be rendered side by side.
+func useComparisonColumns(layout string, n, width int) bool {
+	switch layout {
+	case "columns":
+		return true
+	case "stacked":
+		return false
+	}
+	return n > 0 && width >= n*ui.MinComparisonColumnWidth
+}
+
+// terminalWidth returns the width of stdout, or 80 when it is not a terminal.
+func terminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
+
+func init() {
+	compareCmd.Flags().StringSliceVar(&compareModels, "models", nil, "Models to compare (comma-separated or repeatable)")
+	compareCmd.Flags().StringVar(&compareLayout, "layout", "auto", "Layout (auto, columns, stacked)")
+	compareCmd.Flags().StringVar(&compareFormat, "format", "text", "Output format (text, json)")
+	compareCmd.Flags().StringVar(&flagMode, "mode", "", "Search mode for all models (default: reasoning for reasoning models, pro otherwise)")
+	compareCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Search sources (web,scholar,social)")
+	compareCmd.Flags().StringSliceVar(&flagSites, "site", nil, "Restrict sources to these domains (repeatable or comma-separated)")
+	compareCmd.Flags().StringSliceVar(&flagExclude, "exclude-site", nil, "Exclude sources from these domains (repeatable or comma-separated)")
+	compareCmd.Flags().StringVar(&flagSince, "since", "", "Only use sources published since a relative age (7d, 2w, 3mo, 1y) or date (2024-01-01)")
+	compareCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Response language (e.g., en-US, pt-BR)")
+	compareCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Don't save to history")
+	compareCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Path to cookies.json file")
+	compareCmd.MarkFlagRequired("models")
+	compareCmd.RegisterFlagCompletionFunc("models", completeModelList)
+	compareCmd.RegisterFlagCompletionFunc("mode", completeModes)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestParseCompareModels(t *testing.T) {
+	list, err := parseCompareModels([]string{"gpt51", " claude ", "gpt-5.1", ""})
+	if err != nil {
+		t.Fatalf("parseCompareModels() error = %v", err)
+	}
+	if len(list) != 2 || list[0] != models.ModelGPT51 || list[1] != models.ModelClaude45Sonnet {
+		t.Errorf("list = %v", list)
+	}
+
+	if _, err := parseCompareModels([]string{"gpt51", "nope"}); err == nil {
+		t.Error("expected error for invalid model")
+	}
+	if _, err := parseCompareModels([]string{"gpt51", "gpt51"}); err == nil {
+		t.Error("expected error for a single model")
+	}
+}
+
+func TestValidateCompareModes(t *testing.T) {
+	both := []models.Model{models.ModelGPT51, models.ModelGemini30Pro}
+
+	if err := validateCompareModes(both, models.ModePro, false); err != nil {
+		t.Errorf("own modes: error = %v", err)
+	}
+	if err := validateCompareModes(both, models.ModeReasoning, true); err == nil || !strings.Contains(err.Error(), "cannot compare gpt51 in reasoning mode") {
+		t.Errorf("--mode reasoning: error = %v, want gpt51 rejected", err)
+	}
+	if err := validateCompareModes(both, models.ModePro, true); err == nil || !strings.Contains(err.Error(), string(models.ModelGemini30Pro)) {
+		t.Errorf("--mode pro: error = %v, want %s rejected", err, models.ModelGemini30Pro)
+	}
+	if err := validateCompareModes(both, models.ModeFast, true); err == nil || !strings.Contains(err.Error(), "always uses") {
+		t.Errorf("--mode fast: error = %v, want the fixed-model mode rejected", err)
+	}
+}
+
+func TestRunComparison(t *testing.T) {
+	var mu sync.Mutex
+	var calls []models.SearchOptions
+	search := func(ctx context.Context, opts models.SearchOptions) (*models.SearchResponse, error) {
+		mu.Lock()
+		calls = append(calls, opts)
+		mu.Unlock()
+
+		if opts.Model == models.ModelGrok41Reasoning {
+			return nil, errors.New("rate limited")
+		}
+		// Make the first model the slowest so order does not follow completion
+		if opts.Model == models.ModelGPT51 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return &models.SearchResponse{
+			Text:       "answer from " + string(opts.Model),
+			WebResults: []models.WebResult{{URL: "https://go.dev"}},
+		}, nil
+	}
+
+	base := models.DefaultSearchOptions("What is Go?")
+	base.Stream = true
+	list := []models.Model{models.ModelGPT51, models.ModelGemini30Pro, models.ModelGrok41Reasoning}
+
+	cmp := runComparison(context.Background(), search, base, list, false)
+
+	if len(calls) != 3 {
+		t.Fatalf("search called %d times, want 3", len(calls))
+	}
+	for _, c := range calls {
+		if c.Stream {
+			t.Errorf("search for %s should not stream", c.Model)
+		}
+	}
+
+	if cmp.Query != "What is Go?" || len(cmp.Answers) != 3 {
+		t.Fatalf("cmp = %+v", cmp)
+	}
+	first := cmp.Answers[0]
+	if first.Model != models.ModelGPT51 || first.Mode != models.ModePro || first.Answer != "answer from gpt51" {
+		t.Errorf("first answer = %+v", first)
+	}
+	if first.DurationMS < 20 {
+		t.Errorf("first DurationMS = %d, want >= 20", first.DurationMS)
+	}
+	if cmp.Answers[1].Mode != models.ModeReasoning || len(cmp.Answers[1].WebResults) != 1 {
+		t.Errorf("second answer = %+v", cmp.Answers[1])
+	}
+	if failed := cmp.Answers[2]; failed.Status != models.HistoryStatusError || failed.Error != "rate limited" {
+		t.Errorf("failed answer = %+v", failed)
+	}
+
+	base.Mode = models.ModeFast
+	cmp = runComparison(context.Background(), search, base, list[:2], true)
+	for _, a := range cmp.Answers {
+		if a.Mode != models.ModeFast {
+			t.Errorf("%s mode = %s, want fast", a.Model, a.Mode)
+		}
+	}
+}
+
+func TestSaveComparisonHistory(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cmp := &models.Comparison{
+		Query: "What is Go?",
+		Group: "group-1",
+		Answers: []models.ComparisonAnswer{
+			{Model: models.ModelGPT51, Mode: models.ModePro, Answer: "A language.", DurationMS: 1200, Status: models.HistoryStatusOK},
+			{Model: models.ModelGemini30Pro, Mode: models.ModeReasoning, DurationMS: 300, Status: models.HistoryStatusError, Error: "boom"},
+		},
+	}
+	saveComparisonHistory(models.DefaultSearchOptions(cmp.Query), cmp)
+
+	entries, err := history.NewReader(cfg.HistoryFile).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if e.Group != "group-1" || e.Query != "What is Go?" {
+			t.Errorf("entry = %+v", e)
+		}
+	}
+	if entries[0].Model != "gpt51" || entries[0].Response != "A language." || entries[0].DurationMS != 1200 {
+		t.Errorf("first entry = %+v", entries[0])
+	}
+	if entries[1].Mode != "reasoning" || entries[1].Status != models.HistoryStatusError || entries[1].Error != "boom" {
+		t.Errorf("second entry = %+v", entries[1])
+	}
+}
+
+func TestUseComparisonColumns(t *testing.T) {
+	tests := []struct {
+		layout string
+		n      int
+		width  int
+		want   bool
+	}{
+		{"auto", 3, 160, true},
+		{"auto", 3, 100, false},
+		{"auto", 2, 80, true},
+		{"columns", 3, 60, true},
+		{"stacked", 2, 200, false},
+	}
+
+	for _, tt := range tests {
+		if got := useComparisonColumns(tt.layout, tt.n, tt.width); got != tt.want {
+			t.Errorf("useComparisonColumns(%q, %d, %d) = %v, want %v", tt.layout, tt.n, tt.width, got, tt.want)
+		}
+	}
+}
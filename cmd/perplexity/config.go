@@ -1,12 +1,22 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 
+	"github.com/diogo/perplexity-go/internal/config"
 	"github.com/diogo/perplexity-go/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	configListJSON      bool
+	configListEffective bool
+)
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage configuration interactively",
@@ -15,12 +25,18 @@ var configCmd = &cobra.Command{
 Use arrow keys to navigate, Enter to select an option, and Esc to cancel.
 
 Configuration options:
-  - Model:     Default AI model (pplx_pro, gpt5, claude45sonnet, etc.)
+  - Model:     Default AI model (see 'perplexity models')
   - Mode:      Search mode (fast, pro, reasoning, deep-research, default)
   - Language:  Response language (e.g., en-US, pt-BR)
   - Sources:   Search sources (web, scholar, social)
   - Streaming: Enable/disable streaming output
-  - Incognito: Enable/disable history saving`,
+  - Incognito: Enable/disable history saving
+
+For scripts, use the non-interactive subcommands:
+  perplexity config get default_model
+  perplexity config set default_mode pro
+  perplexity config unset default_mode
+  perplexity config list --effective`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return ui.RunInteractiveConfig(cfg, cfgMgr)
 	},
@@ -35,6 +51,239 @@ var configPathCmd = &cobra.Command{
 	},
 }
 
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Print a configuration value",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := cfg.Get(args[0])
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		fmt.Println(config.FormatValue(value))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a configuration value in the config file of the current profile.
+
+Values are validated like the config file: booleans accept true/false,
+yes/no, on/off or 1/0, and lists are comma-separated.
+
+Examples:
+  perplexity config set default_model claude
+  perplexity config set default_sources web,scholar
+  perplexity config set streaming off`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfgMgr.SetValue(args[0], args[1]); err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		render.RenderSuccess(fmt.Sprintf("Set %s in %s", args[0], cfgMgr.GetConfigFile()))
+		warnOverride(args[0])
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Remove a configuration value so its default applies",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := cfgMgr.UnsetValue(args[0])
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		if !removed {
+			render.RenderInfo(fmt.Sprintf("%s is not set in %s", args[0], cfgMgr.GetConfigFile()))
+			return nil
+		}
+		render.RenderSuccess(fmt.Sprintf("Unset %s", args[0]))
+		warnOverride(args[0])
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configuration values",
+	Long: `List all configuration values of the current profile.
+
+With --effective, query flags given to this command are applied too and each
+value shows the layer it came from, from lowest to highest precedence:
+default, file (user config), project (.perplexity.{json,yaml,toml} found in
+the current directory or a parent), env or flag.
+
+Examples:
+  perplexity config list
+  perplexity config list --json
+  perplexity config list --effective --model claude --no-stream`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := configEntries(configListEffective)
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		if configListJSON {
+			return writeConfigJSON(os.Stdout, entries, configListEffective)
+		}
+		writeConfigTable(os.Stdout, entries, configListEffective)
+		if configListEffective {
+			render.NewLine()
+			render.RenderInfo(fmt.Sprintf("file: %s", cfgMgr.GetConfigFile()))
+			if project := cfgMgr.GetProjectFile(); project != "" {
+				render.RenderInfo(fmt.Sprintf("project: %s", project))
+			}
+		}
+		return nil
+	},
+}
+
+// configEntry is a configuration value with its origin.
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// configEntries returns all configuration values. When effective is set,
+// query flags override the loaded values.
+func configEntries(effective bool) ([]configEntry, error) {
+	var overrides map[string]any
+	if effective {
+		overrides = configFlagOverrides()
+	}
+
+	keys := config.Keys()
+	entries := make([]configEntry, 0, len(keys))
+	for _, key := range keys {
+		value, err := cfg.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		entry := configEntry{Key: key, Value: value, Source: cfgMgr.Origin(key)}
+		if v, ok := overrides[key]; ok {
+			entry.Value, entry.Source = v, config.OriginFlag
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// configFlagOverrides returns the configuration values overridden by query
+// flags, keyed by configuration key.
+func configFlagOverrides() map[string]any {
+	opts := buildSearchOptions("")
+	overrides := map[string]any{}
+
+	if flagModel != "" {
+		overrides["default_model"] = string(opts.Model)
+	}
+	if flagMode != "" {
+		overrides["default_mode"] = string(opts.Mode)
+	}
+	if flagLanguage != "" {
+		overrides["default_language"] = opts.Language
+	}
+	if flagSources != "" {
+		sources := make([]string, len(opts.Sources))
+		for i, s := range opts.Sources {
+			sources[i] = string(s)
+		}
+		overrides["default_sources"] = sources
+	}
+	if flagStream || flagNoStream {
+		overrides["streaming"] = streamingEnabled()
+	}
+	if flagIncognito {
+		overrides["incognito"] = true
+	}
+	if flagCookieFile != "" {
+		overrides["cookie_file"] = flagCookieFile
+	}
+	return overrides
+}
+
+// writeConfigTable prints configuration values, with their origin when
+// showOrigin is set.
+func writeConfigTable(w io.Writer, entries []configEntry, showOrigin bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		if showOrigin {
+			fmt.Fprintf(tw, "%s\t%s\t(%s)\n", e.Key, config.FormatValue(e.Value), e.Source)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", e.Key, config.FormatValue(e.Value))
+		}
+	}
+	tw.Flush()
+}
+
+// writeConfigJSON prints configuration values as a JSON object, or as a list
+// of entries with their origin when showOrigin is set.
+func writeConfigJSON(w io.Writer, entries []configEntry, showOrigin bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if showOrigin {
+		return enc.Encode(entries)
+	}
+
+	values := make(map[string]any, len(entries))
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+	return enc.Encode(values)
+}
+
+// warnOverride warns when the environment or a project config hides the
+// config file value of key.
+func warnOverride(key string) {
+	switch cfgMgr.Origin(key) {
+	case config.OriginEnv:
+		render.RenderWarning(fmt.Sprintf("%s is overridden by the environment", key))
+	case config.OriginProject:
+		render.RenderWarning(fmt.Sprintf("%s is overridden by %s", key, cfgMgr.GetProjectFile()))
+	}
+}
+
+// completeConfigKeys completes the key argument of config subcommands.
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+}
+
 func init() {
 	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configListCmd.Flags().BoolVar(&configListJSON, "json", false, "Output as JSON")
+	configListCmd.Flags().BoolVar(&configListEffective, "effective", false, "Apply query flags and show where each value came from")
+	configListCmd.Flags().StringVarP(&flagModel, "model", "m", "", "Model override to apply with --effective")
+	configListCmd.Flags().StringVar(&flagMode, "mode", "", "Mode override to apply with --effective")
+	configListCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Sources override to apply with --effective")
+	configListCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Language override to apply with --effective")
+	configListCmd.Flags().BoolVar(&flagStream, "stream", false, "Streaming override to apply with --effective")
+	configListCmd.Flags().BoolVar(&flagNoStream, "no-stream", false, "Streaming override to apply with --effective")
+	configListCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Incognito override to apply with --effective")
+	configListCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Cookie file override to apply with --effective")
+	configListCmd.RegisterFlagCompletionFunc("model", completeModels)
+	configListCmd.RegisterFlagCompletionFunc("mode", completeModes)
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestConfigSetGetUnsetCmds(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := configSetCmd.RunE(configSetCmd, []string{"default_mode", "pro"}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+	if err := configSetCmd.RunE(configSetCmd, []string{"streaming", "sometimes"}); err == nil {
+		t.Error("Expected error for invalid boolean")
+	}
+	if err := configSetCmd.RunE(configSetCmd, []string{"unknown_key", "x"}); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+
+	loaded, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.DefaultMode != models.ModePro {
+		t.Errorf("DefaultMode = %q, want pro", loaded.DefaultMode)
+	}
+
+	cfg = loaded
+	if err := configGetCmd.RunE(configGetCmd, []string{"default_mode"}); err != nil {
+		t.Errorf("config get failed: %v", err)
+	}
+	if err := configGetCmd.RunE(configGetCmd, []string{"unknown_key"}); err == nil {
+		t.Error("Expected error getting unknown key")
+	}
+
+	if err := configUnsetCmd.RunE(configUnsetCmd, []string{"default_mode"}); err != nil {
+		t.Errorf("config unset failed: %v", err)
+	}
+}
+
+func TestConfigEntries_Effective(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { flagModel, flagNoStream = "", false }()
+
+	t.Setenv("PERPLEXITY_DEFAULT_LANGUAGE", "pt-BR")
+	loaded, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cfg = loaded
+	flagModel = "claude"
+	flagNoStream = true
+
+	entries, err := configEntries(true)
+	if err != nil {
+		t.Fatalf("configEntries failed: %v", err)
+	}
+
+	got := map[string]configEntry{}
+	for _, e := range entries {
+		got[e.Key] = e
+	}
+	want := map[string]configEntry{
+		"default_model":    {Key: "default_model", Value: "claude45sonnet", Source: config.OriginFlag},
+		"streaming":        {Key: "streaming", Value: false, Source: config.OriginFlag},
+		"default_language": {Key: "default_language", Value: "pt-BR", Source: config.OriginEnv},
+		"default_mode":     {Key: "default_mode", Value: "default", Source: config.OriginDefault},
+	}
+	for key, w := range want {
+		if g := got[key]; g.Value != w.Value || g.Source != w.Source {
+			t.Errorf("%s = %v (%s), want %v (%s)", key, g.Value, g.Source, w.Value, w.Source)
+		}
+	}
+
+	// Without --effective flags are ignored
+	entries, err = configEntries(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Source == config.OriginFlag {
+			t.Errorf("%s has flag source without --effective", e.Key)
+		}
+	}
+}
+
+func TestWriteConfigOutput(t *testing.T) {
+	entries := []configEntry{
+		{Key: "default_sources", Value: []string{"web", "scholar"}, Source: config.OriginFile},
+		{Key: "streaming", Value: true, Source: config.OriginDefault},
+	}
+
+	var buf bytes.Buffer
+	writeConfigTable(&buf, entries, true)
+	if !strings.Contains(buf.String(), "web,scholar") || !strings.Contains(buf.String(), "(file)") {
+		t.Errorf("unexpected table output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeConfigJSON(&buf, entries, false); err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &values); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if values["streaming"] != true {
+		t.Errorf("streaming = %v, want true", values["streaming"])
+	}
+
+	buf.Reset()
+	if err := writeConfigJSON(&buf, entries, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"source": "file"`) {
+		t.Errorf("expected sources in JSON output:\n%s", buf.String())
+	}
+}
@@ -2,9 +2,16 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
+	http "github.com/bogdanfinn/fhttp"
 	"github.com/diogo/perplexity-go/internal/auth"
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/internal/secret"
 	"github.com/spf13/cobra"
 )
 
@@ -14,41 +21,70 @@ var cookiesCmd = &cobra.Command{
 	Long:  `Manage authentication cookies for Perplexity API access.`,
 }
 
+var (
+	flagImportFrom    string
+	flagImportProfile string
+)
+
 // importCookiesCmd is a root-level command for importing cookies.
 var importCookiesCmd = &cobra.Command{
-	Use:   "import-cookies <file>",
-	Short: "Import cookies from file",
-	Long: `Import cookies from a JSON or Netscape format file.
+	Use:   "import-cookies [file]",
+	Short: "Import cookies from file or browser",
+	Long: `Import cookies from a JSON or Netscape format file, or directly from a
+local browser profile with --from.
 
 Supported formats:
   - JSON: Browser extension export (cookies.json)
   - Netscape: curl/wget format (cookies.txt)
 
-Example:
-  perplexity import-cookies ~/Downloads/cookies.json`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		importFile := args[0]
+Supported browsers:
+  - firefox: reads cookies.sqlite from the Firefox profile
+  - chromium, chrome: reads and decrypts the Cookies database (Linux only)
 
-		// Check if source file exists
-		if _, err := os.Stat(importFile); os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", importFile)
-		}
+Only perplexity.ai cookies are imported. The browser may stay open; its
+database is copied before reading.
 
-		// Try JSON format first
-		cookies, err := auth.LoadCookiesFromFile(importFile)
-		if err != nil {
-			// Try Netscape format
-			cookies, err = auth.LoadCookiesFromNetscape(importFile)
-			if err != nil {
-				return fmt.Errorf("failed to parse cookies (tried JSON and Netscape formats): %v", err)
+Examples:
+  perplexity import-cookies ~/Downloads/cookies.json
+  perplexity import-cookies --from firefox
+  perplexity import-cookies --from chromium --browser-profile "Profile 1"
+  perplexity --profile work import-cookies --from firefox`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if flagImportFrom != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot combine a cookie file with --from")
 			}
+			return nil
+		}
+		if flagImportProfile != "" {
+			return fmt.Errorf("--browser-profile requires --from")
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cookies []*http.Cookie
+		var err error
+		if flagImportFrom != "" {
+			cookies, err = loadBrowserCookies(flagImportFrom, flagImportProfile)
+		} else {
+			cookies, err = loadCookieFile(args[0])
+		}
+		if err != nil {
+			return err
 		}
 
 		if len(cookies) == 0 {
+			if flagImportFrom != "" {
+				return fmt.Errorf("no Perplexity cookies found in %s (are you logged in?)", flagImportFrom)
+			}
 			return fmt.Errorf("no Perplexity cookies found in file")
 		}
 
+		cookies = checkCookieExpiry(cookies, time.Now())
+		if len(cookies) == 0 {
+			return fmt.Errorf("all Perplexity cookies have expired; log in to perplexity.ai and export them again")
+		}
+
 		// Save to config cookie file
 		if err := auth.SaveCookiesToFile(cookies, cfg.CookieFile); err != nil {
 			return fmt.Errorf("failed to save cookies: %v", err)
@@ -64,6 +100,146 @@ Example:
 	},
 }
 
+// loadCookieFile reads cookies from a JSON or Netscape export.
+func loadCookieFile(importFile string) ([]*http.Cookie, error) {
+	// Check if source file exists
+	if _, err := os.Stat(importFile); os.IsNotExist(err) {
+		return nil, fmt.Errorf("file not found: %s", importFile)
+	}
+
+	// Try JSON format first
+	cookies, err := auth.LoadCookiesFromFile(importFile)
+	if err != nil {
+		// Try Netscape format
+		cookies, err = auth.LoadCookiesFromNetscape(importFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cookies (tried JSON and Netscape formats): %v", err)
+		}
+	}
+	return cookies, nil
+}
+
+var cookiesEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the cookie file and history at rest",
+	Long: `Encrypt the cookie file, the history database and its archives with a
+key derived from a passphrase, and turn on encrypt_at_rest so they stay
+encrypted.
+
+The passphrase is read from PERPLEXITY_PASSPHRASE, from the file set in
+passphrase_file, or prompted for on the terminal.
+
+Examples:
+  perplexity cookies encrypt
+  PERPLEXITY_PASSPHRASE=... perplexity cookies encrypt
+  perplexity config set passphrase_file ~/.perplexity-key && perplexity cookies encrypt`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setEncryption(true)
+	},
+}
+
+var cookiesDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt the cookie file and history back to plaintext",
+	Long: `Decrypt the cookie file, the history database and its archives, and
+turn off encrypt_at_rest.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setEncryption(false)
+	},
+}
+
+// setEncryption migrates the cookie file and history to the requested state
+// and records it in the config.
+func setEncryption(encrypt bool) error {
+	var changed []string
+
+	cookieFile := cookieFilePath()
+	if _, err := os.Stat(cookieFile); err == nil && secret.IsEncryptedFile(cookieFile) != encrypt {
+		data, _, err := secret.ReadFile(cookieFile)
+		if err == nil {
+			err = secret.WriteFile(cookieFile, data, encrypt)
+		}
+		if err != nil {
+			render.RenderError(fmt.Errorf("failed to convert cookie file: %v", err))
+			return err
+		}
+		changed = append(changed, cookieFile)
+	}
+
+	files, err := history.SetEncrypted(cfg.HistoryFile, encrypt)
+	changed = append(changed, files...)
+	if err != nil {
+		render.RenderError(fmt.Errorf("failed to convert history: %v", err))
+		return err
+	}
+
+	if err := cfgMgr.SetValue("encrypt_at_rest", strconv.FormatBool(encrypt)); err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	verb := "Encrypted"
+	if !encrypt {
+		verb = "Decrypted"
+	}
+	if len(changed) == 0 {
+		render.RenderInfo("No files needed converting")
+	}
+	for _, path := range changed {
+		render.RenderSuccess(fmt.Sprintf("%s %s", verb, path))
+	}
+	render.RenderInfo(fmt.Sprintf("encrypt_at_rest set to %t in %s", encrypt, cfgMgr.GetConfigFile()))
+	warnOverride("encrypt_at_rest")
+	return nil
+}
+
+// writeCookieExpiryTable prints each cookie with its expiry date and state.
+func writeCookieExpiryTable(w io.Writer, cookies []*http.Cookie, now time.Time, warnWithin time.Duration) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tEXPIRES\tSTATUS")
+	for _, c := range cookies {
+		expires, status := "-", "session"
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Format("2006-01-02 15:04")
+		}
+		switch auth.CookieExpiry(c, now, warnWithin) {
+		case auth.ExpiryExpired:
+			status = "expired"
+		case auth.ExpirySoon:
+			status = "expires " + formatUntil(c.Expires.Sub(now))
+		case auth.ExpiryValid:
+			status = "valid, " + formatUntil(c.Expires.Sub(now))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, expires, status)
+	}
+	tw.Flush()
+}
+
+// completeBrowsers completes --from with the supported browsers.
+func completeBrowsers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, len(auth.SupportedBrowsers))
+	for i, b := range auth.SupportedBrowsers {
+		names[i] = string(b)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// loadBrowserCookies reads cookies from a local browser profile.
+func loadBrowserCookies(name, profile string) ([]*http.Cookie, error) {
+	browser, err := auth.ParseBrowser(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := auth.LoadCookiesFromBrowser(browser, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import cookies from %s: %w", browser, err)
+	}
+	return cookies, nil
+}
+
 var cookiesStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check authentication status",
@@ -93,22 +269,32 @@ var cookiesStatusCmd = &cobra.Command{
 			return nil
 		}
 
+		now := time.Now()
+		valid, _ := auth.DropExpired(cookies, now)
+		warnWithin := time.Duration(cfg.CookieExpiryWarningDays) * 24 * time.Hour
+
 		// Check for CSRF token
-		if auth.HasCSRFToken(cookies) {
+		if auth.HasCSRFToken(valid) {
 			render.RenderSuccess("Authenticated")
 			fmt.Printf("Cookie file: %s\n", cookieFile)
 			fmt.Printf("Cookies loaded: %d\n", len(cookies))
-
-			// Show cookie names
-			fmt.Println("\nCookies:")
-			for _, c := range cookies {
-				fmt.Printf("  - %s\n", c.Name)
-			}
 		} else {
 			render.RenderWarning("Cookies found but CSRF token missing")
 			render.RenderInfo("Session may be expired. Re-export cookies from browser.")
 		}
 
+		if token := auth.FindSessionToken(cookies); token != nil {
+			switch auth.CookieExpiry(token, now, warnWithin) {
+			case auth.ExpiryExpired:
+				render.RenderWarning(fmt.Sprintf("Session token expired on %s", token.Expires.Format("2006-01-02")))
+				render.RenderInfo("Log in to perplexity.ai and run 'perplexity import-cookies' again")
+			case auth.ExpirySoon:
+				render.RenderWarning(fmt.Sprintf("Session token expires on %s (%s)", token.Expires.Format("2006-01-02"), formatUntil(token.Expires.Sub(now))))
+			}
+		}
+
+		fmt.Println()
+		writeCookieExpiryTable(os.Stdout, cookies, now, warnWithin)
 		return nil
 	},
 }
@@ -143,6 +329,12 @@ func init() {
 	cookiesCmd.AddCommand(cookiesStatusCmd)
 	cookiesCmd.AddCommand(cookiesClearCmd)
 	cookiesCmd.AddCommand(cookiesPathCmd)
+	cookiesCmd.AddCommand(cookiesEncryptCmd)
+	cookiesCmd.AddCommand(cookiesDecryptCmd)
+
+	importCookiesCmd.Flags().StringVar(&flagImportFrom, "from", "", "Import from a browser profile (firefox, chromium, chrome)")
+	importCookiesCmd.Flags().StringVar(&flagImportProfile, "browser-profile", "", "Browser profile name or directory (default profile if empty)")
+	importCookiesCmd.RegisterFlagCompletionFunc("from", completeBrowsers)
 
 	// NOTE: importCookiesCmd is added to the rootCmd in root.go
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/internal/auth"
 	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/internal/secret"
 	"github.com/diogo/perplexity-go/internal/ui"
 )
 
@@ -188,6 +193,97 @@ func TestCookiesStatusCmd_ValidCookies(t *testing.T) {
 	}
 }
 
+func TestWriteCookieExpiryTable(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{
+		{Name: "next-auth.csrf-token", Value: "a"},
+		{Name: auth.SessionTokenCookie, Value: "b", Expires: now.Add(3 * 24 * time.Hour)},
+		{Name: "pplx.visitor-id", Value: "c", Expires: now.Add(-time.Hour)},
+		{Name: "__cf_bm", Value: "d", Expires: now.Add(30 * 24 * time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	writeCookieExpiryTable(&buf, cookies, now, 7*24*time.Hour)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := [][]string{
+		{"NAME", "EXPIRES", "STATUS"},
+		{"next-auth.csrf-token", "-", "session"},
+		{auth.SessionTokenCookie, "2026-03-04", "expires in 3 days"},
+		{"pplx.visitor-id", "2026-03-01", "expired"},
+		{"__cf_bm", "2026-03-31", "valid, in 30 days"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, parts := range want {
+		for _, p := range parts {
+			if !strings.Contains(lines[i], p) {
+				t.Errorf("line %d = %q, missing %q", i, lines[i], p)
+			}
+		}
+	}
+}
+
+func TestCheckCookieExpiry_DropsExpired(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	cfg.CookieExpiryWarningDays = 7
+
+	now := time.Now()
+	cookies := []*http.Cookie{
+		{Name: "next-auth.csrf-token", Value: "a"},
+		{Name: auth.SessionTokenCookie, Value: "b", Expires: now.Add(-time.Hour)},
+	}
+
+	valid := checkCookieExpiry(cookies, now)
+	if len(valid) != 1 || valid[0].Name != "next-auth.csrf-token" {
+		t.Errorf("checkCookieExpiry() = %v, want only the csrf token", valid)
+	}
+}
+
+func TestCookiesEncryptDecryptCmds(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	secret.Configure(false, func(bool) ([]byte, error) { return []byte("passphrase"), nil })
+	defer secret.Configure(false, nil)
+
+	cookieJSON := `[{"name": "next-auth.csrf-token", "value": "token|hash", "domain": ".perplexity.ai", "path": "/"}]`
+	cfg.CookieFile = filepath.Join(tmpDir, "cookies.json")
+	if err := os.WriteFile(cfg.CookieFile, []byte(cookieJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cookiesEncryptCmd.RunE(cookiesEncryptCmd, nil); err != nil {
+		t.Fatalf("cookies encrypt failed: %v", err)
+	}
+	if !secret.IsEncryptedFile(cfg.CookieFile) {
+		t.Error("cookie file was not encrypted")
+	}
+	loaded, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.EncryptAtRest {
+		t.Error("encrypt_at_rest was not turned on")
+	}
+
+	if err := cookiesDecryptCmd.RunE(cookiesDecryptCmd, nil); err != nil {
+		t.Fatalf("cookies decrypt failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.CookieFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != cookieJSON {
+		t.Errorf("decrypted cookie file = %q, want the original", data)
+	}
+	if loaded, _ := cfgMgr.Load(); loaded.EncryptAtRest {
+		t.Error("encrypt_at_rest was not turned off")
+	}
+}
+
 func TestCookiesStatusCmd_MissingCSRFToken(t *testing.T) {
 	tmpDir, cleanup := setupTestEnv(t)
 	defer cleanup()
@@ -363,3 +459,38 @@ func TestImportCookiesCmd_Help(t *testing.T) {
 		t.Error("importCookiesCmd should have a long description")
 	}
 }
+
+func TestImportCookiesCmd_Args(t *testing.T) {
+	defer func() { flagImportFrom, flagImportProfile = "", "" }()
+
+	tests := []struct {
+		from, profile string
+		args          []string
+		wantErr       bool
+	}{
+		{"", "", []string{"cookies.json"}, false},
+		{"", "", nil, true},
+		{"firefox", "", nil, false},
+		{"firefox", "work", nil, false},
+		{"firefox", "", []string{"cookies.json"}, true},
+		{"", "work", []string{"cookies.json"}, true},
+	}
+	for _, tt := range tests {
+		flagImportFrom, flagImportProfile = tt.from, tt.profile
+		err := importCookiesCmd.Args(importCookiesCmd, tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Args(from=%q, profile=%q, %v) error = %v, wantErr %v", tt.from, tt.profile, tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestImportCookiesCmd_UnsupportedBrowser(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { flagImportFrom = "" }()
+
+	flagImportFrom = "safari"
+	if err := importCookiesCmd.RunE(importCookiesCmd, nil); err == nil {
+		t.Error("Expected error for unsupported browser")
+	}
+}
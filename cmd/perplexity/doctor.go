@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/internal/auth"
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/internal/secret"
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Doctor check outcomes.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+var doctorJSON bool
+
+// configLoadErr is the error that stopped the config from loading when
+// doctor runs; the other checks then use the default config.
+var configLoadErr error
+
+// runningDoctor reports whether the command line runs doctor.
+func runningDoctor() bool {
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && cmd == doctorCmd
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration, cookies and connectivity",
+	Long: `Run a set of checks on the configuration, cookies, history, network and
+terminal, printing pass, warn or fail for each with a suggested fix.
+
+The command exits with an error when any check fails.
+
+Examples:
+  perplexity doctor
+  perplexity doctor --json
+  perplexity --profile work doctor`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+// checkResult is the outcome of one doctor check.
+type checkResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	var results []checkResult
+	results = append(results, checkConfig())
+
+	cookieFile := cookieFilePath()
+	results = append(results, checkCookieFile(cookieFile))
+	warnWithin := time.Duration(cfg.CookieExpiryWarningDays) * 24 * time.Hour
+	cookies, cookieResults := checkCookies(cookieFile, time.Now(), warnWithin)
+	results = append(results, cookieResults...)
+
+	results = append(results, checkHistoryWritable(history.DBPath(cfg.HistoryFile)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	results = append(results, checkSession(ctx, cookies))
+	results = append(results, checkProxy())
+	results = append(results, checkTerminal(os.Stdout))
+
+	if doctorJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode results: %v", err)
+		}
+	} else {
+		writeDoctorResults(os.Stdout, results)
+	}
+
+	if failed := countChecks(results, checkFail); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
+
+// checkConfig reports whether the configuration loaded cleanly.
+func checkConfig() checkResult {
+	result := checkResult{Name: "config"}
+	file := cfgMgr.GetConfigFile()
+
+	if configLoadErr != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("%s failed to load: %v", file, configLoadErr)
+		result.Fix = fmt.Sprintf("Fix the syntax of %s (or of the project config), or move it aside to start from defaults", file)
+		return result
+	}
+
+	if warnings := cfgMgr.Warnings(); len(warnings) > 0 {
+		result.Status = checkWarn
+		result.Message = strings.Join(warnings, "; ")
+		result.Fix = fmt.Sprintf("Fix the values with 'perplexity config set' or edit %s", file)
+		return result
+	}
+
+	result.Status = checkPass
+	if _, err := os.Stat(file); err != nil {
+		result.Message = fmt.Sprintf("no config file, using defaults (profile %s)", currentProfile())
+	} else {
+		result.Message = fmt.Sprintf("%s parses and validates (profile %s)", file, currentProfile())
+	}
+	if project := cfgMgr.GetProjectFile(); project != "" {
+		result.Message += fmt.Sprintf("; project config %s", project)
+	}
+	return result
+}
+
+// checkCookieFile checks that the cookie file exists and is private.
+func checkCookieFile(path string) checkResult {
+	result := checkResult{Name: "cookie file"}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("%s not found", path)
+		result.Fix = "Run 'perplexity import-cookies <file>' or 'perplexity import-cookies --from firefox'"
+		return result
+	}
+
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0077 != 0 {
+		result.Status = checkWarn
+		result.Message = fmt.Sprintf("%s has permissions %04o; other users can read your session", path, perm)
+		result.Fix = fmt.Sprintf("chmod 600 %s", path)
+		return result
+	}
+
+	result.Status = checkPass
+	result.Message = fmt.Sprintf("%s exists with private permissions", path)
+	if secret.IsEncryptedFile(path) {
+		result.Message += " (encrypted)"
+	}
+	return result
+}
+
+// checkCookies loads the cookie file and checks the CSRF and session-token
+// cookies and their expiry.
+func checkCookies(path string, now time.Time, warnWithin time.Duration) ([]*http.Cookie, []checkResult) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+
+	cookies, err := auth.LoadCookiesFromFile(path)
+	if err != nil {
+		return nil, []checkResult{{
+			Name:    "cookies",
+			Status:  checkFail,
+			Message: fmt.Sprintf("failed to load cookies: %v", err),
+			Fix:     "Re-import cookies with 'perplexity import-cookies'",
+		}}
+	}
+
+	return cookies, []checkResult{
+		checkCookie(cookies, "csrf token", "next-auth.csrf-token", now, warnWithin),
+		checkCookie(cookies, "session token", "next-auth.session-token", now, warnWithin),
+	}
+}
+
+// checkCookie checks that a cookie whose name ends with suffix is present
+// and not about to expire within warnWithin.
+func checkCookie(cookies []*http.Cookie, name, suffix string, now time.Time, warnWithin time.Duration) checkResult {
+	result := checkResult{Name: name}
+
+	var cookie *http.Cookie
+	for _, c := range cookies {
+		if strings.HasSuffix(c.Name, suffix) && c.Value != "" {
+			cookie = c
+			break
+		}
+	}
+	if cookie == nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("%s cookie not found", suffix)
+		result.Fix = "Log in to perplexity.ai in your browser and re-import cookies"
+		return result
+	}
+
+	switch auth.CookieExpiry(cookie, now, warnWithin) {
+	case auth.ExpirySession:
+		result.Status = checkPass
+		result.Message = fmt.Sprintf("%s present (no expiry date)", cookie.Name)
+	case auth.ExpiryExpired:
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("%s expired on %s", cookie.Name, cookie.Expires.Format("2006-01-02"))
+		result.Fix = "Log in to perplexity.ai in your browser and re-import cookies"
+	case auth.ExpirySoon:
+		result.Status = checkWarn
+		result.Message = fmt.Sprintf("%s expires on %s (%s)", cookie.Name, cookie.Expires.Format("2006-01-02"), formatUntil(cookie.Expires.Sub(now)))
+		result.Fix = "Re-import cookies soon to avoid interruptions"
+	default:
+		result.Status = checkPass
+		result.Message = fmt.Sprintf("%s expires on %s (%s)", cookie.Name, cookie.Expires.Format("2006-01-02"), formatUntil(cookie.Expires.Sub(now)))
+	}
+	return result
+}
+
+// formatUntil formats a remaining duration in days or hours.
+func formatUntil(d time.Duration) string {
+	if d < time.Hour {
+		return "in less than an hour"
+	}
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("in %d days", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("in %d hours", int(d.Hours()))
+}
+
+// checkHistoryWritable checks that the history database can be written,
+// without opening or creating it.
+func checkHistoryWritable(path string) checkResult {
+	result := checkResult{Name: "history"}
+
+	if cfg.Incognito {
+		result.Status = checkPass
+		result.Message = "incognito is on; history is not saved"
+		return result
+	}
+
+	// The history directory is created on first use, so test the nearest
+	// directory that exists
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	var err error
+	if _, statErr := os.Stat(path); statErr == nil {
+		var f *os.File
+		if f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0); err == nil {
+			f.Close()
+		}
+	} else {
+		var f *os.File
+		if f, err = os.CreateTemp(dir, ".doctor-*"); err == nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}
+
+	if err != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("%s is not writable: %v", path, err)
+		result.Fix = fmt.Sprintf("Check the permissions of %s or set history_file with 'perplexity config set'", dir)
+		return result
+	}
+
+	result.Status = checkPass
+	result.Message = fmt.Sprintf("%s is writable", path)
+	if dir != filepath.Dir(path) {
+		result.Message = fmt.Sprintf("%s will be created (%s is writable)", filepath.Dir(path), dir)
+	}
+	return result
+}
+
+// checkSession probes /api/auth/session with the saved cookies.
+func checkSession(ctx context.Context, cookies []*http.Cookie) checkResult {
+	result := checkResult{Name: "session"}
+
+	if len(cookies) == 0 {
+		result.Status = checkWarn
+		result.Message = "skipped: no cookies to test"
+		result.Fix = "Import cookies first"
+		return result
+	}
+
+	cli, err := client.NewWithCookies(cookies)
+	if err == nil && flagDebugHTTP != "" {
+		err = enableHTTPDebug(cli, flagDebugHTTP)
+	}
+	if err != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("failed to create client: %v", err)
+		return result
+	}
+	defer cli.Close()
+
+	session, err := cli.CheckSession(ctx)
+	if err != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("session probe failed: %v", err)
+		result.Fix = "Log in to perplexity.ai in your browser and re-import cookies; if the request failed, check your connection and proxy"
+		return result
+	}
+
+	result.Status = checkPass
+	user := session.User.Email
+	if user == "" {
+		user = session.User.Name
+	}
+	result.Message = fmt.Sprintf("logged in as %s", user)
+	return result
+}
+
+// checkProxy checks that the proxy from the environment is reachable. The
+// client doesn't route through it, so a broken proxy is only a warning.
+func checkProxy() checkResult {
+	return checkProxyURL(client.ProxyURL())
+}
+
+// checkProxyURL checks the proxy returned by client.ProxyURL.
+func checkProxyURL(proxy *url.URL, err error) checkResult {
+	result := checkResult{Name: "proxy"}
+
+	if err != nil {
+		result.Status = checkFail
+		result.Message = fmt.Sprintf("invalid proxy setting: %v", err)
+		result.Fix = "Fix HTTPS_PROXY or unset it"
+		return result
+	}
+	if proxy == nil {
+		result.Status = checkPass
+		result.Message = "no proxy configured"
+		return result
+	}
+
+	shown := proxy.Redacted()
+	if err := client.CheckProxy(proxy, 5*time.Second); err != nil {
+		result.Status = checkWarn
+		result.Message = fmt.Sprintf("proxy %s is unreachable: %v (perplexity connects directly)", shown, err)
+		result.Fix = "Check that the proxy is running or fix HTTPS_PROXY"
+		return result
+	}
+
+	result.Status = checkPass
+	result.Message = fmt.Sprintf("proxy %s is reachable (perplexity connects directly)", shown)
+	return result
+}
+
+// checkTerminal reports the capabilities of the terminal used for output.
+func checkTerminal(out *os.File) checkResult {
+	result := checkResult{Name: "terminal"}
+
+	if !isatty.IsTerminal(out.Fd()) && !isatty.IsCygwinTerminal(out.Fd()) {
+		result.Status = checkPass
+		result.Message = "output is not a terminal (piped or redirected)"
+		return result
+	}
+
+	width, height, _ := term.GetSize(int(out.Fd()))
+	details := []string{fmt.Sprintf("%dx%d", width, height)}
+	var problems, fixes []string
+
+	switch termName := os.Getenv("TERM"); {
+	case os.Getenv("NO_COLOR") != "":
+		details = append(details, "colors disabled by NO_COLOR")
+	case termName == "dumb":
+		problems = append(problems, "TERM=dumb has no color support")
+		fixes = append(fixes, "set TERM=xterm-256color")
+	default:
+		details = append(details, "colors enabled")
+	}
+
+	if !utf8Locale() {
+		problems = append(problems, "locale is not UTF-8, so spinners and borders may render incorrectly")
+		fixes = append(fixes, "set LANG=en_US.UTF-8")
+	}
+	if width > 0 && width < 60 {
+		problems = append(problems, fmt.Sprintf("terminal is only %d columns wide", width))
+		fixes = append(fixes, "widen the terminal or use --output to save answers")
+	}
+
+	result.Message = strings.Join(append(details, problems...), ", ")
+	if len(problems) > 0 {
+		result.Status = checkWarn
+		result.Fix = strings.Join(fixes, "; ")
+	} else {
+		result.Status = checkPass
+	}
+	return result
+}
+
+// utf8Locale reports whether the locale environment selects UTF-8.
+func utf8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// writeDoctorResults prints check results with a summary line.
+func writeDoctorResults(w io.Writer, results []checkResult) {
+	for _, r := range results {
+		fmt.Fprintf(w, "[%s] %-13s %s\n", strings.ToUpper(r.Status), r.Name, r.Message)
+		if r.Fix != "" && r.Status != checkPass {
+			fmt.Fprintf(w, "       %-13s fix: %s\n", "", r.Fix)
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n",
+		countChecks(results, checkPass), countChecks(results, checkWarn), countChecks(results, checkFail))
+}
+
+// countChecks counts results with the given status.
+func countChecks(results []checkResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output results as JSON")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestCheckCookieFile(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	path := filepath.Join(tmpDir, "cookies.json")
+	if r := checkCookieFile(path); r.Status != checkFail || !strings.Contains(r.Fix, "import-cookies") {
+		t.Errorf("missing file: got %+v", r)
+	}
+
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if r := checkCookieFile(path); r.Status != checkWarn || !strings.Contains(r.Fix, "chmod 600") {
+		t.Errorf("world-readable file: got %+v", r)
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if r := checkCookieFile(path); r.Status != checkPass {
+		t.Errorf("private file: got %+v", r)
+	}
+}
+
+func TestCheckCookie(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+		want    string
+	}{
+		{"missing", nil, checkFail},
+		{"no expiry", []*http.Cookie{{Name: "__Secure-next-auth.session-token", Value: "x"}}, checkPass},
+		{"valid", []*http.Cookie{{Name: "__Secure-next-auth.session-token", Value: "x", Expires: now.AddDate(0, 1, 0)}}, checkPass},
+		{"expiring soon", []*http.Cookie{{Name: "__Secure-next-auth.session-token", Value: "x", Expires: now.Add(48 * time.Hour)}}, checkWarn},
+		{"expired", []*http.Cookie{{Name: "__Secure-next-auth.session-token", Value: "x", Expires: now.Add(-time.Hour)}}, checkFail},
+		{"empty value", []*http.Cookie{{Name: "__Secure-next-auth.session-token"}}, checkFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := checkCookie(tt.cookies, "session token", "next-auth.session-token", now, 7*24*time.Hour)
+			if r.Status != tt.want {
+				t.Errorf("status = %q, want %q (%s)", r.Status, tt.want, r.Message)
+			}
+		})
+	}
+}
+
+func TestCheckHistoryWritable(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	path := filepath.Join(tmpDir, "history.db")
+	if r := checkHistoryWritable(path); r.Status != checkPass {
+		t.Errorf("new file: got %+v", r)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("check should not create the history file")
+	}
+
+	missing := filepath.Join(tmpDir, "profiles", "work", "history.db")
+	if r := checkHistoryWritable(missing); r.Status != checkPass || !strings.Contains(r.Message, "will be created") {
+		t.Errorf("missing directory: got %+v", r)
+	}
+	if _, err := os.Stat(filepath.Dir(missing)); !os.IsNotExist(err) {
+		t.Error("check should not create the history directory")
+	}
+
+	notDir := filepath.Join(tmpDir, "file")
+	if err := os.WriteFile(notDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if r := checkHistoryWritable(filepath.Join(notDir, "history.db")); r.Status != checkFail {
+		t.Errorf("parent is a file: got %+v", r)
+	}
+}
+
+func TestCheckConfig_LoadError(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	defer func() { configLoadErr = nil }()
+	configLoadErr = errors.New("failed to read config: invalid character")
+
+	r := checkConfig()
+	if r.Status != checkFail || !strings.Contains(r.Message, cfgMgr.GetConfigFile()) || !strings.Contains(r.Message, "invalid character") || r.Fix == "" {
+		t.Errorf("got %+v, want a failure naming the file", r)
+	}
+}
+
+func TestCheckSession_NoCookies(t *testing.T) {
+	r := checkSession(t.Context(), nil)
+	if r.Status != checkWarn {
+		t.Errorf("got %+v, want warn", r)
+	}
+}
+
+func TestCheckProxy_Direct(t *testing.T) {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy"} {
+		t.Setenv(name, "")
+	}
+
+	if r := checkProxy(); r.Status != checkPass {
+		t.Errorf("got %+v, want pass", r)
+	}
+}
+
+func TestCheckProxy_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if r := checkProxyURL(&url.URL{Scheme: "http", Host: addr}, nil); r.Status != checkWarn || !strings.Contains(r.Message, "unreachable") {
+		t.Errorf("got %+v, want an unreachable warning", r)
+	}
+}
+
+func TestWriteDoctorResults(t *testing.T) {
+	var buf bytes.Buffer
+	writeDoctorResults(&buf, []checkResult{
+		{Name: "config", Status: checkPass, Message: "ok"},
+		{Name: "cookie file", Status: checkFail, Message: "missing", Fix: "import cookies"},
+	})
+
+	out := buf.String()
+	for _, want := range []string{"[PASS] config", "[FAIL] cookie file", "fix: import cookies", "1 passed, 0 warnings, 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// explainSearch describes how the search options map to the request that
+// is sent, such as modes that replace the selected model.
+func explainSearch(opts models.SearchOptions, preview *client.RequestPreview) ([]string, error) {
+	req, err := preview.SearchPayload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	var lines []string
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%-12s %s", label+":", value))
+		}
+	}
+
+	field("Query", truncateResponse(strings.Join(strings.Fields(opts.Query), " "), 80))
+
+	mode := fmt.Sprintf("%s -> API mode %s", opts.Mode, req.Params.Mode)
+	if req.Params.IsProReasoningMode {
+		mode += " with pro reasoning"
+	}
+	field("Mode", mode)
+
+	sent := ""
+	if req.Params.ModelPreference != nil {
+		sent = *req.Params.ModelPreference
+	}
+	model := string(opts.Model)
+	if info, ok := models.ActiveCatalog().Model(model); ok {
+		model = fmt.Sprintf("%s (%s)", info.ID, info.Name)
+		if sent != info.RequestID() {
+			sent += fmt.Sprintf(" (replaced by %s mode)", opts.Mode)
+		}
+	}
+	field("Model", fmt.Sprintf("%s -> model_preference %s", model, sent))
+
+	field("Language", opts.Language)
+	sources := make([]string, len(opts.Sources))
+	for i, s := range opts.Sources {
+		sources[i] = string(s)
+	}
+	field("Sources", strings.Join(sources, ", "))
+	if !opts.Filters.IsEmpty() {
+		field("Filters", opts.Filters.String())
+	}
+	field("Attachments", strings.Join(opts.Attachments, ", "))
+	field("Streaming", fmt.Sprintf("%v", opts.Stream))
+	if opts.Incognito {
+		field("History", "not saved (incognito)")
+	} else {
+		field("History", "saved")
+	}
+
+	return lines, nil
+}
+
+// writeRequestPreview writes the request line, headers and indented JSON
+// body of preview.
+func writeRequestPreview(w io.Writer, preview *client.RequestPreview) error {
+	fmt.Fprintf(w, "%s %s\n", preview.Method, preview.URL)
+	for _, name := range preview.HeaderNames() {
+		fmt.Fprintf(w, "%s: %s\n", name, preview.Headers[name])
+	}
+	fmt.Fprintln(w)
+
+	var body bytes.Buffer
+	if err := json.Indent(&body, preview.Body, "", "  "); err != nil {
+		return fmt.Errorf("failed to format payload: %w", err)
+	}
+	fmt.Fprintln(w, body.String())
+	return nil
+}
+
+// renderExplanation prints how opts map to the request.
+func renderExplanation(opts models.SearchOptions, preview *client.RequestPreview) error {
+	lines, err := explainSearch(opts, preview)
+	if err != nil {
+		return err
+	}
+
+	render.RenderTitle("Effective request")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	render.NewLine()
+	return nil
+}
+
+// runDryRun prints the explanation and the exact request a search would
+// send, with cookies redacted, without sending it.
+func runDryRun(opts models.SearchOptions) error {
+	cli, err := previewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	preview, err := cli.PreviewSearch(opts)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	if err := renderExplanation(opts, preview); err != nil {
+		return err
+	}
+	if err := writeRequestPreview(os.Stdout, preview); err != nil {
+		return err
+	}
+	render.RenderInfo("Dry run: request not sent")
+	return nil
+}
+
+// previewClient returns a client for building requests. It uses the saved
+// cookies when present so previews list the cookies that would be sent, and
+// works without them.
+func previewClient() (*client.Client, error) {
+	if _, err := os.Stat(cookieFilePath()); err == nil {
+		return newClient()
+	}
+	return client.New(client.DefaultConfig())
+}
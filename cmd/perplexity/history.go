@@ -1,15 +1,42 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
+	"time"
 
+	"github.com/diogo/perplexity-go/internal/diff"
 	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/pkg/models"
 	"github.com/spf13/cobra"
 )
 
 var (
 	historyCount int
+
+	historySearchSince string
+	historySearchUntil string
+	historySearchMode  string
+	historySearchModel string
+	historySearchLimit int
+
+	historyPruneOlderThan string
+	historyPruneMaxCount  int
+	historyPruneMaxSizeMB int
+	historyPruneDryRun    bool
+
+	historyExportFormat string
+	historyExportOutput string
+	historyExportSince  string
+	historyExportUntil  string
+	historyExportMode   string
+	historyExportModel  string
+
+	historyRerunModel string
+	historyRerunMode  string
+	historyRerunDiff  bool
 )
 
 var historyCmd = &cobra.Command{
@@ -26,12 +53,24 @@ var historyListCmd = &cobra.Command{
 }
 
 var historySearchCmd = &cobra.Command{
-	Use:   "search <query>",
+	Use:   "search [query]",
 	Short: "Search history",
-	Args:  cobra.ExactArgs(1),
+	Long: `Full-text search over past queries and answers, ranked by relevance.
+Matching ignores case and accents; every word must match (as a prefix).
+
+Examples:
+  perplexity history search "go generics"
+  perplexity history search rust --mode pro --since 30d
+  perplexity history search --model gpt51 --since 2025-01-01 --until 2025-02-01`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := buildHistorySearchOptions(args, time.Now())
+		if err != nil {
+			return err
+		}
+
 		reader := history.NewReader(cfg.HistoryFile)
-		entries, err := reader.Search(args[0])
+		entries, err := reader.SearchWith(opts)
 		if err != nil {
 			return fmt.Errorf("failed to search history: %v", err)
 		}
@@ -46,6 +85,9 @@ var historySearchCmd = &cobra.Command{
 			fmt.Printf("[%d] %s\n", i+1, entry.Timestamp.Format("2006-01-02 15:04"))
 			fmt.Printf("    Query: %s\n", entry.Query)
 			fmt.Printf("    Mode: %s, Model: %s\n", entry.Mode, entry.Model)
+			if status := entry.StatusOrDefault(); status != models.HistoryStatusOK {
+				fmt.Printf("    Status: %s\n", status)
+			}
 			fmt.Println()
 		}
 
@@ -53,6 +95,47 @@ var historySearchCmd = &cobra.Command{
 	},
 }
 
+// buildHistorySearchOptions builds search options from the search flags.
+func buildHistorySearchOptions(args []string, now time.Time) (history.SearchOptions, error) {
+	opts, err := historyFilterOptions(historySearchSince, historySearchUntil, historySearchMode, historySearchModel, now)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Limit = historySearchLimit
+	if len(args) > 0 {
+		opts.Text = args[0]
+	}
+
+	return opts, nil
+}
+
+// historyFilterOptions builds date, mode and model filters shared by the
+// history subcommands.
+func historyFilterOptions(since, until, mode, model string, now time.Time) (history.SearchOptions, error) {
+	opts := history.SearchOptions{
+		Mode:  mode,
+		Model: model,
+	}
+
+	if since != "" {
+		t, err := models.ParseSince(since, now)
+		if err != nil {
+			return opts, err
+		}
+		opts.Since = t
+	}
+	if until != "" {
+		t, err := models.ParseSince(until, now)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --until: %v", err)
+		}
+		opts.Until = t
+	}
+
+	return opts, nil
+}
+
 var historyShowCmd = &cobra.Command{
 	Use:   "show <index>",
 	Short: "Show details of a history entry",
@@ -73,15 +156,9 @@ var historyShowCmd = &cobra.Command{
 			return fmt.Errorf("index out of range: %d (max: %d)", idx, len(entries))
 		}
 
-		entry := entries[idx-1]
-		render.RenderTitle("History Entry")
-		fmt.Printf("Timestamp: %s\n", entry.Timestamp.Format("2006-01-02 15:04:05"))
-		fmt.Printf("Query:     %s\n", entry.Query)
-		fmt.Printf("Mode:      %s\n", entry.Mode)
-		fmt.Printf("Model:     %s\n", entry.Model)
-		if entry.Response != "" {
-			fmt.Println("\nResponse:")
-			render.RenderStyledResponse(entry.Response)
+		if err := render.RenderHistoryEntry(entries[idx-1]); err != nil {
+			render.RenderError(err)
+			return err
 		}
 
 		return nil
@@ -101,6 +178,304 @@ var historyClearCmd = &cobra.Command{
 	},
 }
 
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Move old entries into compressed archives",
+	Long: `Rotate history entries into compressed monthly archives
+(history-archive/YYYY-MM.jsonl.gz) and compact the database.
+Archived entries remain searchable with 'history search'.
+
+Without limit flags, the retention settings from the config are applied
+(history_max_entries, history_max_age, history_max_size_mb).
+
+Examples:
+  perplexity history prune --older-than 90d --dry-run
+  perplexity history prune --max-entries 5000
+  perplexity history prune`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryPrune,
+}
+
+func runHistoryPrune(cmd *cobra.Command, args []string) error {
+	retention := historyRetention()
+	if historyPruneOlderThan != "" || historyPruneMaxCount > 0 || historyPruneMaxSizeMB > 0 {
+		retention = history.Retention{
+			MaxEntries: historyPruneMaxCount,
+			MaxAge:     historyPruneOlderThan,
+			MaxBytes:   int64(historyPruneMaxSizeMB) << 20,
+		}
+	}
+	if retention.IsZero() {
+		return fmt.Errorf("no retention limit set (use --older-than, --max-entries or --max-size-mb, or configure history_max_*)")
+	}
+	if _, err := models.ParseSince(retention.MaxAge, time.Now()); err != nil {
+		return err
+	}
+
+	store, err := history.OpenStore(cfg.HistoryFile)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %v", err)
+	}
+
+	result, err := store.Prune(retention, time.Now(), historyPruneDryRun)
+	if cerr := store.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune history: %v", err)
+	}
+
+	if result.Archived == 0 {
+		render.RenderInfo("Nothing to prune")
+		return nil
+	}
+
+	for _, month := range result.Months() {
+		fmt.Printf("  %s: %d entries\n", month, result.ByMonth[month])
+	}
+
+	if historyPruneDryRun {
+		render.RenderInfo(fmt.Sprintf("Would archive %d entries into %s", result.Archived, result.Dir))
+	} else {
+		render.RenderSuccess(fmt.Sprintf("Archived %d entries into %s", result.Archived, result.Dir))
+	}
+	return nil
+}
+
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export history to JSONL, CSV, Markdown or HTML",
+	Long: `Export history entries (including archived ones), oldest first.
+
+Formats:
+  jsonl     One JSON entry per line (default; can be re-imported)
+  csv       Spreadsheet-friendly table (can be re-imported, but keeps only
+            the URLs of web results and drops the search sources)
+  markdown  One file per entry with YAML front matter, e.g. for an Obsidian
+            vault; --output must be a directory
+  html      A single page with every entry
+
+Examples:
+  perplexity history export > history.jsonl
+  perplexity history export --format csv --since 30d -o recent.csv
+  perplexity history export --format markdown --mode pro -o ~/vault/perplexity`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryExport,
+}
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import history from a JSONL or CSV export",
+	Long: `Merge entries from a JSONL (optionally .gz) or CSV export into the
+history. Entries with the same timestamp, query and backend UUID as an
+existing entry are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistoryImport,
+}
+
+func runHistoryExport(cmd *cobra.Command, args []string) error {
+	format, err := history.ParseExportFormat(historyExportFormat)
+	if err != nil {
+		return err
+	}
+	if format == history.ExportMarkdown && historyExportOutput == "" {
+		return fmt.Errorf("markdown export requires --output <directory>")
+	}
+
+	opts, err := historyFilterOptions(historyExportSince, historyExportUntil, historyExportMode, historyExportModel, time.Now())
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.NewReader(cfg.HistoryFile).Select(opts)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %v", err)
+	}
+
+	if format == history.ExportMarkdown {
+		n, err := history.ExportMarkdownDir(historyExportOutput, entries)
+		if err != nil {
+			return fmt.Errorf("failed to export history: %v", err)
+		}
+		render.RenderSuccess(fmt.Sprintf("Exported %d entries to %s", n, historyExportOutput))
+		return nil
+	}
+
+	if historyExportOutput == "" {
+		return history.Export(os.Stdout, entries, format)
+	}
+
+	file, err := os.OpenFile(historyExportOutput, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	if err := history.Export(file, entries, format); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to export history: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	render.RenderSuccess(fmt.Sprintf("Exported %d entries to %s", len(entries), historyExportOutput))
+	return nil
+}
+
+func runHistoryImport(cmd *cobra.Command, args []string) error {
+	entries, err := history.ReadImportFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		return err
+	}
+	hw.SetRetention(historyRetention())
+
+	added, err := hw.Import(entries)
+	if err != nil {
+		return fmt.Errorf("failed to import history: %v", err)
+	}
+
+	render.RenderSuccess(fmt.Sprintf("Imported %d of %d entries (%d duplicates skipped)", added, len(entries), len(entries)-added))
+	return nil
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <index>",
+	Short: "Re-run a past query and optionally diff the answers",
+	Long: `Re-execute a history entry with its original options (mode, model,
+language, sources and filters), optionally overriding the model or mode.
+
+With --diff, a word-level diff between the stored and the new answer is
+printed, followed by the source URLs that were added and dropped.
+
+Examples:
+  perplexity history rerun 3
+  perplexity history rerun 3 --model claude45sonnet --diff`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistoryRerun,
+}
+
+func runHistoryRerun(cmd *cobra.Command, args []string) error {
+	idx, err := strconv.Atoi(args[0])
+	if err != nil || idx < 1 {
+		return fmt.Errorf("invalid index: %s", args[0])
+	}
+
+	entries, err := history.NewReader(cfg.HistoryFile).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read history: %v", err)
+	}
+	if idx > len(entries) {
+		return fmt.Errorf("index out of range: %d (max: %d)", idx, len(entries))
+	}
+	entry := entries[idx-1]
+
+	if historyRerunModel != "" {
+		if err := validateModel(historyRerunModel); err != nil {
+			return err
+		}
+	}
+	if historyRerunMode != "" {
+		if err := validateMode(historyRerunMode); err != nil {
+			return err
+		}
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	opts := rerunSearchOptions(entry, historyRerunModel, historyRerunMode)
+	opts.Stream = streamingEnabled()
+
+	render.RenderInfo(fmt.Sprintf("Re-running: %s (Mode: %s, Model: %s)", opts.Query, opts.Mode, opts.Model))
+
+	started := time.Now()
+	result, err := performSearch(ctx, cli, opts)
+	saveHistory(opts, result, time.Since(started), err)
+	if err != nil {
+		if err == context.Canceled {
+			return nil
+		}
+		return err
+	}
+
+	if historyRerunDiff {
+		renderRerunDiff(entry, result)
+	}
+
+	return nil
+}
+
+// rerunSearchOptions rebuilds the search options of a history entry,
+// applying model and mode overrides when set.
+func rerunSearchOptions(entry models.HistoryEntry, model, mode string) models.SearchOptions {
+	opts := buildSearchOptions(entry.Query)
+
+	if entry.Mode != "" {
+		opts.Mode = models.Mode(entry.Mode)
+	}
+	if entry.Model != "" {
+		opts.Model = models.Model(entry.Model)
+	}
+	if entry.Language != "" {
+		opts.Language = entry.Language
+	}
+	if len(entry.Sources) > 0 {
+		opts.Sources = entry.Sources
+	}
+	opts.Filters = entry.Filters
+	opts.Attachments = entry.Attachments
+
+	if model != "" {
+		opts.Model = resolveModel(model)
+	}
+	if mode != "" {
+		opts.Mode = models.Mode(mode)
+	}
+
+	return opts
+}
+
+// renderRerunDiff prints the answer and source differences between a stored
+// entry and its re-run.
+func renderRerunDiff(entry models.HistoryEntry, result *searchResult) {
+	render.NewLine()
+	render.RenderTitle("Answer Diff")
+	if entry.Version < 2 && entry.Response != "" {
+		render.RenderWarning("The stored answer predates full-response history and may be truncated")
+	}
+
+	chunks := diff.Words(entry.Response, result.Text)
+	if diff.Changed(chunks) {
+		render.RenderDiff(chunks)
+	} else {
+		render.RenderInfo("Answer unchanged")
+	}
+
+	added, removed, kept := diff.Sets(sourceURLs(entry.WebResults), sourceURLs(result.WebResults))
+	render.RenderSourceDiff(added, removed, kept)
+}
+
+// sourceURLs returns the non-empty URLs of web results.
+func sourceURLs(results []models.WebResult) []string {
+	urls := make([]string, 0, len(results))
+	for _, wr := range results {
+		if wr.URL != "" {
+			urls = append(urls, wr.URL)
+		}
+	}
+	return urls
+}
+
 func runHistoryList(cmd *cobra.Command, args []string) error {
 	reader := history.NewReader(cfg.HistoryFile)
 
@@ -170,7 +545,35 @@ func init() {
 	historyCmd.AddCommand(historySearchCmd)
 	historyCmd.AddCommand(historyShowCmd)
 	historyCmd.AddCommand(historyClearCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+	historyCmd.AddCommand(historyExportCmd)
+	historyCmd.AddCommand(historyImportCmd)
+	historyCmd.AddCommand(historyRerunCmd)
 
 	historyCmd.Flags().IntVarP(&historyCount, "count", "n", 20, "Number of entries to show")
 	historyListCmd.Flags().IntVarP(&historyCount, "count", "n", 20, "Number of entries to show")
+
+	historySearchCmd.Flags().StringVar(&historySearchSince, "since", "", "Only entries after this time (e.g. 7d, 2025-01-01)")
+	historySearchCmd.Flags().StringVar(&historySearchUntil, "until", "", "Only entries before this time (e.g. 1d, 2025-02-01)")
+	historySearchCmd.Flags().StringVar(&historySearchMode, "mode", "", "Only entries with this mode")
+	historySearchCmd.Flags().StringVar(&historySearchModel, "model", "", "Only entries with this model")
+	historySearchCmd.Flags().IntVarP(&historySearchLimit, "count", "n", 50, "Maximum number of results (0 = unlimited)")
+
+	historyPruneCmd.Flags().StringVar(&historyPruneOlderThan, "older-than", "", "Archive entries older than this age (e.g. 90d, 6mo, 1y)")
+	historyPruneCmd.Flags().IntVar(&historyPruneMaxCount, "max-entries", 0, "Keep at most this many entries")
+	historyPruneCmd.Flags().IntVar(&historyPruneMaxSizeMB, "max-size-mb", 0, "Keep at most this many megabytes of entries")
+	historyPruneCmd.Flags().BoolVar(&historyPruneDryRun, "dry-run", false, "Show what would be archived without changing anything")
+
+	historyExportCmd.Flags().StringVar(&historyExportFormat, "format", "jsonl", "Export format (jsonl, csv, markdown, html)")
+	historyExportCmd.Flags().StringVarP(&historyExportOutput, "output", "o", "", "Output file (directory for markdown); default stdout")
+	historyExportCmd.Flags().StringVar(&historyExportSince, "since", "", "Only entries after this time (e.g. 7d, 2025-01-01)")
+	historyExportCmd.Flags().StringVar(&historyExportUntil, "until", "", "Only entries before this time (e.g. 1d, 2025-02-01)")
+	historyExportCmd.Flags().StringVar(&historyExportMode, "mode", "", "Only entries with this mode")
+	historyExportCmd.Flags().StringVar(&historyExportModel, "model", "", "Only entries with this model")
+
+	historyRerunCmd.Flags().StringVar(&historyRerunModel, "model", "", "Override the stored model")
+	historyRerunCmd.Flags().StringVar(&historyRerunMode, "mode", "", "Override the stored mode")
+	historyRerunCmd.RegisterFlagCompletionFunc("model", completeModels)
+	historyRerunCmd.RegisterFlagCompletionFunc("mode", completeModes)
+	historyRerunCmd.Flags().BoolVar(&historyRerunDiff, "diff", false, "Show a word-level diff against the stored answer")
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestBuildHistorySearchOptions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	origSince, origUntil, origMode, origModel, origLimit := historySearchSince, historySearchUntil, historySearchMode, historySearchModel, historySearchLimit
+	defer func() {
+		historySearchSince, historySearchUntil, historySearchMode, historySearchModel, historySearchLimit = origSince, origUntil, origMode, origModel, origLimit
+	}()
+
+	historySearchSince = "7d"
+	historySearchUntil = "2025-02-28"
+	historySearchMode = "pro"
+	historySearchModel = "gpt51"
+	historySearchLimit = 10
+
+	opts, err := buildHistorySearchOptions([]string{"go generics"}, now)
+	if err != nil {
+		t.Fatalf("buildHistorySearchOptions() error = %v", err)
+	}
+	if opts.Text != "go generics" || opts.Mode != "pro" || opts.Model != "gpt51" || opts.Limit != 10 {
+		t.Errorf("opts = %+v", opts)
+	}
+	if !opts.Since.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("Since = %v", opts.Since)
+	}
+	if !opts.Until.Equal(time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Until = %v", opts.Until)
+	}
+
+	historySearchUntil = "soon"
+	if _, err := buildHistorySearchOptions(nil, now); err == nil {
+		t.Error("expected error for invalid --until")
+	}
+}
+
+func TestHistoryPruneCmd(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	origOlder, origDry := historyPruneOlderThan, historyPruneDryRun
+	defer func() { historyPruneOlderThan, historyPruneDryRun = origOlder, origDry }()
+
+	historyPruneOlderThan = ""
+	if err := historyPruneCmd.RunE(historyPruneCmd, nil); err == nil {
+		t.Error("expected error without retention limits")
+	}
+
+	historyPruneOlderThan = "soon"
+	if err := historyPruneCmd.RunE(historyPruneCmd, nil); err == nil {
+		t.Error("expected error for invalid --older-than")
+	}
+
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	hw.Append(models.HistoryEntry{Query: "old", Timestamp: time.Now().AddDate(-1, 0, 0)})
+	hw.Append(models.HistoryEntry{Query: "new"})
+
+	historyPruneOlderThan = "90d"
+	historyPruneDryRun = true
+	if err := historyPruneCmd.RunE(historyPruneCmd, nil); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if entries, _ := history.NewReader(cfg.HistoryFile).ReadAll(); len(entries) != 2 {
+		t.Errorf("dry run changed history: %d entries", len(entries))
+	}
+
+	historyPruneDryRun = false
+	if err := historyPruneCmd.RunE(historyPruneCmd, nil); err != nil {
+		t.Fatalf("prune error = %v", err)
+	}
+	if entries, _ := history.NewReader(cfg.HistoryFile).ReadAll(); len(entries) != 1 || entries[0].Query != "new" {
+		t.Errorf("after prune: %+v", entries)
+	}
+}
+
+func TestHistoryExportImportCmd(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	origFormat, origOutput := historyExportFormat, historyExportOutput
+	defer func() { historyExportFormat, historyExportOutput = origFormat, origOutput }()
+
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	hw.Append(models.HistoryEntry{Query: "exported question", Response: "answer"})
+
+	historyExportFormat = "markdown"
+	historyExportOutput = ""
+	if err := historyExportCmd.RunE(historyExportCmd, nil); err == nil {
+		t.Error("expected error for markdown export without --output")
+	}
+
+	historyExportFormat = "csv"
+	historyExportOutput = filepath.Join(tmpDir, "history.csv")
+	if err := historyExportCmd.RunE(historyExportCmd, nil); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	// Importing into a fresh history adds the entry; importing again skips it.
+	cfg.HistoryFile = filepath.Join(tmpDir, "other", "history.jsonl")
+	for i, want := range []int{1, 1} {
+		if err := historyImportCmd.RunE(historyImportCmd, []string{historyExportOutput}); err != nil {
+			t.Fatalf("import %d error = %v", i, err)
+		}
+		entries, _ := history.NewReader(cfg.HistoryFile).ReadAll()
+		if len(entries) != want || entries[0].Query != "exported question" {
+			t.Errorf("import %d: %+v", i, entries)
+		}
+	}
+}
+
+func TestRerunSearchOptions(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	entry := models.HistoryEntry{
+		Query:       "What is Go?",
+		Mode:        "pro",
+		Model:       "gpt51",
+		Language:    "pt-BR",
+		Sources:     []models.Source{models.SourceScholar},
+		Filters:     &models.SearchFilters{Sites: []string{"go.dev"}},
+		Attachments: []string{"https://example.com/a.pdf"},
+	}
+
+	opts := rerunSearchOptions(entry, "", "")
+	if opts.Query != entry.Query || opts.Mode != models.ModePro || opts.Model != models.ModelGPT51 {
+		t.Errorf("opts = %+v", opts)
+	}
+	if opts.Language != "pt-BR" || len(opts.Sources) != 1 || opts.Sources[0] != models.SourceScholar {
+		t.Errorf("language/sources = %q/%v", opts.Language, opts.Sources)
+	}
+	if opts.Filters == nil || len(opts.Attachments) != 1 {
+		t.Errorf("filters/attachments = %v/%v", opts.Filters, opts.Attachments)
+	}
+
+	opts = rerunSearchOptions(entry, "claude45sonnet", "reasoning")
+	if opts.Model != "claude45sonnet" || opts.Mode != "reasoning" {
+		t.Errorf("overrides not applied: %+v", opts)
+	}
+}
+
+func TestHistoryRerunCmd_InvalidIndex(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	for _, arg := range []string{"abc", "0", "5"} {
+		if err := historyRerunCmd.RunE(historyRerunCmd, []string{arg}); err == nil {
+			t.Errorf("expected error for index %q", arg)
+		}
+	}
+}
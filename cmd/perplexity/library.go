@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	librarySyncFull     bool
+	librarySyncMax      int
+	librarySyncPageSize int
+)
+
+var libraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "Work with your Perplexity library",
+	Long:  `Manage the threads stored in your Perplexity account library.`,
+}
+
+var librarySyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Merge library threads into local history",
+	Long: `Page through the threads in your Perplexity library and merge them into
+the local history, skipping threads already recorded (by backend UUID).
+Threads synced before are refreshed when they have a newer query, e.g. a
+follow-up; threads asked from this CLI are left as recorded. Threads without
+a valid date are recorded with the time of the sync.
+
+By default only threads newer than the last sync are fetched. Use --full
+to walk the entire library again.
+
+Examples:
+  perplexity library sync
+  perplexity library sync --full
+  perplexity library sync --max 100`,
+	Args: cobra.NoArgs,
+	RunE: runLibrarySync,
+}
+
+// libraryPageFunc fetches one page of library threads.
+type libraryPageFunc func(offset, limit int) ([]models.LibraryThread, error)
+
+func runLibrarySync(cmd *cobra.Command, args []string) error {
+	statePath := history.SyncStatePath(cfg.HistoryFile)
+	state, err := history.LoadSyncState(statePath)
+	if err != nil {
+		return err
+	}
+
+	since := state.Cursor
+	if librarySyncFull {
+		since = time.Time{}
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	fetch := func(offset, limit int) ([]models.LibraryThread, error) {
+		return cli.ListLibrary(ctx, offset, limit)
+	}
+
+	render.RenderInfo("Syncing library...")
+	threads, err := collectLibraryThreads(fetch, since, librarySyncPageSize, librarySyncMax)
+	if err != nil {
+		render.RenderError(fmt.Errorf("failed to sync library: %v", err))
+		return err
+	}
+
+	entries := make([]models.HistoryEntry, 0, len(threads))
+	for _, t := range threads {
+		entries = append(entries, libraryHistoryEntry(t))
+	}
+
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		return err
+	}
+	hw.SetRetention(historyRetention())
+	result, err := hw.Merge(entries)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %v", err)
+	}
+	if result.Undated > 0 {
+		render.RenderWarning(fmt.Sprintf("%d threads had no valid date and were recorded with the sync time", result.Undated))
+	}
+
+	// Only advance the cursor after a complete walk, otherwise threads beyond
+	// --max would be skipped by the next incremental sync.
+	if librarySyncMax == 0 || len(threads) < librarySyncMax {
+		for _, e := range entries {
+			if e.Timestamp.After(state.Cursor) {
+				state.Cursor = e.Timestamp
+			}
+		}
+	}
+	state.LastSync = time.Now()
+	if err := history.SaveSyncState(statePath, state); err != nil {
+		return err
+	}
+
+	render.RenderSuccess(fmt.Sprintf("Synced library: %d fetched, %d new entries, %d updated", len(threads), result.Added, result.Updated))
+	return nil
+}
+
+// collectLibraryThreads pages through the library, newest first, stopping at
+// the first thread not newer than since, after max threads (0 = unlimited),
+// or when the library is exhausted.
+func collectLibraryThreads(fetch libraryPageFunc, since time.Time, pageSize, max int) ([]models.LibraryThread, error) {
+	if pageSize <= 0 {
+		pageSize = client.DefaultLibraryPageSize
+	}
+
+	var threads []models.LibraryThread
+	for offset := 0; ; offset += pageSize {
+		page, err := fetch(offset, pageSize)
+		if err != nil {
+			return threads, err
+		}
+
+		for _, t := range page {
+			if !since.IsZero() {
+				if ts := client.LibraryThreadTime(t); !ts.IsZero() && !ts.After(since) {
+					return threads, nil
+				}
+			}
+			threads = append(threads, t)
+			if max > 0 && len(threads) >= max {
+				return threads, nil
+			}
+		}
+
+		if len(page) < pageSize {
+			return threads, nil
+		}
+	}
+}
+
+// libraryHistoryEntry converts a library thread into a history entry.
+func libraryHistoryEntry(t models.LibraryThread) models.HistoryEntry {
+	query := t.QueryStr
+	if query == "" {
+		query = t.Title
+	}
+
+	return models.HistoryEntry{
+		Version:     models.HistorySchemaVersion,
+		Timestamp:   client.LibraryThreadTime(t),
+		Query:       query,
+		Mode:        t.Mode,
+		Model:       t.Model(),
+		Response:    client.LibraryThreadAnswer(t),
+		BackendUUID: t.ID(),
+		Status:      models.HistoryStatusOK,
+		Title:       t.Title,
+		Slug:        t.Slug,
+		Source:      models.HistorySourceLibrary,
+	}
+}
+
+func init() {
+	libraryCmd.AddCommand(librarySyncCmd)
+
+	librarySyncCmd.Flags().BoolVar(&librarySyncFull, "full", false, "Ignore the stored cursor and sync the whole library")
+	librarySyncCmd.Flags().IntVar(&librarySyncMax, "max", 0, "Maximum number of threads to fetch (0 = unlimited)")
+	librarySyncCmd.Flags().IntVar(&librarySyncPageSize, "page-size", client.DefaultLibraryPageSize, "Number of threads per request")
+}
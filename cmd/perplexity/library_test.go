@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// fakeLibrary returns n threads, newest first, one hour apart.
+func fakeLibrary(n int, newest time.Time) libraryPageFunc {
+	return func(offset, limit int) ([]models.LibraryThread, error) {
+		var page []models.LibraryThread
+		for i := offset; i < offset+limit && i < n; i++ {
+			page = append(page, models.LibraryThread{
+				UUID:              fmt.Sprintf("uuid-%d", i),
+				Title:             fmt.Sprintf("Thread %d", i),
+				LastQueryDatetime: newest.Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
+			})
+		}
+		return page, nil
+	}
+}
+
+func TestCollectLibraryThreads(t *testing.T) {
+	newest := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		max   int
+		want  int
+	}{
+		{"full library across pages", time.Time{}, 0, 7},
+		{"incremental from cursor", newest.Add(-3 * time.Hour), 0, 3},
+		{"cursor at newest", newest, 0, 0},
+		{"max limit", time.Time{}, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threads, err := collectLibraryThreads(fakeLibrary(7, newest), tt.since, 3, tt.max)
+			if err != nil {
+				t.Fatalf("collectLibraryThreads() error = %v", err)
+			}
+			if len(threads) != tt.want {
+				t.Errorf("len(threads) = %d, want %d", len(threads), tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectLibraryThreads_Error(t *testing.T) {
+	fetch := func(offset, limit int) ([]models.LibraryThread, error) {
+		return nil, errors.New("boom")
+	}
+	if _, err := collectLibraryThreads(fetch, time.Time{}, 20, 0); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLibraryHistoryEntry(t *testing.T) {
+	entry := libraryHistoryEntry(models.LibraryThread{
+		UUID:              "u1",
+		Slug:              "what-is-go",
+		Title:             "What is Go?",
+		FirstAnswer:       `{"answer":"Go is a language."}`,
+		Mode:              "copilot",
+		DisplayModel:      "gpt51",
+		LastQueryDatetime: "2025-03-01T10:00:00",
+	})
+
+	if entry.Query != "What is Go?" || entry.Title != "What is Go?" || entry.Slug != "what-is-go" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.BackendUUID != "u1" || entry.Source != models.HistorySourceLibrary {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Mode != "copilot" || entry.Model != "gpt51" || entry.Response != "Go is a language." {
+		t.Errorf("entry = %+v", entry)
+	}
+	if !entry.Timestamp.Equal(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v", entry.Timestamp)
+	}
+}
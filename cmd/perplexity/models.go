@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modelsMode   string
+	modelsFormat string
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List available models and modes",
+	Long: `List the models and search modes in the model catalog with their
+display names, aliases and supported modes.
+
+The built-in catalog can be extended or overridden with a models.json file in
+the config directory using the same format as --format json. Entries replace
+built-in entries with the same id; new ids are added.
+
+Examples:
+  perplexity models
+  perplexity models --mode reasoning
+  perplexity models --format json > ~/.perplexity-cli/models.json`,
+	Args: cobra.NoArgs,
+	RunE: runModels,
+}
+
+func runModels(cmd *cobra.Command, args []string) error {
+	if modelsFormat != "text" && modelsFormat != "json" {
+		return fmt.Errorf("invalid format: %s (valid: text, json)", modelsFormat)
+	}
+
+	catalog, err := filterCatalog(models.ActiveCatalog(), models.Mode(modelsMode))
+	if err != nil {
+		return err
+	}
+
+	if modelsFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(catalog); err != nil {
+			return fmt.Errorf("failed to encode catalog: %v", err)
+		}
+		return nil
+	}
+
+	render.RenderTitle("Models")
+	writeModelTable(os.Stdout, catalog, cfg.DefaultModel)
+	render.NewLine()
+	render.RenderTitle("Modes")
+	writeModeTable(os.Stdout, catalog, cfg.DefaultMode)
+	render.NewLine()
+	render.RenderInfo(fmt.Sprintf("* default; customize in %s", cfgMgr.GetCatalogFile()))
+
+	return nil
+}
+
+// filterCatalog returns the catalog restricted to models supporting mode,
+// or the whole catalog when mode is empty.
+func filterCatalog(catalog *models.Catalog, mode models.Mode) (*models.Catalog, error) {
+	if mode == "" {
+		return catalog, nil
+	}
+	if _, ok := catalog.Mode(mode); !ok {
+		return nil, fmt.Errorf("invalid mode: %s", mode)
+	}
+
+	filtered := &models.Catalog{Modes: catalog.Modes}
+	for _, m := range catalog.Models {
+		if m.SupportsMode(mode) {
+			filtered.Models = append(filtered.Models, m)
+		}
+	}
+	return filtered, nil
+}
+
+// writeModelTable writes the catalog models as an aligned table, marking
+// the default model.
+func writeModelTable(w io.Writer, catalog *models.Catalog, defaultModel models.Model) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tMODES\tREASONING\tALIASES")
+	for _, m := range catalog.Models {
+		id := string(m.ID)
+		if m.ID == defaultModel {
+			id += " *"
+		}
+		modes := make([]string, len(m.Modes))
+		for i, mode := range m.Modes {
+			modes[i] = string(mode)
+		}
+		reasoning := ""
+		if m.Reasoning {
+			reasoning = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", id, m.Name, strings.Join(modes, ", "), reasoning, strings.Join(m.Aliases, ", "))
+	}
+	tw.Flush()
+}
+
+// writeModeTable writes the catalog modes as an aligned table, marking the
+// default mode.
+func writeModeTable(w io.Writer, catalog *models.Catalog, defaultMode models.Mode) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tDESCRIPTION")
+	for _, m := range catalog.Modes {
+		id := string(m.ID)
+		if m.ID == defaultMode {
+			id += " *"
+		}
+		desc := m.Description
+		if m.APIModel != "" {
+			desc += fmt.Sprintf(" (always uses %s)", m.APIModel)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", id, m.Name, desc)
+	}
+	tw.Flush()
+}
+
+// resolveModel returns the catalog id for a model id or alias, or name
+// unchanged when it is not in the catalog.
+func resolveModel(name string) models.Model {
+	if model, ok := models.ResolveModel(name); ok {
+		return model
+	}
+	return models.Model(name)
+}
+
+// modeCompatibilityWarning explains when the model will not be used as
+// asked in the selected mode, or returns "".
+func modeCompatibilityWarning(model models.Model, mode models.Mode) string {
+	catalog := models.ActiveCatalog()
+	info, ok := catalog.Model(string(model))
+	if !ok {
+		return ""
+	}
+	if m, ok := catalog.Mode(mode); ok && m.APIModel != "" && info.APIMode == "" {
+		return fmt.Sprintf("%s mode always uses %s; model %s is ignored", mode, m.APIModel, model)
+	}
+	if !info.SupportsMode(mode) && mode != models.ModeDefault {
+		modes := make([]string, len(info.Modes))
+		for i, m := range info.Modes {
+			modes[i] = string(m)
+		}
+		return fmt.Sprintf("model %s is meant for %s mode, not %s", model, strings.Join(modes, "/"), mode)
+	}
+	return ""
+}
+
+// completeModels completes a model id with its display name.
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, m := range models.ActiveCatalog().Models {
+		completions = append(completions, string(m.ID)+"\t"+m.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModelList completes the last entry of a comma-separated model list,
+// skipping models already listed.
+func completeModelList(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	listed := make(map[string]bool)
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		for _, m := range strings.Split(toComplete[:i], ",") {
+			listed[strings.TrimSpace(m)] = true
+		}
+	}
+
+	var completions []string
+	for _, m := range models.ActiveCatalog().Models {
+		if !listed[string(m.ID)] {
+			completions = append(completions, prefix+string(m.ID)+"\t"+m.Name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeModes completes a search mode with its description.
+func completeModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, m := range models.ActiveCatalog().Modes {
+		completions = append(completions, string(m.ID)+"\t"+m.Description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	modelsCmd.Flags().StringVar(&modelsMode, "mode", "", "Only list models supporting this mode")
+	modelsCmd.Flags().StringVar(&modelsFormat, "format", "text", "Output format (text, json)")
+	modelsCmd.RegisterFlagCompletionFunc("mode", completeModes)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestFilterCatalog(t *testing.T) {
+	catalog := models.DefaultCatalog()
+
+	all, err := filterCatalog(catalog, "")
+	if err != nil || all != catalog {
+		t.Fatalf("filterCatalog(\"\") = %v, %v", all, err)
+	}
+
+	reasoning, err := filterCatalog(catalog, models.ModeReasoning)
+	if err != nil {
+		t.Fatalf("filterCatalog() error = %v", err)
+	}
+	if len(reasoning.Models) != len(models.AvailableReasoningModels) {
+		t.Errorf("len(Models) = %d, want %d", len(reasoning.Models), len(models.AvailableReasoningModels))
+	}
+	for _, m := range reasoning.Models {
+		if !m.Reasoning {
+			t.Errorf("%s is not a reasoning model", m.ID)
+		}
+	}
+
+	if _, err := filterCatalog(catalog, "turbo"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestWriteModelTable(t *testing.T) {
+	var buf bytes.Buffer
+	writeModelTable(&buf, models.DefaultCatalog(), models.ModelGPT51)
+
+	output := buf.String()
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != len(models.DefaultCatalog().Models)+1 {
+		t.Fatalf("got %d lines, want header plus one per model\n%s", len(lines), output)
+	}
+	for _, want := range []string{"ID", "ALIASES", "gpt51 *", "Claude Sonnet 4.5", "reasoning", "gpt-5.1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	writeModeTable(&buf, models.DefaultCatalog(), models.ModeDefault)
+	if output := buf.String(); !strings.Contains(output, "default *") || !strings.Contains(output, "always uses turbo") {
+		t.Errorf("mode table = %s", output)
+	}
+}
+
+func TestBuildSearchOptions_ModelAlias(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	flagModel = "Claude"
+	defer func() { flagModel = "" }()
+
+	if opts := buildSearchOptions("q"); opts.Model != models.ModelClaude45Sonnet {
+		t.Errorf("Model = %q, want %q", opts.Model, models.ModelClaude45Sonnet)
+	}
+
+	flagModel = "unknown"
+	if opts := buildSearchOptions("q"); opts.Model != "unknown" {
+		t.Errorf("unknown models should pass through, got %q", opts.Model)
+	}
+}
+
+func TestModeCompatibilityWarning(t *testing.T) {
+	tests := []struct {
+		model models.Model
+		mode  models.Mode
+		want  string
+	}{
+		{models.ModelGPT51, models.ModePro, ""},
+		{models.ModelGPT51, models.ModeDefault, ""},
+		{models.ModelGemini30Pro, models.ModeReasoning, ""},
+		{models.ModelGemini30Pro, models.ModePro, "meant for reasoning mode"},
+		{models.ModelGPT51, models.ModeFast, "always uses turbo"},
+		{models.ModelGPT51Thinking, models.ModeReasoning, ""},
+		{"unknown", models.ModePro, ""},
+	}
+
+	for _, tt := range tests {
+		got := modeCompatibilityWarning(tt.model, tt.mode)
+		if (tt.want == "" && got != "") || !strings.Contains(got, tt.want) {
+			t.Errorf("modeCompatibilityWarning(%s, %s) = %q, want %q", tt.model, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteModelList(t *testing.T) {
+	completions, _ := completeModelList(nil, nil, "gpt51,clau")
+
+	if len(completions) != len(models.DefaultCatalog().Models)-1 {
+		t.Errorf("len(completions) = %d", len(completions))
+	}
+	for _, c := range completions {
+		if !strings.HasPrefix(c, "gpt51,") {
+			t.Errorf("completion %q should keep the listed models", c)
+		}
+		if strings.HasPrefix(c, "gpt51,gpt51\t") {
+			t.Errorf("completion %q repeats a listed model", c)
+		}
+	}
+}
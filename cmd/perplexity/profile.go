@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage configuration profiles",
+	Long: `Manage named profiles for separate accounts and configurations.
+
+Each profile has its own config, cookies and history. The default profile
+lives in ~/.perplexity-cli; other profiles live in ~/.perplexity-cli/profiles.
+
+The profile in use is chosen by --profile, then $PERPLEXITY_PROFILE, then
+the profile selected with 'perplexity profile use'.
+
+Examples:
+  perplexity profile create work
+  perplexity --profile work import-cookies cookies.json
+  perplexity profile use work
+  perplexity profile copy default personal
+  perplexity --profile personal "What is Go?"`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewProfileStore()
+		if err != nil {
+			return err
+		}
+		names, err := store.List()
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		writeProfileTable(os.Stdout, store, names, currentProfile())
+		return nil
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an empty profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewProfileStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Create(args[0]); err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		render.RenderSuccess(fmt.Sprintf("Created profile %s", args[0]))
+		render.RenderInfo(fmt.Sprintf("Import cookies with 'perplexity --profile %s import-cookies <file>'", args[0]))
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Set the active profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewProfileStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Use(args[0]); err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		render.RenderSuccess(fmt.Sprintf("Active profile: %s", args[0]))
+		if env := os.Getenv(config.ProfileEnvVar); env != "" && env != args[0] {
+			render.RenderWarning(fmt.Sprintf("%s=%s overrides the active profile", config.ProfileEnvVar, env))
+		}
+		return nil
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a profile with its cookies and history",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewProfileStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(args[0]); err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		render.RenderSuccess(fmt.Sprintf("Deleted profile %s", args[0]))
+		return nil
+	},
+}
+
+var profileCopyCmd = &cobra.Command{
+	Use:               "copy <source> <destination>",
+	Short:             "Create a profile from a copy of another",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProfiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewProfileStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Copy(args[0], args[1]); err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		render.RenderSuccess(fmt.Sprintf("Copied profile %s to %s", args[0], args[1]))
+		return nil
+	},
+}
+
+// writeProfileTable prints profiles with their directories, marking the
+// profile in use.
+func writeProfileTable(w io.Writer, store *config.ProfileStore, names []string, current string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tDIRECTORY")
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\n", marker, name, store.Dir(name))
+	}
+	tw.Flush()
+}
+
+// currentProfile returns the profile the configuration was loaded from.
+func currentProfile() string {
+	if cfgMgr == nil {
+		return config.DefaultProfile
+	}
+	return cfgMgr.Profile()
+}
+
+// completeProfiles completes profile names.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := config.NewProfileStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, _ := store.List()
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileCopyCmd)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+)
+
+func TestProfileCmds(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.ProfileEnvVar, "")
+
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"work"}); err != nil {
+		t.Fatalf("profile create failed: %v", err)
+	}
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"work"}); err == nil {
+		t.Error("Expected error creating an existing profile")
+	}
+	if err := profileCopyCmd.RunE(profileCopyCmd, []string{"work", "personal"}); err != nil {
+		t.Fatalf("profile copy failed: %v", err)
+	}
+	if err := profileUseCmd.RunE(profileUseCmd, []string{"personal"}); err != nil {
+		t.Fatalf("profile use failed: %v", err)
+	}
+
+	store, err := config.NewProfileStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Resolve(""); got != "personal" {
+		t.Errorf("Resolved profile = %q, want personal", got)
+	}
+
+	if err := profileDeleteCmd.RunE(profileDeleteCmd, []string{"personal"}); err != nil {
+		t.Fatalf("profile delete failed: %v", err)
+	}
+	if got, _ := store.Resolve(""); got != config.DefaultProfile {
+		t.Errorf("Resolved profile after delete = %q, want default", got)
+	}
+	if err := profileDeleteCmd.RunE(profileDeleteCmd, []string{config.DefaultProfile}); err == nil {
+		t.Error("Expected error deleting the default profile")
+	}
+}
+
+func TestWriteProfileTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := config.NewProfileStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	writeProfileTable(&buf, store, []string{"default", "work"}, "work")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[2], "* work") {
+		t.Errorf("Current profile not marked: %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[1], "  default") {
+		t.Errorf("Unexpected default row: %q", lines[1])
+	}
+}
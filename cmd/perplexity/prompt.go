@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/diogo/perplexity-go/internal/prompt"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var promptVars []string
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Run reusable prompt templates",
+	Long: `Manage and run named prompt templates from the prompt library.
+
+Templates are Markdown files in the prompts directory (prompts_dir, by
+default ~/.perplexity-cli/prompts). Each file has YAML front matter that
+declares its variables and search defaults (the same keys as query files
+read with -f), followed by a Go text/template body:
+
+  ---
+  description: Compare two technologies
+  mode: pro
+  model: gpt51
+  sources: [web, scholar]
+  vars:
+    - name: a
+      required: true
+    - name: b
+      required: true
+    - name: focus
+      default: performance
+  ---
+  Compare {{.a}} and {{.b}}, focusing on {{.focus}}.
+
+Variables are set with --var, then PERPLEXITY_VAR_<NAME>, then the declared
+default. A variable with "stdin: true" reads piped input when --var does not
+set it, and --var name=- always reads stdin. Command-line flags override the
+template's search defaults.
+
+Examples:
+  perplexity prompt list
+  perplexity prompt show compare
+  perplexity prompt run compare --var a=Go --var b=Rust
+  git diff | perplexity prompt run review`,
+}
+
+var promptListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List prompt templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib := prompt.NewLibrary(cfg.PromptsDir)
+		names, err := lib.Names()
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+		if len(names) == 0 {
+			render.RenderInfo(fmt.Sprintf("No prompt templates in %s", lib.Dir()))
+			return nil
+		}
+
+		templates := make([]*prompt.Template, 0, len(names))
+		for _, name := range names {
+			t, err := lib.Load(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				continue
+			}
+			templates = append(templates, t)
+		}
+		writePromptTable(os.Stdout, templates)
+		return nil
+	},
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "Show a prompt template",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePrompts,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := prompt.NewLibrary(cfg.PromptsDir).Load(args[0])
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
+
+		writePromptDetails(os.Stdout, t)
+		return nil
+	},
+}
+
+var promptRunCmd = &cobra.Command{
+	Use:               "run <name>",
+	Short:             "Render a prompt template and send it",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePrompts,
+	RunE:              runPrompt,
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	t, err := prompt.NewLibrary(cfg.PromptsDir).Load(args[0])
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	isTerminal := isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+	given, err := parsePromptVars(t, promptVars, os.Stdin, isTerminal)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	values, err := t.Resolve(given, os.LookupEnv)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+	query, err := t.Render(values)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	return executeQuery(queryInput{Query: query}, nil, t.Options)
+}
+
+// parsePromptVars parses --var name=value flags. A value of "-" reads stdin,
+// and the template's stdin variable reads piped input when no flag sets it.
+func parsePromptVars(t *prompt.Template, raw []string, stdin io.Reader, isTerminal bool) (map[string]string, error) {
+	given := make(map[string]string, len(raw))
+	readStdin := ""
+	for _, r := range raw {
+		name, value, ok := strings.Cut(r, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected name=value)", r)
+		}
+		if value == "-" {
+			if readStdin != "" {
+				return nil, fmt.Errorf("only one variable can read stdin (%s and %s)", readStdin, name)
+			}
+			readStdin = name
+			continue
+		}
+		given[name] = value
+	}
+
+	if readStdin == "" && !isTerminal {
+		if v, ok := t.StdinVar(); ok {
+			if _, set := given[v.Name]; !set {
+				readStdin = v.Name
+			}
+		}
+	}
+	if readStdin == "" {
+		return given, nil
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from stdin: %w", err)
+	}
+	given[readStdin] = strings.TrimSpace(string(data))
+	return given, nil
+}
+
+// writePromptTable prints the name, description and variables of each
+// template.
+func writePromptTable(w io.Writer, templates []*prompt.Template) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDESCRIPTION\tVARS")
+	for _, t := range templates {
+		vars := make([]string, len(t.Vars))
+		for i, v := range t.Vars {
+			vars[i] = v.Name
+			if v.Required {
+				vars[i] += "*"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Name, t.Description, strings.Join(vars, ", "))
+	}
+	tw.Flush()
+}
+
+// writePromptDetails prints a template's file, search defaults, variables
+// and body.
+func writePromptDetails(w io.Writer, t *prompt.Template) {
+	fmt.Fprintf(w, "Name: %s\n", t.Name)
+	fmt.Fprintf(w, "Path: %s\n", t.Path)
+	if t.Description != "" {
+		fmt.Fprintf(w, "Description: %s\n", t.Description)
+	}
+	if t.Mode != "" {
+		fmt.Fprintf(w, "Mode: %s\n", t.Mode)
+	}
+	if t.Model != "" {
+		fmt.Fprintf(w, "Model: %s\n", t.Model)
+	}
+	if len(t.Sources) > 0 {
+		fmt.Fprintf(w, "Sources: %s\n", strings.Join(t.Sources, ", "))
+	}
+	if t.Language != "" {
+		fmt.Fprintf(w, "Language: %s\n", t.Language)
+	}
+	if len(t.Attachments) > 0 {
+		fmt.Fprintf(w, "Attachments: %s\n", strings.Join(t.Attachments, ", "))
+	}
+	if t.Output != "" {
+		fmt.Fprintf(w, "Output: %s\n", t.Output)
+	}
+	if t.Incognito {
+		fmt.Fprintln(w, "Incognito: true")
+	}
+
+	if len(t.Vars) > 0 {
+		fmt.Fprintln(w, "\nVariables:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, v := range t.Vars {
+			var notes []string
+			if v.Required {
+				notes = append(notes, "required")
+			}
+			if v.Default != "" {
+				notes = append(notes, fmt.Sprintf("default %q", v.Default))
+			}
+			if v.Stdin {
+				notes = append(notes, "stdin")
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", v.Name, v.Description, strings.Join(notes, ", "))
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintf(w, "\n%s\n", t.Body)
+}
+
+func completePrompts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, _ := prompt.NewLibrary(cfg.PromptsDir).Names()
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	promptRunCmd.Flags().StringArrayVar(&promptVars, "var", nil, "Set a template variable (name=value, repeatable; name=- reads stdin)")
+	promptRunCmd.Flags().StringVarP(&flagModel, "model", "m", "", "AI model to use (overrides the template)")
+	promptRunCmd.Flags().StringVar(&flagMode, "mode", "", "Search mode (overrides the template)")
+	promptRunCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Search sources (overrides the template)")
+	promptRunCmd.Flags().StringSliceVar(&flagSites, "site", nil, "Restrict sources to these domains (repeatable or comma-separated)")
+	promptRunCmd.Flags().StringSliceVar(&flagExclude, "exclude-site", nil, "Exclude sources from these domains (repeatable or comma-separated)")
+	promptRunCmd.Flags().StringVar(&flagSince, "since", "", "Only use sources published since a relative age (7d, 2w, 3mo, 1y) or date (2024-01-01)")
+	promptRunCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Response language (overrides the template)")
+	promptRunCmd.Flags().BoolVar(&flagStream, "stream", false, "Enable streaming output")
+	promptRunCmd.Flags().BoolVar(&flagNoStream, "no-stream", false, "Disable streaming output")
+	promptRunCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Don't save to history")
+	promptRunCmd.Flags().StringVarP(&flagOutputFile, "output", "o", "", "Save response to file (format chosen by extension: .md, .html, .json)")
+	promptRunCmd.Flags().StringVar(&flagExportFmt, "export-format", "", "Output file format (text, markdown, html, json)")
+	promptRunCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Path to cookies.json file")
+	promptRunCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
+	promptRunCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Print the request payload and headers (cookies redacted) without sending it")
+	promptRunCmd.RegisterFlagCompletionFunc("model", completeModels)
+	promptRunCmd.RegisterFlagCompletionFunc("mode", completeModes)
+
+	promptCmd.AddCommand(promptListCmd)
+	promptCmd.AddCommand(promptShowCmd)
+	promptCmd.AddCommand(promptRunCmd)
+}
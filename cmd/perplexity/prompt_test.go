@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/internal/prompt"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestParsePromptVars(t *testing.T) {
+	withStdin, err := prompt.ParseTemplate("review", []byte("---\nvars:\n  - name: diff\n    stdin: true\n  - name: lang\n---\n{{.diff}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := prompt.ParseTemplate("plain", []byte("---\nvars:\n  - name: topic\n---\n{{.topic}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		tmpl       *prompt.Template
+		raw        []string
+		stdin      string
+		isTerminal bool
+		want       map[string]string
+		wantErr    string
+	}{
+		{"flags", plain, []string{"topic=Go = fun"}, "", true, map[string]string{"topic": "Go = fun"}, ""},
+		{"empty value", plain, []string{"topic="}, "", true, map[string]string{"topic": ""}, ""},
+		{"dash reads stdin", plain, []string{"topic=-"}, " piped\n", false, map[string]string{"topic": "piped"}, ""},
+		{"stdin var", withStdin, []string{"lang=go"}, "diff\n", false, map[string]string{"diff": "diff", "lang": "go"}, ""},
+		{"stdin var set by flag", withStdin, []string{"diff=x"}, "ignored", false, map[string]string{"diff": "x"}, ""},
+		{"terminal stdin", withStdin, nil, "", true, map[string]string{}, ""},
+		{"no stdin var", plain, nil, "ignored", false, map[string]string{}, ""},
+		{"missing equals", plain, []string{"topic"}, "", true, nil, `invalid --var "topic"`},
+		{"two dashes", plain, []string{"a=-", "b=-"}, "", false, nil, "only one variable can read stdin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePromptVars(tt.tmpl, tt.raw, strings.NewReader(tt.stdin), tt.isTerminal)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parsePromptVars() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePromptVars() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePromptVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSearchOptionsWithPromptDefaults(t *testing.T) {
+	origCfg := cfg
+	origFlagModel, origFlagMode, origFlagSources := flagModel, flagMode, flagSources
+	defer func() {
+		cfg = origCfg
+		flagModel, flagMode, flagSources = origFlagModel, origFlagMode, origFlagSources
+	}()
+
+	cfg = &config.Config{
+		DefaultModel:    models.ModelPplxPro,
+		DefaultMode:     models.ModeDefault,
+		DefaultLanguage: "en-US",
+		DefaultSources:  []models.Source{models.SourceWeb},
+	}
+	defaults := prompt.Options{Mode: "reasoning", Model: "gpt51", Sources: []string{"scholar", " social"}}
+
+	flagModel, flagMode, flagSources = "", "pro", ""
+	opts := buildSearchOptionsWith("q", defaults)
+
+	if opts.Model != models.ModelGPT51 {
+		t.Errorf("Model = %q, want the template model", opts.Model)
+	}
+	if opts.Mode != models.ModePro {
+		t.Errorf("Mode = %q, want the flag mode", opts.Mode)
+	}
+	if opts.Language != "en-US" {
+		t.Errorf("Language = %q, want the config language", opts.Language)
+	}
+	if !reflect.DeepEqual(opts.Sources, []models.Source{"scholar", "social"}) {
+		t.Errorf("Sources = %v, want the template sources", opts.Sources)
+	}
+}
+
+func TestBuildSearchOptionsWithFileIncognitoAndAttachments(t *testing.T) {
+	origCfg, origFlagIncognito := cfg, flagIncognito
+	defer func() { cfg, flagIncognito = origCfg, origFlagIncognito }()
+
+	cfg = &config.Config{Incognito: true}
+	defaults := prompt.Options{
+		Attachments: []string{"/tmp/data.csv", "https://example.com/spec.pdf"},
+	}
+
+	flagIncognito = false
+	opts := buildSearchOptionsWith("q", defaults)
+	if !opts.Incognito {
+		t.Error("Incognito = false, want the config setting (a file can't turn it off)")
+	}
+	if !reflect.DeepEqual(opts.Attachments, []string{"https://example.com/spec.pdf"}) {
+		t.Errorf("Attachments = %v, want only the URL (files are uploaded later)", opts.Attachments)
+	}
+
+	cfg = &config.Config{}
+	if opts := buildSearchOptionsWith("q", defaults); opts.Incognito {
+		t.Error("Incognito = true, want the config setting")
+	}
+	defaults.Incognito = true
+	if opts := buildSearchOptionsWith("q", defaults); !opts.Incognito {
+		t.Error("Incognito = false, want the file to turn it on")
+	}
+}
+
+func TestWritePromptTable(t *testing.T) {
+	tmpl, err := prompt.ParseTemplate("compare", []byte("---\ndescription: Compare two things\nvars:\n  - name: a\n    required: true\n  - name: b\n---\n{{.a}} {{.b}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	writePromptTable(&buf, []*prompt.Template{tmpl})
+	out := buf.String()
+	for _, want := range []string{"NAME", "compare", "Compare two things", "a*, b"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -5,17 +5,17 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
+	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
+	"unicode/utf8"
 
-	"github.com/diogo/perplexity-go/internal/auth"
 	"github.com/mattn/go-isatty"
 	"github.com/diogo/perplexity-go/internal/config"
-	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/internal/export"
+	"github.com/diogo/perplexity-go/internal/prompt"
+	"github.com/diogo/perplexity-go/internal/secret"
 	"github.com/diogo/perplexity-go/internal/ui"
-	"github.com/diogo/perplexity-go/pkg/client"
 	"github.com/diogo/perplexity-go/pkg/models"
 	"github.com/spf13/cobra"
 )
@@ -25,14 +25,23 @@ var (
 	flagModel      string
 	flagMode       string
 	flagSources    string
+	flagSites      []string
+	flagExclude    []string
+	flagSince      string
 	flagLanguage   string
 	flagStream     bool
 	flagNoStream   bool
 	flagIncognito  bool
 	flagOutputFile string
+	flagExportFmt  string
 	flagInputFile  string
+	flagStdin      string
 	flagCookieFile string
 	flagVerbose    bool
+	flagExplain    bool
+	flagDryRun     bool
+	flagDebugHTTP  string
+	flagProfile    string
 
 	// Global config
 	cfg     *config.Config
@@ -50,14 +59,24 @@ It allows you to perform AI-powered searches directly from your terminal
 with support for multiple models, streaming output, and file attachments.
 
 The query can be provided as command-line arguments, from a file (-f), or via stdin (pipe).
+Input piped along with a query argument is added as a fenced context block, or
+uploaded as an attachment when larger than stdin_attach_kb; --stdin changes this.
+
+A query file may start with YAML front matter between "---" lines setting mode,
+model, sources, language, attachments, output and incognito. These override the
+config and are overridden by flags; the rest of the file is the query.
 
 Examples:
   perplexity "What is the capital of France?"
-  perplexity "Explain quantum computing" --model gpt5 --mode pro
+  perplexity "Explain quantum computing" --model gpt51 --mode pro
   perplexity "Latest news on AI" --sources web,scholar --stream
+  perplexity "Go generics" --site go.dev --exclude-site reddit.com --since 30d
   echo "What is Go?" | perplexity
+  git diff | perplexity "Review this change"
   perplexity -f prompt.md --mode pro
-  perplexity -f question.txt -o answer.md`,
+  perplexity -f question.txt -o answer.md
+  perplexity "Compare Go and Rust" -o report.html
+  perplexity "What is Go?" --mode fast --model gpt51 --dry-run`,
 	Args: cobra.ArbitraryArgs,
 	RunE: runQuery,
 }
@@ -71,22 +90,43 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Query flags
-	rootCmd.Flags().StringVarP(&flagModel, "model", "m", "", "AI model to use (pplx_pro, gpt5, claude45sonnet, etc.)")
+	rootCmd.Flags().StringVarP(&flagModel, "model", "m", "", "AI model to use (pplx_pro, gpt51, claude45sonnet, etc.; see 'perplexity models')")
 	rootCmd.Flags().StringVar(&flagMode, "mode", "", "Search mode (fast, pro, reasoning, deep-research, default)")
 	rootCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Search sources (web,scholar,social)")
+	rootCmd.Flags().StringSliceVar(&flagSites, "site", nil, "Restrict sources to these domains (repeatable or comma-separated)")
+	rootCmd.Flags().StringSliceVar(&flagExclude, "exclude-site", nil, "Exclude sources from these domains (repeatable or comma-separated)")
+	rootCmd.Flags().StringVar(&flagSince, "since", "", "Only use sources published since a relative age (7d, 2w, 3mo, 1y) or date (2024-01-01)")
 	rootCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Response language (e.g., en-US, pt-BR)")
 	rootCmd.Flags().BoolVar(&flagStream, "stream", false, "Enable streaming output")
 	rootCmd.Flags().BoolVar(&flagNoStream, "no-stream", false, "Disable streaming output")
 	rootCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Don't save to history")
-	rootCmd.Flags().StringVarP(&flagOutputFile, "output", "o", "", "Save response to file")
-	rootCmd.Flags().StringVarP(&flagInputFile, "file", "f", "", "Read query from file (takes precedence over args/stdin)")
+	rootCmd.Flags().StringVarP(&flagOutputFile, "output", "o", "", "Save response to file (format chosen by extension: .md, .html, .json)")
+	rootCmd.Flags().StringVar(&flagExportFmt, "export-format", "", "Output file format (text, markdown, html, json)")
+	rootCmd.Flags().StringVarP(&flagInputFile, "file", "f", "", "Read query from file, with optional YAML front matter setting search options (takes precedence over args/stdin)")
+	rootCmd.Flags().StringVar(&flagStdin, "stdin", "", "How piped input combines with a query argument: context, query or ignore (default: stdin_mode)")
 	rootCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Path to cookies.json file")
 	rootCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
+	rootCmd.Flags().BoolVar(&flagExplain, "explain", false, "Explain how flags map to the request before sending it")
+	rootCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Print the request payload and headers (cookies redacted) without sending it")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Configuration profile to use (default: $PERPLEXITY_PROFILE or the active profile)")
+	rootCmd.PersistentFlags().StringVar(&flagDebugHTTP, "debug-http", "", "Log HTTP traffic to this file with session tokens and other secrets masked")
+	rootCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.RegisterFlagCompletionFunc("mode", completeModes)
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.RegisterFlagCompletionFunc("stdin", completeStdinModes)
 
 	// Add subcommands
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(profileCmd)
 	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(modelsCmd)
+	rootCmd.AddCommand(threadCmd)
+	rootCmd.AddCommand(libraryCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(cookiesCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(importCookiesCmd)
 }
@@ -94,46 +134,79 @@ func init() {
 func initConfig() {
 	var err error
 
+	// Pick the profile before loading its configuration
+	store, err := config.NewProfileStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing config: %v\n", err)
+		os.Exit(1)
+	}
+	profile, err := store.Resolve(flagProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error selecting profile: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize config manager
-	cfgMgr, err = config.NewManager()
+	cfgMgr, err = config.NewManagerForProfile(profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Load configuration
+	// Load configuration. doctor runs on the defaults instead, so it can
+	// report the broken config file
 	cfg, err = cfgMgr.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		if !runningDoctor() {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		configLoadErr = err
+		cfg = cfgMgr.Defaults()
 	}
 
+	// Encrypted files are opened with a passphrase from the environment, the
+	// key file or a terminal prompt
+	secret.Configure(cfg.EncryptAtRest, secret.ChainSource(cfg.PassphraseFile))
+
 	// Initialize renderer
 	render, err = ui.NewRenderer()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing renderer: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Report migrated settings and invalid values replaced by defaults on
+	// stderr so they do not mix with command output
+	for _, warning := range cfgMgr.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
 }
 
 func runQuery(cmd *cobra.Command, args []string) error {
-	var query string
+	var input queryInput
+	var fileOpts prompt.Options
 	var err error
 
-	// Priority: -f/--file > args > stdin
+	// Priority: -f/--file > args (with piped context) > stdin
 	// 1. Check if -f/--file flag is provided
 	if flagInputFile != "" {
-		query, err = getQueryFromFile(flagInputFile)
+		input.Query, fileOpts, err = getQueryFromFile(flagInputFile)
 		if err != nil {
 			render.RenderError(err)
 			return err
 		}
 	}
 
-	// 2. If query is still empty, try args or stdin
-	if query == "" {
+	// 2. If query is still empty, try args and stdin
+	if input.Query == "" {
+		pipe, err := stdinSettings()
+		if err != nil {
+			render.RenderError(err)
+			return err
+		}
 		isTerminal := isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
-		query, err = getQueryFromInput(args, os.Stdin, isTerminal)
+		input, err = getQueryFromInput(args, os.Stdin, isTerminal, pipe)
 		if err != nil {
 			render.RenderError(err)
 			return err
@@ -141,186 +214,123 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 
 	// If query is still empty, show help
-	if query == "" {
+	if input.Query == "" {
 		return cmd.Help()
 	}
 
-	// Determine cookie file
-	cookieFile := cfg.CookieFile
-	if flagCookieFile != "" {
-		cookieFile = flagCookieFile
-	}
+	return executeQuery(input, args, fileOpts)
+}
 
-	// Check if cookies exist
-	if _, err := os.Stat(cookieFile); os.IsNotExist(err) {
-		render.RenderError(fmt.Errorf("cookies file not found: %s", cookieFile))
-		render.RenderInfo("Run 'perplexity import-cookies <file>' to import cookies from browser")
-		return fmt.Errorf("no cookies found")
-	}
+// executeQuery sends a query with options layered from the config, the
+// prompt defaults and the flags, then records and saves the answer. Piped
+// input in input.Attachment and the prompt's attachment files are uploaded
+// and attached first.
+func executeQuery(input queryInput, args []string, defaults prompt.Options) error {
+	var err error
 
-	// Load cookies
-	cookies, err := auth.LoadCookiesFromFile(cookieFile)
-	if err != nil {
-		render.RenderError(fmt.Errorf("failed to load cookies: %v", err))
+	// Build and validate search options
+	query := withPromptPrefix(input.Query)
+	opts := buildSearchOptionsWith(query, defaults)
+	if err := validateSearchOptions(opts); err != nil {
+		render.RenderError(err)
 		return err
 	}
-
-	// Create client
-	cli, err := client.NewWithCookies(cookies)
+	opts.Filters, err = buildSearchFilters(time.Now())
 	if err != nil {
-		render.RenderError(fmt.Errorf("failed to create client: %v", err))
+		render.RenderError(err)
 		return err
 	}
-	defer cli.Close()
-
-	// Build search options
-	opts := buildSearchOptions(query)
-
-	// Setup context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle interrupt
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		cancel()
-	}()
+	for _, warning := range searchFlagWarnings(opts, args) {
+		render.RenderWarning(warning)
+	}
 
 	// Determine if streaming
-	streaming := cfg.Streaming
-	if flagStream {
-		streaming = true
-	}
-	if flagNoStream {
-		streaming = false
+	opts.Stream = streamingEnabled()
+
+	files := defaults.AttachmentFiles()
+	if flagDryRun {
+		if len(input.Attachment) > 0 {
+			render.RenderInfo(fmt.Sprintf("Piped input (%d KB) would be uploaded as %s", sizeKB(len(input.Attachment)), stdinAttachmentName))
+		}
+		for _, f := range files {
+			render.RenderInfo(fmt.Sprintf("%s would be uploaded", f))
+		}
+		return runDryRun(opts)
 	}
-	opts.Stream = streaming
 
-	if flagVerbose {
-		render.RenderInfo(fmt.Sprintf("Query: %s", query))
-		render.RenderInfo(fmt.Sprintf("Mode: %s, Model: %s", opts.Mode, opts.Model))
-		render.RenderInfo(fmt.Sprintf("Streaming: %v", streaming))
-		render.NewLine()
+	// Create client
+	cli, err := newClient()
+	if err != nil {
+		return err
 	}
+	defer cli.Close()
 
-	var responseText string
+	urls, err := uploadAttachments(cli, input, files)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+	opts.Attachments = append(opts.Attachments, urls...)
 
-	if streaming {
-		// Streaming mode
-		ch, err := cli.SearchStream(ctx, opts)
+	if flagExplain {
+		preview, err := cli.PreviewSearch(opts)
+		if err == nil {
+			err = renderExplanation(opts, preview)
+		}
 		if err != nil {
-			render.RenderError(err) // Render error from stream initiation
+			render.RenderError(err)
 			return err
 		}
+	}
 
-		var fullResponse strings.Builder
-		var allWebResults []models.WebResult
-		var renderedFinal bool // Track if we already rendered a FINAL response
-		for chunk := range ch {
-			if chunk.Error != nil { // Handle all chunk errors
-				if chunk.Error == context.Canceled {
-					render.NewLine()
-					render.RenderWarning("Search cancelled")
-					break // Exit loop on cancel
-				}
-				// Report other errors
-				render.RenderError(chunk.Error)
-				return chunk.Error
-			}
-
-			// For new step-based format, only render FINAL step
-			if chunk.StepType == "FINAL" && chunk.Text != "" {
-				// Render as markdown instead of raw text
-				if err := render.RenderStyledResponse(chunk.Text); err != nil {
-					render.RenderStreamChunk(chunk)
-				}
-				fullResponse.WriteString(chunk.Text)
-				allWebResults = append(allWebResults, chunk.WebResults...)
-				renderedFinal = true
-			} else if chunk.StepType == "" {
-				// Legacy format - render as stream
-				render.RenderStreamChunk(chunk)
-				if chunk.Delta != "" {
-					fullResponse.WriteString(chunk.Delta)
-				} else if chunk.Text != "" {
-					fullResponse.WriteString(chunk.Text)
-				}
-			}
-		}
-		render.NewLine()
-
-		// Post-stream rendering only for legacy format (token-by-token streaming)
-		// Skip if we already rendered a FINAL step response
-		if fullResponse.Len() > 0 && !renderedFinal {
-			if err := render.RenderStyledResponse(fullResponse.String()); err != nil {
-				// If final styled rendering fails, the raw stream output is still there.
-				render.RenderError(fmt.Errorf("failed to render final response: %w", err))
-			}
-		}
-
-		// Render web results if any
-		if len(allWebResults) > 0 {
-			render.RenderWebResults(allWebResults)
-		}
-
-		responseText = fullResponse.String()
-	} else {
-		// Non-streaming mode with spinner
-		done := make(chan struct{})
-		go func() {
-			frame := 0
-			for {
-				select {
-				case <-done:
-					render.ClearLine()
-					return
-				case <-time.After(100 * time.Millisecond):
-					render.RenderSpinner(frame)
-					frame++
-				}
-			}
-		}()
-
-		resp, err := cli.Search(ctx, opts)
-		close(done)
+	// Setup context with cancellation on interrupt
+	ctx, cancel := signalContext()
+	defer cancel()
 
-		if err != nil {
-			if err == context.Canceled {
-				render.RenderWarning("Search cancelled")
-				return nil
-			}
-			render.RenderError(err)
-			return err
+	if flagVerbose {
+		render.RenderInfo(fmt.Sprintf("Profile: %s", currentProfile()))
+		render.RenderInfo(fmt.Sprintf("Query: %s", query))
+		render.RenderInfo(fmt.Sprintf("Mode: %s, Model: %s", opts.Mode, opts.Model))
+		render.RenderInfo(fmt.Sprintf("Streaming: %v", opts.Stream))
+		if !opts.Filters.IsEmpty() {
+			render.RenderInfo(fmt.Sprintf("Filters: %s", opts.Filters))
 		}
+		render.NewLine()
+	}
 
-		if err := render.RenderResponse(resp); err != nil {
-			render.RenderError(err)
-			return err
+	started := time.Now()
+	result, err := performSearch(ctx, cli, opts)
+	saveHistory(opts, result, time.Since(started), err)
+	if err != nil {
+		if err == context.Canceled {
+			return nil
 		}
-		responseText = resp.Text
+		return err
 	}
+	responseText := result.Text
+	webResults := result.WebResults
+	backendUUID := result.BackendUUID
 
 	// Save to output file if specified
-	if flagOutputFile != "" {
-		if err := os.WriteFile(flagOutputFile, []byte(responseText), 0644); err != nil {
+	outputFile := flagOutputFile
+	if outputFile == "" {
+		outputFile = defaults.Output
+	}
+	if outputFile != "" {
+		doc := export.Document{
+			Query:       query,
+			Answer:      responseText,
+			Mode:        string(opts.Mode),
+			Model:       string(opts.Model),
+			Language:    opts.Language,
+			BackendUUID: backendUUID,
+			Date:        time.Now(),
+			Sources:     webResults,
+		}
+		if err := saveOutput(outputFile, flagExportFmt, doc); err != nil {
 			render.RenderError(fmt.Errorf("failed to save output: %v", err))
 		} else {
-			render.RenderSuccess(fmt.Sprintf("Saved to %s", flagOutputFile))
-		}
-	}
-
-	// Save to history if not incognito
-	if !flagIncognito && !cfg.Incognito {
-		hw, err := history.NewWriter(cfg.HistoryFile)
-		if err == nil {
-			hw.Append(models.HistoryEntry{
-				Query:    query,
-				Mode:     string(opts.Mode),
-				Model:    string(opts.Model),
-				Response: truncateResponse(responseText, 500),
-			})
+			render.RenderSuccess(fmt.Sprintf("Saved to %s", outputFile))
 		}
 	}
 
@@ -328,6 +338,12 @@ func runQuery(cmd *cobra.Command, args []string) error {
 }
 
 func buildSearchOptions(query string) models.SearchOptions {
+	return buildSearchOptionsWith(query, prompt.Options{})
+}
+
+// buildSearchOptionsWith builds search options from the config defaults,
+// then the defaults of a prompt file, then the flags.
+func buildSearchOptionsWith(query string, defaults prompt.Options) models.SearchOptions {
 	opts := models.DefaultSearchOptions(query)
 
 	// Apply config defaults
@@ -337,9 +353,27 @@ func buildSearchOptions(query string) models.SearchOptions {
 	opts.Sources = cfg.DefaultSources
 	opts.Incognito = cfg.Incognito
 
+	// Apply prompt defaults
+	if defaults.Model != "" {
+		opts.Model = resolveModel(defaults.Model)
+	}
+	if defaults.Mode != "" {
+		opts.Mode = models.Mode(defaults.Mode)
+	}
+	if defaults.Language != "" {
+		opts.Language = defaults.Language
+	}
+	if len(defaults.Sources) > 0 {
+		opts.Sources = parseSourceList(defaults.Sources)
+	}
+	if defaults.Incognito {
+		opts.Incognito = true
+	}
+	opts.Attachments = defaults.AttachmentURLs()
+
 	// Override with flags
 	if flagModel != "" {
-		opts.Model = models.Model(flagModel)
+		opts.Model = resolveModel(flagModel)
 	}
 	if flagMode != "" {
 		opts.Mode = models.Mode(flagMode)
@@ -348,11 +382,7 @@ func buildSearchOptions(query string) models.SearchOptions {
 		opts.Language = flagLanguage
 	}
 	if flagSources != "" {
-		sources := strings.Split(flagSources, ",")
-		opts.Sources = make([]models.Source, 0, len(sources))
-		for _, s := range sources {
-			opts.Sources = append(opts.Sources, models.Source(strings.TrimSpace(s)))
-		}
+		opts.Sources = parseSourceList(strings.Split(flagSources, ","))
 	}
 	if flagIncognito {
 		opts.Incognito = true
@@ -361,42 +391,119 @@ func buildSearchOptions(query string) models.SearchOptions {
 	return opts
 }
 
+// parseSourceList converts source names to sources, trimming whitespace.
+func parseSourceList(names []string) []models.Source {
+	sources := make([]models.Source, 0, len(names))
+	for _, s := range names {
+		sources = append(sources, models.Source(strings.TrimSpace(s)))
+	}
+	return sources
+}
+
+// withPromptPrefix prepends the configured prompt prefix to a new query.
+func withPromptPrefix(query string) string {
+	prefix := strings.TrimSpace(cfg.PromptPrefix)
+	if prefix == "" {
+		return query
+	}
+	return prefix + "\n\n" + query
+}
+
+// buildSearchFilters builds source filters from --site, --exclude-site and --since.
+// It returns nil when no filter flag is set.
+func buildSearchFilters(now time.Time) (*models.SearchFilters, error) {
+	filters := &models.SearchFilters{
+		Sites:        normalizeDomains(flagSites),
+		ExcludeSites: normalizeDomains(flagExclude),
+	}
+
+	since, err := models.ParseSince(flagSince, now)
+	if err != nil {
+		return nil, err
+	}
+	filters.Since = since
+
+	if filters.IsEmpty() {
+		return nil, nil
+	}
+	return filters, nil
+}
+
+// normalizeDomains normalizes and deduplicates domain flag values.
+func normalizeDomains(raw []string) []string {
+	var domains []string
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		d := models.NormalizeDomain(r)
+		if d != "" && !seen[d] {
+			domains = append(domains, d)
+			seen[d] = true
+		}
+	}
+	return domains
+}
+
+// saveOutput writes the answer to path using the explicit format, or one
+// inferred from the file extension when format is empty.
+func saveOutput(path, format string, doc export.Document) error {
+	exportFormat := export.FormatFromPath(path)
+	if format != "" {
+		var err error
+		exportFormat, err = export.ParseFormat(format)
+		if err != nil {
+			return err
+		}
+	}
+	return export.WriteFile(path, doc, exportFormat)
+}
+
+// truncateResponse shortens s to at most maxLen bytes without splitting a
+// multibyte UTF-8 character.
 func truncateResponse(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
 	return s[:maxLen] + "..."
 }
 
-// getQueryFromInput extracts the query from command-line args or stdin.
-// This function is exported for testing purposes.
-func getQueryFromInput(args []string, stdin io.Reader, isTerminal bool) (string, error) {
-	if len(args) > 0 {
-		return strings.Join(args, " "), nil
+// getQueryFromInput extracts the query from command-line args and stdin.
+// Piped input is the query when there are no args; otherwise pipe decides
+// whether it is ignored, added as context or appended to the query.
+func getQueryFromInput(args []string, stdin io.Reader, isTerminal bool, pipe stdinOptions) (queryInput, error) {
+	query := strings.Join(args, " ")
+	if isTerminal || (query != "" && pipe.Mode == config.StdinIgnore) {
+		return queryInput{Query: query}, nil
 	}
 
-	// If stdin is not a terminal, try to read from it
-	if !isTerminal {
-		data, err := io.ReadAll(stdin)
-		if err != nil {
-			return "", fmt.Errorf("failed to read from stdin: %w", err)
-		}
-		return strings.TrimSpace(string(data)), nil
+	piped, err := readPiped(stdin)
+	if err != nil {
+		return queryInput{}, err
 	}
-
-	return "", nil
+	return combineStdin(query, piped, pipe), nil
 }
 
-// getQueryFromFile reads the query content from a file.
-// Returns the trimmed content or an error if the file cannot be read.
-func getQueryFromFile(path string) (string, error) {
+// getQueryFromFile reads the query content from a file. Optional YAML front
+// matter sets search options, with attachments relative to the file.
+// Returns the trimmed body or an error if the file cannot be read.
+func getQueryFromFile(path string) (string, prompt.Options, error) {
+	var opts prompt.Options
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to read input file %s: %w", path, err)
+		return "", opts, fmt.Errorf("failed to read input file %s: %w", path, err)
 	}
-	content := strings.TrimSpace(string(data))
+	body, err := prompt.ParseFrontMatter(data, &opts)
+	if err != nil {
+		return "", opts, fmt.Errorf("input file %s: %w", path, err)
+	}
+	content := strings.TrimSpace(string(body))
 	if content == "" {
-		return "", fmt.Errorf("input file %s is empty", path)
+		return "", opts, fmt.Errorf("input file %s is empty", path)
+	}
+	if err := opts.ResolvePaths(filepath.Dir(path)); err != nil {
+		return "", opts, fmt.Errorf("input file %s: %w", path, err)
 	}
-	return content, nil
+	return content, opts, nil
 }
@@ -8,11 +8,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	http "github.com/bogdanfinn/fhttp"
 	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/internal/export"
 	"github.com/diogo/perplexity-go/internal/ui"
 	"github.com/diogo/perplexity-go/pkg/models"
 	"github.com/spf13/cobra"
@@ -238,11 +241,11 @@ func TestGetQueryFromInput(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name:       "args take precedence over stdin",
+			name:       "stdin added as context to args",
 			args:       []string{"from args"},
 			stdin:      strings.NewReader("from stdin"),
 			isTerminal: false,
-			want:       "from args",
+			want:       "from args\n\n```\nfrom stdin\n```",
 			wantErr:    false,
 		},
 		{
@@ -273,15 +276,15 @@ func TestGetQueryFromInput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getQueryFromInput(tt.args, tt.stdin, tt.isTerminal)
+			got, err := getQueryFromInput(tt.args, tt.stdin, tt.isTerminal, stdinOptions{Mode: config.StdinContext})
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getQueryFromInput() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if got != tt.want {
-				t.Errorf("getQueryFromInput() = %q, want %q", got, tt.want)
+			if got.Query != tt.want {
+				t.Errorf("getQueryFromInput() = %q, want %q", got.Query, tt.want)
 			}
 		})
 	}
@@ -290,14 +293,14 @@ func TestGetQueryFromInput(t *testing.T) {
 func TestGetQueryFromInput_BufferReader(t *testing.T) {
 	// Test with bytes.Buffer (different Reader implementation)
 	buf := bytes.NewBuffer([]byte("buffer query"))
-	got, err := getQueryFromInput([]string{}, buf, false)
+	got, err := getQueryFromInput([]string{}, buf, false, stdinOptions{Mode: config.StdinContext})
 
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	if got != "buffer query" {
-		t.Errorf("got %q, want %q", got, "buffer query")
+	if got.Query != "buffer query" {
+		t.Errorf("got %q, want %q", got.Query, "buffer query")
 	}
 }
 
@@ -436,7 +439,7 @@ func TestGetQueryFromFile(t *testing.T) {
 				filePath = filepath.Join(tempDir, "nonexistent_"+tt.name+".txt")
 			}
 
-			got, err := getQueryFromFile(filePath)
+			got, _, err := getQueryFromFile(filePath)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getQueryFromFile() error = %v, wantErr %v", err, tt.wantErr)
@@ -467,7 +470,7 @@ func TestGetQueryFromFile_SpecialCharacters(t *testing.T) {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	got, err := getQueryFromFile(filePath)
+	got, _, err := getQueryFromFile(filePath)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -476,6 +479,62 @@ func TestGetQueryFromFile_SpecialCharacters(t *testing.T) {
 	}
 }
 
+func TestGetQueryFromFile_FrontMatter(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "data.csv"), []byte("a,b"), 0644); err != nil {
+		t.Fatalf("failed to create attachment: %v", err)
+	}
+
+	filePath := filepath.Join(tempDir, "prompt.md")
+	content := "---\nmode: pro\nmodel: gpt51\nsources: [web, scholar]\nlanguage: pt-BR\n" +
+		"attachments: [data.csv, https://example.com/spec.pdf]\noutput: answer.md\nincognito: false\n---\n\nSummarize the data.\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	got, opts, err := getQueryFromFile(filePath)
+	if err != nil {
+		t.Fatalf("getQueryFromFile() error = %v", err)
+	}
+	if got != "Summarize the data." {
+		t.Errorf("query = %q, want the body", got)
+	}
+	if opts.Mode != "pro" || opts.Model != "gpt51" || opts.Language != "pt-BR" || len(opts.Sources) != 2 {
+		t.Errorf("opts = %+v", opts)
+	}
+	if opts.Output != filepath.Join(tempDir, "answer.md") || opts.Incognito {
+		t.Errorf("Output = %q, Incognito = %v, want the output relative to the file", opts.Output, opts.Incognito)
+	}
+	wantAttachments := []string{filepath.Join(tempDir, "data.csv"), "https://example.com/spec.pdf"}
+	if !reflect.DeepEqual(opts.Attachments, wantAttachments) {
+		t.Errorf("Attachments = %v, want %v", opts.Attachments, wantAttachments)
+	}
+
+	errorCases := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{"unknown field", "---\nmodle: gpt51\n---\nquery", "modle"},
+		{"missing attachment", "---\nattachments: [missing.txt]\n---\nquery", "attachment missing.txt"},
+		{"attachment outside the directory", "---\nattachments: [../id_rsa]\n---\nquery", "attachment ../id_rsa: outside"},
+		{"output outside the directory", "---\noutput: /etc/cron.d/x\n---\nquery", "output /etc/cron.d/x: outside"},
+		{"front matter only", "---\nmode: pro\n---\n", "is empty"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.name+".md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			_, _, err := getQueryFromFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("getQueryFromFile() error = %v, should contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
 func TestGetQueryFromFile_PermissionDenied(t *testing.T) {
 	// Skip on Windows as permission handling is different
 	if os.Getenv("GOOS") == "windows" {
@@ -492,7 +551,7 @@ func TestGetQueryFromFile_PermissionDenied(t *testing.T) {
 	}
 	defer os.Chmod(filePath, 0644) // Cleanup
 
-	_, err = getQueryFromFile(filePath)
+	_, _, err = getQueryFromFile(filePath)
 	if err == nil {
 		t.Error("expected error for permission denied, got nil")
 	}
@@ -510,18 +569,19 @@ func TestQueryInputPriority(t *testing.T) {
 
 		// Priority 1: -f/--file flag
 		if inputFile != "" {
-			query, err = getQueryFromFile(inputFile)
+			query, _, err = getQueryFromFile(inputFile)
 			if err != nil {
 				return "", err
 			}
 		}
 
-		// Priority 2: args or stdin
+		// Priority 2: args and stdin
 		if query == "" {
-			query, err = getQueryFromInput(args, stdin, isTerminal)
+			input, err := getQueryFromInput(args, stdin, isTerminal, stdinOptions{Mode: config.StdinContext})
 			if err != nil {
 				return "", err
 			}
+			query = input.Query
 		}
 
 		return query, nil
@@ -560,7 +620,7 @@ func TestQueryInputPriority(t *testing.T) {
 	})
 
 	t.Run("args used when no file specified", func(t *testing.T) {
-		got, err := getQueryWithPriority("", []string{"from args"}, strings.NewReader("from stdin"), false)
+		got, err := getQueryWithPriority("", []string{"from args"}, strings.NewReader(""), true)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -569,6 +629,16 @@ func TestQueryInputPriority(t *testing.T) {
 		}
 	})
 
+	t.Run("args combined with stdin when no file specified", func(t *testing.T) {
+		got, err := getQueryWithPriority("", []string{"from args"}, strings.NewReader("from stdin"), false)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if want := "from args\n\n```\nfrom stdin\n```"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
 	t.Run("stdin used when no file and no args", func(t *testing.T) {
 		got, err := getQueryWithPriority("", []string{}, strings.NewReader("from stdin"), false)
 		if err != nil {
@@ -636,6 +706,101 @@ func TestOutputFileSaving(t *testing.T) {
 	}
 }
 
+// TestSaveOutput tests that the export format follows the extension or explicit flag
+func TestSaveOutput(t *testing.T) {
+	tempDir := t.TempDir()
+
+	doc := export.Document{
+		Query:   "What is Go?",
+		Answer:  "Go is a language [1].",
+		Model:   "gpt51",
+		Sources: []models.WebResult{{Title: "Go", URL: "https://go.dev"}},
+	}
+
+	tests := []struct {
+		name       string
+		filename   string
+		format     string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{"text by extension", "out.txt", "", "Go is a language [1].", false},
+		{"markdown by extension", "out.md", "", "---\n", false},
+		{"html by extension", "out.html", "", "<!DOCTYPE html>", false},
+		{"json by extension", "out.json", "", "{", false},
+		{"explicit format overrides extension", "out.txt", "markdown", "---\n", false},
+		{"invalid explicit format", "out.txt", "pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.filename)
+			err := saveOutput(path, tt.format, doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("saveOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read output file: %v", err)
+			}
+			if !strings.HasPrefix(string(data), tt.wantPrefix) {
+				t.Errorf("output starts with %q, want prefix %q", string(data), tt.wantPrefix)
+			}
+		})
+	}
+}
+
+// TestBuildSearchFilters tests that filter flags are normalized and parsed
+func TestBuildSearchFilters(t *testing.T) {
+	origSites, origExclude, origSince := flagSites, flagExclude, flagSince
+	defer func() {
+		flagSites, flagExclude, flagSince = origSites, origExclude, origSince
+	}()
+
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no flags returns nil", func(t *testing.T) {
+		flagSites, flagExclude, flagSince = nil, nil, ""
+		filters, err := buildSearchFilters(now)
+		if err != nil {
+			t.Fatalf("buildSearchFilters() error = %v", err)
+		}
+		if filters != nil {
+			t.Errorf("filters = %+v, want nil", filters)
+		}
+	})
+
+	t.Run("normalizes and deduplicates domains", func(t *testing.T) {
+		flagSites = []string{"https://www.Go.dev/doc", "go.dev", "golang.org"}
+		flagExclude = []string{"reddit.com"}
+		flagSince = "7d"
+		filters, err := buildSearchFilters(now)
+		if err != nil {
+			t.Fatalf("buildSearchFilters() error = %v", err)
+		}
+		if len(filters.Sites) != 2 || filters.Sites[0] != "go.dev" || filters.Sites[1] != "golang.org" {
+			t.Errorf("Sites = %v", filters.Sites)
+		}
+		if len(filters.ExcludeSites) != 1 || filters.ExcludeSites[0] != "reddit.com" {
+			t.Errorf("ExcludeSites = %v", filters.ExcludeSites)
+		}
+		if !filters.Since.Equal(now.AddDate(0, 0, -7)) {
+			t.Errorf("Since = %v", filters.Since)
+		}
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		flagSites, flagExclude, flagSince = nil, nil, "soon"
+		if _, err := buildSearchFilters(now); err == nil {
+			t.Error("expected error for invalid --since")
+		}
+	})
+}
+
 // TestBuildSearchOptions tests the buildSearchOptions function
 func TestBuildSearchOptions(t *testing.T) {
 	// Save current global state
@@ -1024,7 +1189,7 @@ func TestRunQueryIntegration(t *testing.T) {
 		flagStream = false
 
 		// Verify the file can be read
-		content, err := getQueryFromFile(queryFile)
+		content, _, err := getQueryFromFile(queryFile)
 		if err != nil {
 			t.Fatalf("failed to read query file: %v", err)
 		}
@@ -1053,12 +1218,12 @@ func TestRunQueryIntegration(t *testing.T) {
 		stdin := strings.NewReader("query from stdin")
 		isTerminal := false
 
-		query, err := getQueryFromInput([]string{}, stdin, isTerminal)
+		input, err := getQueryFromInput([]string{}, stdin, isTerminal, stdinOptions{Mode: config.StdinContext})
 		if err != nil {
 			t.Fatalf("failed to read from stdin: %v", err)
 		}
-		if query != "query from stdin" {
-			t.Errorf("expected 'query from stdin', got %q", query)
+		if input.Query != "query from stdin" {
+			t.Errorf("expected 'query from stdin', got %q", input.Query)
 		}
 	})
 
@@ -1095,7 +1260,7 @@ func TestQueryFileWithEmptyContent(t *testing.T) {
 	}
 
 	// Try to read empty file - should return error
-	_, err = getQueryFromFile(emptyFile)
+	_, _, err = getQueryFromFile(emptyFile)
 	if err == nil {
 		t.Error("expected error for empty file, got nil")
 	}
@@ -1171,6 +1336,8 @@ func TestTruncateResponseEdgeCases(t *testing.T) {
 		{"zero length", "", 10, ""},
 		{"zero max", "hello", 0, "..."},
 		{"very long string", strings.Repeat("x", 1000), 10, strings.Repeat("x", 10) + "..."},
+		{"multibyte not split", "héllo", 2, "h..."},
+		{"multibyte boundary", "日本語", 6, "日本..."},
 	}
 
 	for _, tt := range tests {
@@ -1294,3 +1461,17 @@ func TestExecute(t *testing.T) {
 		}
 	})
 }
+
+func TestWithPromptPrefix(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if got := withPromptPrefix("What is Go?"); got != "What is Go?" {
+		t.Errorf("withPromptPrefix() without prefix = %q", got)
+	}
+
+	cfg.PromptPrefix = "Answer for Go developers.\n"
+	if got, want := withPromptPrefix("What is a slice?"), "Answer for Go developers.\n\nWhat is a slice?"; got != want {
+		t.Errorf("withPromptPrefix() = %q, want %q", got, want)
+	}
+}
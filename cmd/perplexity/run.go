@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/export"
+	"github.com/diogo/perplexity-go/internal/prompt"
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <script>",
+	Short: "Run a multi-turn conversation script in one thread",
+	Long: `Send the turns of a conversation script one after another, each as a
+follow-up in the same thread, and save the whole transcript as one document.
+
+Turns are separated by lines containing only "+++". Front matter at the top
+of the script sets the title, output file and options for every turn; a
+turn may start with its own front matter to change mode, model, sources,
+language or attachments for that turn only:
+
+  ---
+  title: CRDT research
+  mode: pro
+  output: crdt.md
+  ---
+  What are CRDTs?
+  +++
+  ---
+  mode: reasoning
+  ---
+  How do they compare to operational transformation?
+  +++
+  Which libraries implement them in Go?
+
+The transcript format follows the output file's extension (.md, .json,
+.html) unless --export-format is given. Command-line flags override the
+options of every turn. Every turn is checked before the first is sent, and
+the run stops at the first failed turn, saving the turns answered so far.
+
+Examples:
+  perplexity run research.md
+  perplexity run research.md -o transcript.json
+  perplexity run research.md --model claude45sonnet --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runScript,
+}
+
+func runScript(cmd *cobra.Command, args []string) error {
+	script, err := prompt.LoadScript(args[0])
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	turns, err := buildTurnOptions(script)
+	if err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	outputFile := flagOutputFile
+	if outputFile == "" {
+		outputFile = script.Output
+	}
+	format := export.FormatFromPath(outputFile)
+	if flagExportFmt != "" {
+		if format, err = export.ParseFormat(flagExportFmt); err != nil {
+			render.RenderError(err)
+			return err
+		}
+	}
+
+	if flagDryRun {
+		for i, opts := range turns {
+			render.RenderInfo(fmt.Sprintf("Turn %d/%d", i+1, len(turns)))
+			for _, f := range script.TurnOptions(i).AttachmentFiles() {
+				render.RenderInfo(fmt.Sprintf("%s would be uploaded", f))
+			}
+			if err := runDryRun(opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	transcript := export.Transcript{Title: script.Title, Date: time.Now()}
+	if transcript.Title == "" {
+		transcript.Title = truncateResponse(strings.Join(strings.Fields(script.Turns[0].Query), " "), 80)
+	}
+	group := uuid.NewString()
+
+	var backendUUID string
+	for i, opts := range turns {
+		if i > 0 {
+			if backendUUID == "" {
+				err = fmt.Errorf("turn %d returned no backend UUID; cannot continue the thread", i)
+				render.RenderError(err)
+				break
+			}
+			opts.FollowUp = &models.FollowUpContext{BackendUUID: backendUUID}
+			render.NewLine()
+		}
+
+		urls, uploadErr := uploadAttachments(cli, queryInput{}, script.TurnOptions(i).AttachmentFiles())
+		if uploadErr != nil {
+			err = fmt.Errorf("turn %d: %w", i+1, uploadErr)
+			render.RenderError(err)
+			break
+		}
+		opts.Attachments = append(opts.Attachments, urls...)
+
+		render.RenderInfo(fmt.Sprintf("Turn %d/%d: %s", i+1, len(turns), truncateResponse(strings.Join(strings.Fields(opts.Query), " "), 80)))
+		if flagVerbose {
+			render.RenderInfo(fmt.Sprintf("Mode: %s, Model: %s", opts.Mode, opts.Model))
+		}
+
+		started := time.Now()
+		result, searchErr := performSearch(ctx, cli, opts)
+		saveHistoryGroup(opts, result, time.Since(started), searchErr, group)
+		if searchErr != nil {
+			if !errors.Is(searchErr, context.Canceled) {
+				err = fmt.Errorf("turn %d: %w", i+1, searchErr)
+			}
+			break
+		}
+
+		backendUUID = result.BackendUUID
+		transcript.Turns = append(transcript.Turns, export.Document{
+			Query:       opts.Query,
+			Answer:      result.Text,
+			Mode:        string(opts.Mode),
+			Model:       string(opts.Model),
+			Language:    opts.Language,
+			BackendUUID: result.BackendUUID,
+			Date:        time.Now(),
+			Sources:     result.WebResults,
+		})
+	}
+
+	if outputFile != "" && len(transcript.Turns) > 0 {
+		if saveErr := export.WriteTranscriptFile(outputFile, transcript, format); saveErr != nil {
+			render.RenderError(fmt.Errorf("failed to save transcript: %v", saveErr))
+		} else if len(transcript.Turns) < len(turns) {
+			render.RenderWarning(fmt.Sprintf("Saved %d of %d turns to %s", len(transcript.Turns), len(turns), outputFile))
+		} else {
+			render.RenderSuccess(fmt.Sprintf("Saved transcript to %s", outputFile))
+		}
+	}
+
+	return err
+}
+
+// buildTurnOptions builds and validates the search options of every turn
+// before any is sent. The prompt prefix only goes on the first turn, which
+// starts the thread.
+func buildTurnOptions(script *prompt.Script) ([]models.SearchOptions, error) {
+	filters, err := buildSearchFilters(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	turns := make([]models.SearchOptions, len(script.Turns))
+	for i, t := range script.Turns {
+		query := t.Query
+		if i == 0 {
+			query = withPromptPrefix(query)
+		}
+		opts := buildSearchOptionsWith(query, script.TurnOptions(i))
+		if err := validateSearchOptions(opts); err != nil {
+			return nil, fmt.Errorf("turn %d: %w", i+1, err)
+		}
+		opts.Filters = filters
+		opts.Stream = streamingEnabled()
+		turns[i] = opts
+	}
+	return turns, nil
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&flagModel, "model", "m", "", "AI model for every turn (overrides the script)")
+	runCmd.Flags().StringVar(&flagMode, "mode", "", "Search mode for every turn (overrides the script)")
+	runCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Search sources for every turn (overrides the script)")
+	runCmd.Flags().StringSliceVar(&flagSites, "site", nil, "Restrict sources to these domains (repeatable or comma-separated)")
+	runCmd.Flags().StringSliceVar(&flagExclude, "exclude-site", nil, "Exclude sources from these domains (repeatable or comma-separated)")
+	runCmd.Flags().StringVar(&flagSince, "since", "", "Only use sources published since a relative age (7d, 2w, 3mo, 1y) or date (2024-01-01)")
+	runCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Response language for every turn (overrides the script)")
+	runCmd.Flags().BoolVar(&flagStream, "stream", false, "Enable streaming output")
+	runCmd.Flags().BoolVar(&flagNoStream, "no-stream", false, "Disable streaming output")
+	runCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Don't save to history")
+	runCmd.Flags().StringVarP(&flagOutputFile, "output", "o", "", "Save the transcript to file (format chosen by extension: .md, .html, .json)")
+	runCmd.Flags().StringVar(&flagExportFmt, "export-format", "", "Transcript format (text, markdown, html, json)")
+	runCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Path to cookies.json file")
+	runCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
+	runCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Print the request of every turn (cookies redacted) without sending it")
+	runCmd.RegisterFlagCompletionFunc("model", completeModels)
+	runCmd.RegisterFlagCompletionFunc("mode", completeModes)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/internal/prompt"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestBuildTurnOptions(t *testing.T) {
+	origCfg := cfg
+	origFlagModel, origFlagMode, origFlagSources := flagModel, flagMode, flagSources
+	defer func() {
+		cfg = origCfg
+		flagModel, flagMode, flagSources = origFlagModel, origFlagMode, origFlagSources
+	}()
+
+	cfg = &config.Config{
+		DefaultModel:   models.ModelPplxPro,
+		DefaultMode:    models.ModeDefault,
+		DefaultSources: []models.Source{models.SourceWeb},
+	}
+	script, err := prompt.ParseScript([]byte("---\nmode: pro\n---\nfirst\n+++\n---\nmode: reasoning\nmodel: gpt51\n---\nsecond\n+++\nthird"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flagModel, flagMode, flagSources = "", "", ""
+	turns, err := buildTurnOptions(script)
+	if err != nil {
+		t.Fatalf("buildTurnOptions() error = %v", err)
+	}
+	if len(turns) != 3 {
+		t.Fatalf("len(turns) = %d, want 3", len(turns))
+	}
+	if turns[0].Query != "first" || turns[0].Mode != models.ModePro || turns[0].Model != models.ModelPplxPro {
+		t.Errorf("turn 1 = %+v", turns[0])
+	}
+	if turns[1].Mode != models.ModeReasoning || turns[1].Model != models.ModelGPT51 {
+		t.Errorf("turn 2 = %+v, want the turn's mode and model", turns[1])
+	}
+	if turns[2].Mode != models.ModePro || turns[2].Model != models.ModelPplxPro {
+		t.Errorf("turn 3 = %+v, want the script options", turns[2])
+	}
+
+	flagMode = "deep-research"
+	turns, err = buildTurnOptions(script)
+	if err != nil {
+		t.Fatalf("buildTurnOptions() error = %v", err)
+	}
+	for i, opts := range turns {
+		if opts.Mode != models.ModeDeepResearch {
+			t.Errorf("turn %d Mode = %q, want the flag mode", i+1, opts.Mode)
+		}
+	}
+}
+
+func TestBuildTurnOptionsPromptPrefix(t *testing.T) {
+	origCfg, origFlagMode := cfg, flagMode
+	defer func() { cfg, flagMode = origCfg, origFlagMode }()
+
+	cfg = &config.Config{DefaultModel: models.ModelPplxPro, DefaultMode: models.ModeDefault, PromptPrefix: "Be brief."}
+	flagMode = ""
+	script, err := prompt.ParseScript([]byte("first\n+++\nsecond"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	turns, err := buildTurnOptions(script)
+	if err != nil {
+		t.Fatalf("buildTurnOptions() error = %v", err)
+	}
+	if turns[0].Query != "Be brief.\n\nfirst" {
+		t.Errorf("turn 1 Query = %q, want the prefix", turns[0].Query)
+	}
+	if turns[1].Query != "second" {
+		t.Errorf("turn 2 Query = %q, want no prefix on a follow-up", turns[1].Query)
+	}
+}
+
+func TestBuildTurnOptionsInvalidTurn(t *testing.T) {
+	origCfg, origFlagMode := cfg, flagMode
+	defer func() { cfg, flagMode = origCfg, origFlagMode }()
+
+	cfg = &config.Config{DefaultModel: models.ModelPplxPro, DefaultMode: models.ModeDefault}
+	flagMode = ""
+	script, err := prompt.ParseScript([]byte("q1\n+++\n---\nmode: bogus\n---\nq2"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := buildTurnOptions(script); err == nil || !strings.Contains(err.Error(), "turn 2: invalid mode: bogus") {
+		t.Errorf("buildTurnOptions() error = %v, want turn 2 invalid mode", err)
+	}
+}
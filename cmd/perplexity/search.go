@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/internal/auth"
+	"github.com/diogo/perplexity-go/internal/history"
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// searchResult holds the collected output of a single search.
+type searchResult struct {
+	Text        string
+	WebResults  []models.WebResult
+	BackendUUID string
+	// Cancelled is set when a streaming search was interrupted; Text then
+	// holds the partial answer.
+	Cancelled bool
+}
+
+// cookieFilePath returns the cookie file from the --cookies flag or config.
+func cookieFilePath() string {
+	if flagCookieFile != "" {
+		return flagCookieFile
+	}
+	return cfg.CookieFile
+}
+
+// newClient loads cookies and creates an API client, rendering any errors.
+func newClient() (*client.Client, error) {
+	cookieFile := cookieFilePath()
+
+	// Check if cookies exist
+	if _, err := os.Stat(cookieFile); os.IsNotExist(err) {
+		render.RenderError(fmt.Errorf("cookies file not found: %s", cookieFile))
+		render.RenderInfo("Run 'perplexity import-cookies <file>' to import cookies from browser")
+		return nil, fmt.Errorf("no cookies found")
+	}
+
+	// Load cookies
+	cookies, err := auth.LoadCookiesFromFile(cookieFile)
+	if err != nil {
+		render.RenderError(fmt.Errorf("failed to load cookies: %v", err))
+		return nil, err
+	}
+	cookies = checkCookieExpiry(cookies, time.Now())
+
+	// Create client
+	cli, err := client.NewWithCookies(cookies)
+	if err != nil {
+		render.RenderError(fmt.Errorf("failed to create client: %v", err))
+		return nil, err
+	}
+
+	if flagDebugHTTP != "" {
+		if err := enableHTTPDebug(cli, flagDebugHTTP); err != nil {
+			cli.Close()
+			render.RenderError(err)
+			return nil, err
+		}
+	}
+
+	return cli, nil
+}
+
+// checkCookieExpiry drops expired cookies and warns on stderr about them and
+// about a session token close to expiring.
+func checkCookieExpiry(cookies []*http.Cookie, now time.Time) []*http.Cookie {
+	valid, expired := auth.DropExpired(cookies, now)
+	for _, c := range expired {
+		fmt.Fprintf(os.Stderr, "Warning: cookie %s expired on %s and was ignored\n", c.Name, c.Expires.Format("2006-01-02"))
+	}
+
+	warnWithin := time.Duration(cfg.CookieExpiryWarningDays) * 24 * time.Hour
+	if token := auth.FindSessionToken(valid); token != nil && warnWithin > 0 &&
+		auth.CookieExpiry(token, now, warnWithin) == auth.ExpirySoon {
+		fmt.Fprintf(os.Stderr, "Warning: session token expires on %s (%s); re-import cookies with 'perplexity import-cookies'\n",
+			token.Expires.Format("2006-01-02"), formatUntil(token.Expires.Sub(now)))
+	}
+	return valid
+}
+
+// enableHTTPDebug logs the client's HTTP traffic to path, masking the
+// built-in secrets and the config's debug_redact patterns.
+func enableHTTPDebug(cli *client.Client, path string) error {
+	logger, err := client.OpenDebugLog(path, cfg.DebugRedact)
+	if err != nil {
+		return err
+	}
+	if err := cli.SetDebugLogger(logger); err != nil {
+		logger.Close()
+		return err
+	}
+	return nil
+}
+
+// signalContext returns a context that is cancelled on interrupt or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
+	}()
+
+	return ctx, cancel
+}
+
+// uploadAttachments uploads piped input too large to inline and local
+// attachment files, and returns their URLs.
+func uploadAttachments(cli *client.Client, input queryInput, files []string) ([]string, error) {
+	var urls []string
+	if len(input.Attachment) > 0 {
+		url, err := cli.UploadBytes(input.Attachment, stdinAttachmentName, "text/plain")
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload piped input: %w", err)
+		}
+		urls = append(urls, url)
+		if flagVerbose {
+			render.RenderInfo(fmt.Sprintf("Uploaded piped input (%d KB) as %s", sizeKB(len(input.Attachment)), stdinAttachmentName))
+		}
+	}
+
+	for _, f := range files {
+		url, err := cli.UploadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %s: %w", f, err)
+		}
+		urls = append(urls, url)
+		if flagVerbose {
+			render.RenderInfo(fmt.Sprintf("Uploaded %s", f))
+		}
+	}
+	return urls, nil
+}
+
+// performSearch runs a search and renders it, streaming when opts.Stream is set.
+// A cancelled streaming search returns the partial result; a cancelled
+// non-streaming search returns context.Canceled. Errors are rendered here.
+func performSearch(ctx context.Context, cli *client.Client, opts models.SearchOptions) (*searchResult, error) {
+	if opts.Stream {
+		return performStreamingSearch(ctx, cli, opts)
+	}
+
+	// Non-streaming mode with spinner
+	done := make(chan struct{})
+	go func() {
+		frame := 0
+		for {
+			select {
+			case <-done:
+				render.ClearLine()
+				return
+			case <-time.After(100 * time.Millisecond):
+				render.RenderSpinner(frame)
+				frame++
+			}
+		}
+	}()
+
+	resp, err := cli.Search(ctx, opts)
+	close(done)
+
+	if err != nil {
+		if err == context.Canceled {
+			render.RenderWarning("Search cancelled")
+			return nil, err
+		}
+		render.RenderError(err)
+		return nil, err
+	}
+
+	if err := render.RenderResponse(resp); err != nil {
+		render.RenderError(err)
+		return nil, err
+	}
+
+	return &searchResult{
+		Text:        resp.Text,
+		WebResults:  resp.WebResults,
+		BackendUUID: resp.BackendUUID,
+	}, nil
+}
+
+// performStreamingSearch renders chunks as they arrive.
+func performStreamingSearch(ctx context.Context, cli *client.Client, opts models.SearchOptions) (*searchResult, error) {
+	ch, err := cli.SearchStream(ctx, opts)
+	if err != nil {
+		render.RenderError(err) // Render error from stream initiation
+		return nil, err
+	}
+
+	result := &searchResult{}
+	var fullResponse strings.Builder
+	var renderedFinal bool // Track if we already rendered a FINAL response
+	for chunk := range ch {
+		if chunk.Error != nil { // Handle all chunk errors
+			if chunk.Error == context.Canceled {
+				render.NewLine()
+				render.RenderWarning("Search cancelled")
+				result.Cancelled = true
+				break // Exit loop on cancel
+			}
+			// Report other errors
+			render.RenderError(chunk.Error)
+			return nil, chunk.Error
+		}
+
+		if chunk.BackendUUID != "" {
+			result.BackendUUID = chunk.BackendUUID
+		}
+
+		// For new step-based format, only render FINAL step
+		if chunk.StepType == "FINAL" && chunk.Text != "" {
+			// Render as markdown instead of raw text
+			if err := render.RenderStyledResponse(chunk.Text); err != nil {
+				render.RenderStreamChunk(chunk)
+			}
+			fullResponse.WriteString(chunk.Text)
+			result.WebResults = append(result.WebResults, chunk.WebResults...)
+			renderedFinal = true
+		} else if chunk.StepType == "" {
+			// Legacy format - render as stream
+			render.RenderStreamChunk(chunk)
+			if chunk.Delta != "" {
+				fullResponse.WriteString(chunk.Delta)
+			} else if chunk.Text != "" {
+				fullResponse.WriteString(chunk.Text)
+			}
+		}
+	}
+	render.NewLine()
+
+	// Post-stream rendering only for legacy format (token-by-token streaming)
+	// Skip if we already rendered a FINAL step response
+	if fullResponse.Len() > 0 && !renderedFinal {
+		if err := render.RenderStyledResponse(fullResponse.String()); err != nil {
+			// If final styled rendering fails, the raw stream output is still there.
+			render.RenderError(fmt.Errorf("failed to render final response: %w", err))
+		}
+	}
+
+	// Render web results if any
+	if len(result.WebResults) > 0 {
+		render.RenderWebResults(result.WebResults)
+	}
+
+	result.Text = fullResponse.String()
+	return result, nil
+}
+
+// streamingEnabled resolves streaming from config and the --stream/--no-stream flags.
+func streamingEnabled() bool {
+	streaming := cfg.Streaming
+	if flagStream {
+		streaming = true
+	}
+	if flagNoStream {
+		streaming = false
+	}
+	return streaming
+}
+
+// newHistoryEntry builds the history record of a search. Failed and cancelled
+// searches are recorded too, keeping any partial answer and the error.
+func newHistoryEntry(opts models.SearchOptions, result *searchResult, elapsed time.Duration, searchErr error) models.HistoryEntry {
+	entry := models.HistoryEntry{
+		Version:     models.HistorySchemaVersion,
+		Query:       opts.Query,
+		Mode:        string(opts.Mode),
+		Model:       string(opts.Model),
+		Language:    opts.Language,
+		Sources:     opts.Sources,
+		Attachments: opts.Attachments,
+		Filters:     opts.Filters,
+		DurationMS:  elapsed.Milliseconds(),
+		Status:      models.HistoryStatusOK,
+	}
+
+	if result != nil {
+		entry.Response = result.Text
+		entry.WebResults = result.WebResults
+		entry.BackendUUID = result.BackendUUID
+		if result.Cancelled {
+			entry.Status = models.HistoryStatusCancelled
+		}
+	}
+
+	switch {
+	case errors.Is(searchErr, context.Canceled):
+		entry.Status = models.HistoryStatusCancelled
+	case searchErr != nil:
+		entry.Status = models.HistoryStatusError
+		entry.Error = searchErr.Error()
+	}
+
+	return entry
+}
+
+// saveHistory records a search in the history unless running incognito.
+func saveHistory(opts models.SearchOptions, result *searchResult, elapsed time.Duration, searchErr error) {
+	saveHistoryGroup(opts, result, elapsed, searchErr, "")
+}
+
+// saveHistoryGroup records a search like saveHistory, tagged with a group
+// shared by related entries.
+func saveHistoryGroup(opts models.SearchOptions, result *searchResult, elapsed time.Duration, searchErr error, group string) {
+	if opts.Incognito {
+		return
+	}
+
+	hw, err := history.NewWriter(cfg.HistoryFile)
+	if err != nil {
+		return
+	}
+	hw.SetRetention(historyRetention())
+	entry := newHistoryEntry(opts, result, elapsed, searchErr)
+	entry.Group = group
+	if err := hw.Append(entry); err != nil {
+		render.RenderWarning(fmt.Sprintf("Failed to save history: %v", err))
+	}
+}
+
+// historyRetention returns the retention policy from the config.
+func historyRetention() history.Retention {
+	return history.Retention{
+		MaxEntries: cfg.HistoryMaxEntries,
+		MaxAge:     cfg.HistoryMaxAge,
+		MaxBytes:   int64(cfg.HistoryMaxSizeMB) << 20,
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	opts := models.DefaultSearchOptions("What is Go?")
+	opts.Mode = models.ModePro
+	opts.Attachments = []string{"https://example.com/file.pdf"}
+
+	result := &searchResult{
+		Text:        "Go is a programming language.",
+		WebResults:  []models.WebResult{{URL: "https://go.dev"}},
+		BackendUUID: "uuid-1",
+	}
+
+	tests := []struct {
+		name       string
+		result     *searchResult
+		err        error
+		wantStatus string
+		wantError  string
+		wantText   string
+	}{
+		{"success", result, nil, models.HistoryStatusOK, "", result.Text},
+		{"cancelled stream keeps partial answer", &searchResult{Text: "Go is", Cancelled: true}, nil, models.HistoryStatusCancelled, "", "Go is"},
+		{"cancelled without result", nil, context.Canceled, models.HistoryStatusCancelled, "", ""},
+		{"error", nil, errors.New("API error 500"), models.HistoryStatusError, "API error 500", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newHistoryEntry(opts, tt.result, 1500*time.Millisecond, tt.err)
+
+			if entry.Version != models.HistorySchemaVersion {
+				t.Errorf("Version = %d", entry.Version)
+			}
+			if entry.Status != tt.wantStatus || entry.Error != tt.wantError {
+				t.Errorf("Status/Error = %q/%q, want %q/%q", entry.Status, entry.Error, tt.wantStatus, tt.wantError)
+			}
+			if entry.Response != tt.wantText {
+				t.Errorf("Response = %q, want %q", entry.Response, tt.wantText)
+			}
+			if entry.Query != "What is Go?" || entry.Mode != "pro" || entry.Language != "en-US" {
+				t.Errorf("entry = %+v", entry)
+			}
+			if len(entry.Sources) != 1 || len(entry.Attachments) != 1 {
+				t.Errorf("Sources/Attachments = %v/%v", entry.Sources, entry.Attachments)
+			}
+			if entry.DurationMS != 1500 {
+				t.Errorf("DurationMS = %d, want 1500", entry.DurationMS)
+			}
+		})
+	}
+}
+
+func TestEnableHTTPDebug(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cli, err := client.New(client.DefaultConfig())
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "http.log")
+	if err := enableHTTPDebug(cli, path); err != nil {
+		t.Fatalf("enableHTTPDebug() error = %v", err)
+	}
+	if err := cli.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("debug log not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("debug log mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	cfg.DebugRedact = []string{"[bad"}
+	if err := enableHTTPDebug(cli, path); err == nil {
+		t.Error("expected error for invalid redact pattern")
+	}
+}
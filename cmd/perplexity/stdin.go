@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// stdinAttachmentName is the file name of piped input uploaded as an
+// attachment.
+const stdinAttachmentName = "stdin.txt"
+
+// stdinOptions controls how piped input combines with a query argument.
+type stdinOptions struct {
+	Mode   string
+	Layout string
+	// AttachBytes is the size above which piped context is uploaded
+	// instead of inlined; zero never uploads.
+	AttachBytes int
+}
+
+// queryInput is a query and any piped input to upload with it.
+type queryInput struct {
+	Query      string
+	Attachment []byte
+}
+
+// stdinSettings returns the stdin options from the config, with --stdin
+// overriding the mode.
+func stdinSettings() (stdinOptions, error) {
+	opts := stdinOptions{
+		Mode:        cfg.StdinMode,
+		Layout:      cfg.StdinLayout,
+		AttachBytes: cfg.StdinAttachKB << 10,
+	}
+	if flagStdin != "" {
+		if err := config.ValidateStdinMode(flagStdin); err != nil {
+			return opts, err
+		}
+		opts.Mode = flagStdin
+	}
+	return opts, nil
+}
+
+// combineStdin merges piped input with a query argument. In context mode the
+// input becomes a fenced block laid out around the query, or an attachment
+// when it is larger than the threshold; in query mode it is appended to the
+// query as is.
+func combineStdin(query, piped string, opts stdinOptions) queryInput {
+	switch {
+	case piped == "":
+		return queryInput{Query: query}
+	case query == "":
+		return queryInput{Query: piped}
+	case opts.Mode == config.StdinQuery:
+		return queryInput{Query: query + "\n\n" + piped}
+	case opts.AttachBytes > 0 && len(piped) > opts.AttachBytes:
+		return queryInput{Query: query, Attachment: []byte(piped)}
+	case opts.Layout == config.StdinStdinFirst:
+		return queryInput{Query: fenceBlock(piped) + "\n\n" + query}
+	default:
+		return queryInput{Query: query + "\n\n" + fenceBlock(piped)}
+	}
+}
+
+// fenceBlock wraps text in a Markdown code fence longer than any run of
+// backticks inside it, so the input cannot close the block early.
+func fenceBlock(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + "\n" + text + "\n" + fence
+}
+
+// sizeKB returns n bytes in kilobytes, rounded up.
+func sizeKB(n int) int {
+	return (n + 1023) >> 10
+}
+
+// readPiped reads all of stdin, trimmed.
+func readPiped(stdin io.Reader) (string, error) {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func completeStdinModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{config.StdinContext, config.StdinQuery, config.StdinIgnore}, cobra.ShellCompDirectiveNoFileComp
+}
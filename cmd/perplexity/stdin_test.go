@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+)
+
+func TestCombineStdin(t *testing.T) {
+	inline := stdinOptions{Mode: config.StdinContext, Layout: config.StdinQueryFirst, AttachBytes: 16}
+
+	tests := []struct {
+		name       string
+		query      string
+		piped      string
+		opts       stdinOptions
+		want       string
+		wantAttach bool
+	}{
+		{"no input", "review", "", inline, "review", false},
+		{"no query", "", "diff", inline, "diff", false},
+		{"query first", "review", "diff", inline, "review\n\n```\ndiff\n```", false},
+		{"stdin first", "review", "diff", stdinOptions{Mode: config.StdinContext, Layout: config.StdinStdinFirst}, "```\ndiff\n```\n\nreview", false},
+		{"default layout", "review", "diff", stdinOptions{}, "review\n\n```\ndiff\n```", false},
+		{"query mode", "review", "diff", stdinOptions{Mode: config.StdinQuery, AttachBytes: 1}, "review\n\ndiff", false},
+		{"over threshold", "review", strings.Repeat("x", 17), inline, "review", true},
+		{"at threshold", "review", strings.Repeat("x", 16), inline, "review\n\n```\n" + strings.Repeat("x", 16) + "\n```", false},
+		{"no threshold", "review", strings.Repeat("x", 100), stdinOptions{Mode: config.StdinContext}, "review\n\n```\n" + strings.Repeat("x", 100) + "\n```", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := combineStdin(tt.query, tt.piped, tt.opts)
+			if got.Query != tt.want {
+				t.Errorf("Query = %q, want %q", got.Query, tt.want)
+			}
+			if (got.Attachment != nil) != tt.wantAttach {
+				t.Errorf("Attachment = %q, want attachment %v", got.Attachment, tt.wantAttach)
+			}
+			if tt.wantAttach && string(got.Attachment) != tt.piped {
+				t.Errorf("Attachment = %q, want the piped input", got.Attachment)
+			}
+		})
+	}
+}
+
+func TestFenceBlock(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "```\nplain\n```"},
+		{"has `code`", "```\nhas `code`\n```"},
+		{"```go\nx\n```", "````\n```go\nx\n```\n````"},
+		{"`````", "``````\n`````\n``````"},
+	}
+
+	for _, tt := range tests {
+		if got := fenceBlock(tt.text); got != tt.want {
+			t.Errorf("fenceBlock(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGetQueryFromInputStdinModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		args []string
+		want string
+	}{
+		{config.StdinIgnore, []string{"review"}, "review"},
+		{config.StdinIgnore, nil, "diff"},
+		{config.StdinQuery, []string{"review"}, "review\n\ndiff"},
+		{config.StdinContext, []string{"review", "this"}, "review this\n\n```\ndiff\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := getQueryFromInput(tt.args, strings.NewReader("diff\n"), false, stdinOptions{Mode: tt.mode})
+			if err != nil {
+				t.Fatalf("getQueryFromInput() error = %v", err)
+			}
+			if got.Query != tt.want {
+				t.Errorf("getQueryFromInput() = %q, want %q", got.Query, tt.want)
+			}
+		})
+	}
+}
+
+func TestStdinSettings(t *testing.T) {
+	origCfg, origFlag := cfg, flagStdin
+	defer func() { cfg, flagStdin = origCfg, origFlag }()
+
+	cfg = &config.Config{StdinMode: config.StdinContext, StdinLayout: config.StdinStdinFirst, StdinAttachKB: 2}
+
+	flagStdin = ""
+	got, err := stdinSettings()
+	if err != nil {
+		t.Fatalf("stdinSettings() error = %v", err)
+	}
+	want := stdinOptions{Mode: config.StdinContext, Layout: config.StdinStdinFirst, AttachBytes: 2048}
+	if got != want {
+		t.Errorf("stdinSettings() = %+v, want %+v", got, want)
+	}
+
+	flagStdin = "ignore"
+	if got, _ := stdinSettings(); got.Mode != config.StdinIgnore {
+		t.Errorf("Mode = %q, want the --stdin value", got.Mode)
+	}
+
+	flagStdin = "append"
+	if _, err := stdinSettings(); err == nil || !strings.Contains(err.Error(), "invalid stdin mode: append") {
+		t.Errorf("stdinSettings() error = %v, want invalid stdin mode", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/client"
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	threadFormat   string
+	threadFollowUp string
+)
+
+var threadCmd = &cobra.Command{
+	Use:   "thread",
+	Short: "Work with existing Perplexity threads",
+	Long:  `View and continue threads created in the browser or by this CLI.`,
+}
+
+var threadShowCmd = &cobra.Command{
+	Use:   "show <url|uuid>",
+	Short: "Show all turns of an existing thread",
+	Long: `Fetch an existing thread by its perplexity.ai URL, slug or UUID and
+display every turn with its answer and sources.
+
+Use --follow-up to ask a new question in the same thread; the query flags
+(--model, --mode, --sources, ...) apply to it. --follow-up works with the
+text format only.
+
+Examples:
+  perplexity thread show https://www.perplexity.ai/search/what-is-go-abc123
+  perplexity thread show what-is-go-abc123 --format json
+  perplexity thread show <uuid> --follow-up "And how does it compare to Rust?"
+  perplexity thread show <uuid> --follow-up "Go deeper" --mode reasoning --model gpt51`,
+	Args: cobra.ExactArgs(1),
+	RunE: runThreadShow,
+}
+
+func runThreadShow(cmd *cobra.Command, args []string) error {
+	if threadFormat != "text" && threadFormat != "json" {
+		return fmt.Errorf("invalid format: %s (valid: text, json)", threadFormat)
+	}
+	// The follow-up answer is rendered as text, which would corrupt the JSON
+	if threadFormat == "json" && threadFollowUp != "" {
+		return fmt.Errorf("--follow-up cannot be combined with --format json")
+	}
+
+	id, err := client.ParseThreadID(args[0])
+	if err != nil {
+		return err
+	}
+
+	var followUp models.SearchOptions
+	if threadFollowUp != "" {
+		if followUp, err = buildFollowUpOptions(threadFollowUp); err != nil {
+			render.RenderError(err)
+			return err
+		}
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	thread, err := cli.GetThread(ctx, id)
+	if err != nil {
+		render.RenderError(fmt.Errorf("failed to fetch thread: %v", err))
+		return err
+	}
+
+	if threadFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(thread); err != nil {
+			return fmt.Errorf("failed to encode thread: %v", err)
+		}
+	} else if err := render.RenderThread(thread); err != nil {
+		render.RenderError(err)
+		return err
+	}
+
+	if threadFollowUp == "" {
+		return nil
+	}
+
+	return continueThread(ctx, cli, thread, followUp)
+}
+
+// buildFollowUpOptions builds and validates the search options of a
+// follow-up from the query flags.
+func buildFollowUpOptions(query string) (models.SearchOptions, error) {
+	opts := buildSearchOptions(query)
+	if err := validateSearchOptions(opts); err != nil {
+		return opts, err
+	}
+
+	filters, err := buildSearchFilters(time.Now())
+	if err != nil {
+		return opts, err
+	}
+	opts.Filters = filters
+	opts.Stream = streamingEnabled()
+	return opts, nil
+}
+
+// continueThread sends opts as a follow-up in the given thread.
+func continueThread(ctx context.Context, cli *client.Client, thread *models.Thread, opts models.SearchOptions) error {
+	backendUUID := thread.LastBackendUUID()
+	if backendUUID == "" {
+		return fmt.Errorf("thread has no backend UUID to continue from")
+	}
+	opts.FollowUp = &models.FollowUpContext{BackendUUID: backendUUID}
+
+	render.NewLine()
+	render.RenderInfo(fmt.Sprintf("Follow-up: %s", opts.Query))
+
+	started := time.Now()
+	result, err := performSearch(ctx, cli, opts)
+	saveHistory(opts, result, time.Since(started), err)
+	if err != nil {
+		if err == context.Canceled {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func init() {
+	threadCmd.AddCommand(threadShowCmd)
+
+	threadShowCmd.Flags().StringVar(&threadFormat, "format", "text", "Output format (text, json)")
+	threadShowCmd.Flags().StringVar(&threadFollowUp, "follow-up", "", "Ask a follow-up question in this thread")
+	threadShowCmd.Flags().StringVarP(&flagModel, "model", "m", "", "AI model for the follow-up")
+	threadShowCmd.Flags().StringVar(&flagMode, "mode", "", "Search mode for the follow-up")
+	threadShowCmd.Flags().StringVarP(&flagSources, "sources", "s", "", "Search sources for the follow-up (web,scholar,social)")
+	threadShowCmd.Flags().StringSliceVar(&flagSites, "site", nil, "Restrict sources to these domains (repeatable or comma-separated)")
+	threadShowCmd.Flags().StringSliceVar(&flagExclude, "exclude-site", nil, "Exclude sources from these domains (repeatable or comma-separated)")
+	threadShowCmd.Flags().StringVar(&flagSince, "since", "", "Only use sources published since a relative age (7d, 2w, 3mo, 1y) or date (2024-01-01)")
+	threadShowCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "Response language for the follow-up (e.g., en-US, pt-BR)")
+	threadShowCmd.Flags().BoolVar(&flagStream, "stream", false, "Enable streaming output")
+	threadShowCmd.Flags().BoolVar(&flagNoStream, "no-stream", false, "Disable streaming output")
+	threadShowCmd.Flags().BoolVarP(&flagIncognito, "incognito", "i", false, "Don't save the follow-up to history")
+	threadShowCmd.Flags().StringVarP(&flagCookieFile, "cookies", "c", "", "Path to cookies.json file")
+	threadShowCmd.RegisterFlagCompletionFunc("model", completeModels)
+	threadShowCmd.RegisterFlagCompletionFunc("mode", completeModes)
+}
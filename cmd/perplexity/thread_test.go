@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestThreadCmdStructure(t *testing.T) {
+	found := false
+	for _, cmd := range threadCmd.Commands() {
+		if cmd.Name() == "show" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected subcommand \"show\" not found")
+	}
+
+	for _, name := range []string{"format", "follow-up", "model", "mode", "sources", "language", "stream", "no-stream", "incognito", "cookies"} {
+		if threadShowCmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected flag --%s on thread show", name)
+		}
+	}
+}
+
+func TestThreadShowCmd_InvalidFormat(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	origFormat := threadFormat
+	defer func() { threadFormat = origFormat }()
+	threadFormat = "yaml"
+
+	err := threadShowCmd.RunE(threadShowCmd, []string{"some-slug"})
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
+
+func TestThreadShowCmd_JSONWithFollowUp(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	origFormat, origFollowUp := threadFormat, threadFollowUp
+	defer func() { threadFormat, threadFollowUp = origFormat, origFollowUp }()
+	threadFormat, threadFollowUp = "json", "And Rust?"
+
+	err := threadShowCmd.RunE(threadShowCmd, []string{"some-slug"})
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined with --format json") {
+		t.Errorf("expected an error for --follow-up with JSON, got %v", err)
+	}
+}
+
+func TestThreadShowCmd_NoCookies(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	origFormat := threadFormat
+	defer func() { threadFormat = origFormat }()
+	threadFormat = "text"
+
+	err := threadShowCmd.RunE(threadShowCmd, []string{"https://www.perplexity.ai/search/some-slug"})
+	if err == nil || !strings.Contains(err.Error(), "no cookies found") {
+		t.Errorf("expected missing cookies error, got %v", err)
+	}
+}
+
+func TestBuildFollowUpOptions(t *testing.T) {
+	origCfg := cfg
+	origFlagModel, origFlagMode, origFlagNoStream := flagModel, flagMode, flagNoStream
+	defer func() {
+		cfg = origCfg
+		flagModel, flagMode, flagNoStream = origFlagModel, origFlagMode, origFlagNoStream
+	}()
+
+	cfg = &config.Config{DefaultModel: models.ModelPplxPro, DefaultMode: models.ModeDefault, Streaming: true}
+	flagModel, flagMode, flagNoStream = "gpt51", "reasoning", true
+
+	opts, err := buildFollowUpOptions("And Rust?")
+	if err != nil {
+		t.Fatalf("buildFollowUpOptions() error = %v", err)
+	}
+	if opts.Model != models.ModelGPT51 || opts.Mode != models.ModeReasoning || opts.Stream {
+		t.Errorf("opts = %+v, want the flag model, mode and --no-stream", opts)
+	}
+
+	flagMode = "bogus"
+	if _, err := buildFollowUpOptions("And Rust?"); err == nil || !strings.Contains(err.Error(), "invalid mode") {
+		t.Errorf("buildFollowUpOptions() error = %v, want invalid mode", err)
+	}
+}
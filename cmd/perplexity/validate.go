@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diogo/perplexity-go/internal/config"
+	"github.com/diogo/perplexity-go/internal/export"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// validateSearchOptions checks the model, mode, sources and language of a
+// search, suggesting the closest valid value for typos. Empty values are
+// left to the client defaults.
+func validateSearchOptions(opts models.SearchOptions) error {
+	if opts.Model != "" {
+		if err := validateModel(string(opts.Model)); err != nil {
+			return err
+		}
+	}
+	if opts.Mode != "" {
+		if err := validateMode(string(opts.Mode)); err != nil {
+			return err
+		}
+	}
+	for _, s := range opts.Sources {
+		if !models.IsValidSource(s) {
+			candidates := make([]string, len(models.AvailableSources))
+			for i, src := range models.AvailableSources {
+				candidates[i] = string(src)
+			}
+			return invalidValueError("source", string(s), candidates, "")
+		}
+	}
+	if opts.Language != "" && !config.IsValidLanguage(opts.Language) {
+		return fmt.Errorf("invalid language: %s (expected xx-XX, e.g. en-US or pt-BR)", opts.Language)
+	}
+	if flagExportFmt != "" {
+		if _, err := export.ParseFormat(flagExportFmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateModel checks that name is a model id or alias in the catalog.
+func validateModel(name string) error {
+	if _, ok := models.ResolveModel(name); ok {
+		return nil
+	}
+
+	var candidates []string
+	for _, m := range models.ActiveCatalog().Models {
+		candidates = append(candidates, string(m.ID))
+		candidates = append(candidates, m.Aliases...)
+	}
+	return invalidValueError("model", name, candidates, "run 'perplexity models' to list models")
+}
+
+// validateMode checks that name is a mode in the catalog.
+func validateMode(name string) error {
+	if models.IsValidMode(models.Mode(name)) {
+		return nil
+	}
+
+	var candidates []string
+	for _, m := range models.ActiveCatalog().ModeIDs() {
+		candidates = append(candidates, string(m))
+	}
+	return invalidValueError("mode", name, candidates, "valid: "+strings.Join(candidates, ", "))
+}
+
+// invalidValueError reports an invalid flag value with a did-you-mean
+// suggestion when one is close enough.
+func invalidValueError(kind, value string, candidates []string, hint string) error {
+	msg := fmt.Sprintf("invalid %s: %s", kind, value)
+	if match := closestMatch(value, candidates); match != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", match)
+	}
+	if hint != "" {
+		msg += "; " + hint
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// closestMatch returns the candidate nearest to value by edit distance,
+// ignoring case, or "" when none is close enough to be a likely typo.
+func closestMatch(value string, candidates []string) string {
+	value = strings.ToLower(value)
+	best := ""
+	bestDist := len(value)/3 + 2
+
+	for _, c := range candidates {
+		lower := strings.ToLower(c)
+		if lower == value {
+			return c
+		}
+		dist := editDistance(value, lower)
+		// A typed prefix such as "claude45" is a likely match too
+		if len(value) >= 3 && strings.HasPrefix(lower, value) && dist > 1 {
+			dist = 1
+		}
+		if dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// searchFlagWarnings describes flags that are overridden by other flags or
+// have no effect.
+func searchFlagWarnings(opts models.SearchOptions, args []string) []string {
+	var warnings []string
+
+	if flagStream && flagNoStream {
+		warnings = append(warnings, "--no-stream overrides --stream")
+	}
+	if flagInputFile != "" && len(args) > 0 {
+		warnings = append(warnings, "--file takes precedence; query arguments are ignored")
+	}
+	if flagExportFmt != "" && flagOutputFile == "" {
+		warnings = append(warnings, "--export-format has no effect without --output")
+	}
+	if flagModel != "" || flagMode != "" {
+		if w := modeCompatibilityWarning(opts.Model, opts.Mode); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	return warnings
+}
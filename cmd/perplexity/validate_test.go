@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestValidateSearchOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    models.SearchOptions
+		wantErr string
+	}{
+		{"empty uses defaults", models.SearchOptions{}, ""},
+		{"valid", models.SearchOptions{Model: "gpt51", Mode: "pro", Sources: []models.Source{"web", "scholar"}, Language: "pt-BR"}, ""},
+		{"model alias", models.SearchOptions{Model: "claude"}, ""},
+		{"model typo", models.SearchOptions{Model: "claude45sonet"}, `invalid model: claude45sonet (did you mean "claude45sonnet"?)`},
+		{"stale model", models.SearchOptions{Model: "gpt5"}, `did you mean "gpt51"?`},
+		{"mode typo", models.SearchOptions{Mode: "reasonig"}, `invalid mode: reasonig (did you mean "reasoning"?); valid: fast`},
+		{"source typo", models.SearchOptions{Sources: []models.Source{"web", "scholr"}}, `invalid source: scholr (did you mean "scholar"?)`},
+		{"no suggestion", models.SearchOptions{Model: "zzzzzzzzzz"}, "invalid model: zzzzzzzzzz; run 'perplexity models'"},
+		{"language", models.SearchOptions{Language: "pt_BR"}, "invalid language: pt_BR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSearchOptions(tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSearchOptions() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSearchOptions() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSearchOptions_ExportFormat(t *testing.T) {
+	flagExportFmt = "pdf"
+	defer func() { flagExportFmt = "" }()
+
+	if err := validateSearchOptions(models.SearchOptions{}); err == nil {
+		t.Error("expected error for invalid export format")
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"fast", "pro", "reasoning", "deep-research", "default"}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"FAST", "fast"},
+		{"prp", "pro"},
+		{"deep", "deep-research"},
+		{"deepresearch", "deep-research"},
+		{"xyz", ""},
+	}
+
+	for _, tt := range tests {
+		if got := closestMatch(tt.value, candidates); got != tt.want {
+			t.Errorf("closestMatch(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFlagWarnings(t *testing.T) {
+	defer func() {
+		flagStream, flagNoStream = false, false
+		flagInputFile, flagExportFmt, flagOutputFile = "", "", ""
+		flagModel, flagMode = "", ""
+	}()
+
+	flagStream, flagNoStream = true, true
+	flagInputFile = "q.md"
+	flagExportFmt = "html"
+	flagModel, flagMode = "claude45sonnet", "fast"
+
+	opts := models.SearchOptions{Model: models.ModelClaude45Sonnet, Mode: models.ModeFast}
+	warnings := strings.Join(searchFlagWarnings(opts, []string{"ignored"}), "\n")
+
+	for _, want := range []string{"--no-stream overrides --stream", "--file takes precedence", "--export-format has no effect", "fast mode always uses turbo"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings should contain %q\n%s", want, warnings)
+		}
+	}
+
+	flagStream, flagNoStream = false, false
+	flagInputFile, flagExportFmt = "", ""
+	flagModel, flagMode = "", ""
+	if w := searchFlagWarnings(opts, nil); len(w) != 0 {
+		t.Errorf("config defaults should not warn, got %v", w)
+	}
+}
+
+func TestExplainSearch(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cli, err := previewClient()
+	if err != nil {
+		t.Fatalf("previewClient() error = %v", err)
+	}
+	defer cli.Close()
+
+	opts := models.DefaultSearchOptions("What is Go?")
+	opts.Mode = models.ModeFast
+	opts.Model = models.ModelClaude45Sonnet
+	opts.Incognito = true
+
+	preview, err := cli.PreviewSearch(opts)
+	if err != nil {
+		t.Fatalf("PreviewSearch() error = %v", err)
+	}
+	lines, err := explainSearch(opts, preview)
+	if err != nil {
+		t.Fatalf("explainSearch() error = %v", err)
+	}
+
+	output := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"fast -> API mode concise",
+		"claude45sonnet (Claude Sonnet 4.5) -> model_preference turbo (replaced by fast mode)",
+		"not saved (incognito)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("explanation should contain %q\n%s", want, output)
+		}
+	}
+
+	var buf strings.Builder
+	if err := writeRequestPreview(&buf, preview); err != nil {
+		t.Fatalf("writeRequestPreview() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "POST https://www.perplexity.ai/") || !strings.Contains(buf.String(), `"query_str": "What is Go?"`) {
+		t.Errorf("preview = %s", buf.String())
+	}
+}
@@ -0,0 +1,567 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// Browser identifies a browser whose cookie store can be imported.
+type Browser string
+
+const (
+	BrowserFirefox  Browser = "firefox"
+	BrowserChromium Browser = "chromium"
+	BrowserChrome   Browser = "chrome"
+)
+
+// SupportedBrowsers lists the browsers cookies can be imported from.
+var SupportedBrowsers = []Browser{BrowserFirefox, BrowserChromium, BrowserChrome}
+
+// chromiumDirs maps Chromium-based browsers to their config directory and
+// keyring application name.
+var chromiumDirs = map[Browser]struct{ dir, keyring string }{
+	BrowserChromium: {"chromium", "chromium"},
+	BrowserChrome:   {"google-chrome", "chrome"},
+}
+
+// Chromium's Linux cookie encryption: AES-128-CBC with a PBKDF2-SHA1 key.
+var (
+	chromiumSalt       = []byte("saltysalt")
+	chromiumIV         = []byte("                ")
+	chromiumV10Pass    = "peanuts"
+	chromiumIterations = 1
+	chromiumKeyLength  = 16
+)
+
+// keyringPassword returns the Chromium "Safe Storage" password from the
+// desktop keyring, used for v11 cookies. It is a variable for testing.
+var keyringPassword = func(application string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "application", application).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ParseBrowser parses a browser name.
+func ParseBrowser(name string) (Browser, error) {
+	b := Browser(strings.ToLower(strings.TrimSpace(name)))
+	for _, s := range SupportedBrowsers {
+		if b == s {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported browser: %s (valid: firefox, chromium, chrome)", name)
+}
+
+// LoadCookiesFromBrowser reads the perplexity.ai cookies of a local browser
+// profile. An empty profile selects the browser's default profile.
+func LoadCookiesFromBrowser(browser Browser, profile string) ([]*http.Cookie, error) {
+	switch browser {
+	case BrowserFirefox:
+		path, err := FindFirefoxCookieDB(profile)
+		if err != nil {
+			return nil, err
+		}
+		return LoadCookiesFromFirefoxDB(path)
+	case BrowserChromium, BrowserChrome:
+		if runtime.GOOS != "linux" {
+			return nil, fmt.Errorf("importing from %s is only supported on Linux", browser)
+		}
+		path, err := FindChromiumCookieDB(browser, profile)
+		if err != nil {
+			return nil, err
+		}
+		password, _ := keyringPassword(chromiumDirs[browser].keyring)
+		return LoadCookiesFromChromiumDB(path, password)
+	}
+	return nil, fmt.Errorf("unsupported browser: %s", browser)
+}
+
+// firefoxProfilesDir returns the directory holding Firefox profiles and
+// profiles.ini.
+func firefoxProfilesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Application Support", "Firefox"), nil
+	}
+	return filepath.Join(home, ".mozilla", "firefox"), nil
+}
+
+// FindFirefoxCookieDB returns the cookies.sqlite of the named Firefox
+// profile, or of the default profile when name is empty. Names match the
+// profile name in profiles.ini or its directory.
+func FindFirefoxCookieDB(name string) (string, error) {
+	root, err := firefoxProfilesDir()
+	if err != nil {
+		return "", err
+	}
+
+	profiles, err := readFirefoxProfiles(filepath.Join(root, "profiles.ini"))
+	if err != nil {
+		return "", fmt.Errorf("no Firefox profiles found in %s: %w", root, err)
+	}
+
+	chosen := pickFirefoxProfile(profiles, name)
+	if chosen == nil {
+		if name == "" {
+			return "", fmt.Errorf("no Firefox profiles found in %s", root)
+		}
+		return "", fmt.Errorf("Firefox profile not found: %s", name)
+	}
+
+	path := chosen.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	db := filepath.Join(path, "cookies.sqlite")
+	if _, err := os.Stat(db); err != nil {
+		return "", fmt.Errorf("no cookie database in Firefox profile %s", chosen.Name)
+	}
+	return db, nil
+}
+
+// pickFirefoxProfile selects the profile matching name, or the default
+// profile when name is empty.
+func pickFirefoxProfile(profiles []firefoxProfile, name string) *firefoxProfile {
+	if name != "" {
+		for i, p := range profiles {
+			if strings.EqualFold(p.Name, name) || filepath.Base(p.Path) == name {
+				return &profiles[i]
+			}
+		}
+		return nil
+	}
+
+	for i, p := range profiles {
+		if p.InstallDefault {
+			return &profiles[i]
+		}
+	}
+	for i, p := range profiles {
+		if p.Default {
+			return &profiles[i]
+		}
+	}
+	if len(profiles) > 0 {
+		return &profiles[0]
+	}
+	return nil
+}
+
+// firefoxProfile is a profile entry from profiles.ini.
+type firefoxProfile struct {
+	Name           string
+	Path           string
+	Default        bool
+	InstallDefault bool
+}
+
+// readFirefoxProfiles parses profiles.ini. Profiles chosen as default by an
+// [Install...] section are marked InstallDefault.
+func readFirefoxProfiles(path string) ([]firefoxProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var profiles []firefoxProfile
+	var installDefaults []string
+	var section string
+	var current *firefoxProfile
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			current = nil
+			if strings.HasPrefix(section, "Profile") {
+				profiles = append(profiles, firefoxProfile{})
+				current = &profiles[len(profiles)-1]
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case current != nil && key == "Name":
+			current.Name = value
+		case current != nil && key == "Path":
+			current.Path = filepath.FromSlash(value)
+		case current != nil && key == "Default" && value == "1":
+			current.Default = true
+		case strings.HasPrefix(section, "Install") && key == "Default":
+			installDefaults = append(installDefaults, filepath.FromSlash(value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range profiles {
+		for _, d := range installDefaults {
+			if profiles[i].Path == d {
+				profiles[i].InstallDefault = true
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// LoadCookiesFromFirefoxDB reads the perplexity.ai cookies of a Firefox
+// cookies.sqlite database.
+func LoadCookiesFromFirefoxDB(path string) ([]*http.Cookie, error) {
+	db, cleanup, err := openCookieDBCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	rows, err := db.Query(`SELECT name, value, host, path, expiry, isSecure, isHttpOnly, sameSite
+		FROM moz_cookies WHERE host LIKE '%perplexity.ai'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Firefox cookies: %w", err)
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var c http.Cookie
+		var expiry int64
+		var sameSite int
+		if err := rows.Scan(&c.Name, &c.Value, &c.Domain, &c.Path, &expiry, &c.Secure, &c.HttpOnly, &sameSite); err != nil {
+			return nil, fmt.Errorf("failed to read Firefox cookies: %w", err)
+		}
+		if !isPerplexityDomain(c.Domain) {
+			continue
+		}
+
+		// Recent Firefox versions store the expiry in milliseconds
+		if expiry > 1e11 {
+			expiry /= 1000
+		}
+		if expiry > 0 {
+			c.Expires = time.Unix(expiry, 0)
+		}
+		c.SameSite = firefoxSameSite(sameSite)
+		cookies = append(cookies, &c)
+	}
+
+	return cookies, rows.Err()
+}
+
+// firefoxSameSite converts Firefox's sameSite column.
+func firefoxSameSite(v int) http.SameSite {
+	switch v {
+	case 0:
+		return http.SameSiteNoneMode
+	case 1:
+		return http.SameSiteLaxMode
+	case 2:
+		return http.SameSiteStrictMode
+	}
+	return http.SameSiteDefaultMode
+}
+
+// FindChromiumCookieDB returns the Cookies database of the named profile of
+// a Chromium-based browser, or of the "Default" profile when name is empty.
+// Names match the profile directory or its display name.
+func FindChromiumCookieDB(browser Browser, name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(configDir, chromiumDirs[browser].dir)
+
+	dir := "Default"
+	if name != "" {
+		dir = chromiumProfileDir(root, name)
+	}
+
+	for _, p := range []string{
+		filepath.Join(root, dir, "Network", "Cookies"),
+		filepath.Join(root, dir, "Cookies"),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("no %s cookie database found in %s", browser, root)
+	}
+	return "", fmt.Errorf("%s profile not found: %s", browser, name)
+}
+
+// chromiumProfileDir maps a profile display name to its directory using
+// Local State, returning name itself when no display name matches.
+func chromiumProfileDir(root, name string) string {
+	data, err := os.ReadFile(filepath.Join(root, "Local State"))
+	if err != nil {
+		return name
+	}
+
+	var state struct {
+		Profile struct {
+			InfoCache map[string]struct {
+				Name string `json:"name"`
+			} `json:"info_cache"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return name
+	}
+
+	for dir, info := range state.Profile.InfoCache {
+		if strings.EqualFold(info.Name, name) {
+			return dir
+		}
+	}
+	return name
+}
+
+// LoadCookiesFromChromiumDB reads and decrypts the perplexity.ai cookies of
+// a Chromium Cookies database. keyringPass decrypts v11 values; v10 values
+// use Chromium's built-in password.
+func LoadCookiesFromChromiumDB(path, keyringPass string) ([]*http.Cookie, error) {
+	db, cleanup, err := openCookieDBCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// Since database version 24 values are prefixed with a hash of the domain
+	var version int
+	_ = db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&version)
+
+	dec, err := newChromiumDecrypter(keyringPass)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT host_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly, samesite
+		FROM cookies WHERE host_key LIKE '%perplexity.ai'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Chromium cookies: %w", err)
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var c http.Cookie
+		var encrypted []byte
+		var expires int64
+		var sameSite int
+		if err := rows.Scan(&c.Domain, &c.Name, &c.Value, &encrypted, &c.Path, &expires, &c.Secure, &c.HttpOnly, &sameSite); err != nil {
+			return nil, fmt.Errorf("failed to read Chromium cookies: %w", err)
+		}
+		if !isPerplexityDomain(c.Domain) {
+			continue
+		}
+
+		if c.Value == "" && len(encrypted) > 0 {
+			value, err := dec.decrypt(encrypted)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt cookie %s: %w", c.Name, err)
+			}
+			if version >= 24 && len(value) >= sha256.Size {
+				value = value[sha256.Size:]
+			}
+			c.Value = string(value)
+		}
+
+		if expires > 0 {
+			c.Expires = chromiumTime(expires)
+		}
+		c.SameSite = chromiumSameSite(sameSite)
+		cookies = append(cookies, &c)
+	}
+
+	return cookies, rows.Err()
+}
+
+// chromiumDecrypter decrypts Linux Chromium cookie values.
+type chromiumDecrypter struct {
+	v10, v11, empty []byte
+}
+
+func newChromiumDecrypter(keyringPass string) (*chromiumDecrypter, error) {
+	var d chromiumDecrypter
+	var err error
+	if d.v10, err = chromiumKey(chromiumV10Pass); err != nil {
+		return nil, err
+	}
+	if d.empty, err = chromiumKey(""); err != nil {
+		return nil, err
+	}
+	if keyringPass != "" {
+		if d.v11, err = chromiumKey(keyringPass); err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// chromiumKey derives the AES key for a Chromium Safe Storage password.
+func chromiumKey(password string) ([]byte, error) {
+	return pbkdf2.Key(sha1.New, password, chromiumSalt, chromiumIterations, chromiumKeyLength)
+}
+
+// decrypt decrypts a v10 or v11 value, trying the empty-password key that
+// Chromium falls back to when no keyring is available.
+func (d *chromiumDecrypter) decrypt(value []byte) ([]byte, error) {
+	if len(value) < 3 {
+		return nil, errors.New("value too short")
+	}
+
+	var keys [][]byte
+	switch string(value[:3]) {
+	case "v10":
+		keys = [][]byte{d.v10, d.empty}
+	case "v11":
+		if d.v11 == nil {
+			keys = [][]byte{d.empty}
+		} else {
+			keys = [][]byte{d.v11, d.empty}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption version %q", value[:3])
+	}
+
+	for _, key := range keys {
+		if plain, err := aesCBCDecrypt(key, value[3:]); err == nil {
+			return plain, nil
+		}
+	}
+	if string(value[:3]) == "v11" && d.v11 == nil {
+		return nil, errors.New("v11 cookie needs the keyring password (is secret-tool installed and the keyring unlocked?)")
+	}
+	return nil, errors.New("wrong key")
+}
+
+// aesCBCDecrypt decrypts AES-128-CBC data with Chromium's fixed IV and
+// checks its PKCS#7 padding.
+func aesCBCDecrypt(key, data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid ciphertext length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, chromiumIV).CryptBlocks(plain, data)
+
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || pad > len(plain) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range plain[len(plain)-pad:] {
+		if int(b) != pad {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+// chromiumEpoch is the origin of Chromium timestamps.
+var chromiumEpoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// chromiumTime converts microseconds since 1601 to a time.
+func chromiumTime(us int64) time.Time {
+	return time.Unix(us/1e6+chromiumEpoch.Unix(), (us%1e6)*1e3)
+}
+
+// chromiumSameSite converts Chromium's samesite column.
+func chromiumSameSite(v int) http.SameSite {
+	switch v {
+	case 0:
+		return http.SameSiteNoneMode
+	case 1:
+		return http.SameSiteLaxMode
+	case 2:
+		return http.SameSiteStrictMode
+	}
+	return http.SameSiteDefaultMode
+}
+
+// isPerplexityDomain reports whether a cookie domain belongs to perplexity.ai.
+func isPerplexityDomain(domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	return domain == "perplexity.ai" || strings.HasSuffix(domain, ".perplexity.ai")
+}
+
+// openCookieDBCopy opens a copy of a browser cookie database, since the
+// browser keeps the original locked while running. The WAL file is copied
+// too so recent changes are included.
+func openCookieDBCopy(path string) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "perplexity-cookies-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := copyFile(path, dst); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to copy cookie database: %w", err)
+	}
+	if _, err := os.Stat(path + "-wal"); err == nil {
+		if err := copyFile(path+"-wal", dst+"-wal"); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to copy cookie database: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dst)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() { db.Close(); cleanup() }, nil
+}
+
+// copyFile copies src to dst with owner-only permissions.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
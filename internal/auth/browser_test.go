@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// createFirefoxDB writes a minimal Firefox cookies.sqlite fixture.
+func createFirefoxDB(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT,
+			expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER, sameSite INTEGER)`,
+		`INSERT INTO moz_cookies (name, value, host, path, expiry, isSecure, isHttpOnly, sameSite) VALUES
+			('next-auth.csrf-token', 'csrf|hash', 'www.perplexity.ai', '/', 1900000000, 1, 1, 1),
+			('session', 'abc', '.perplexity.ai', '/', 1900000000000, 1, 0, 0),
+			('tracker', 'x', '.notperplexity.ai', '/', 1900000000, 0, 0, 0),
+			('other', 'y', '.example.com', '/', 1900000000, 0, 0, 0)`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// encryptChromium encrypts a value the way Linux Chromium does.
+func encryptChromium(t *testing.T, prefix, password string, plain []byte) []byte {
+	t.Helper()
+	key, err := chromiumKey(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append([]byte{}, plain...)
+	for i := 0; i < pad; i++ {
+		data = append(data, byte(pad))
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, chromiumIV).CryptBlocks(out, data)
+	return append([]byte(prefix), out...)
+}
+
+// createChromiumDB writes a minimal Chromium Cookies fixture at the given
+// meta version.
+func createChromiumDB(t *testing.T, path string, version int, rows [][]any) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE TABLE meta (key TEXT PRIMARY KEY, value TEXT)`,
+		`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB, path TEXT,
+			expires_utc INTEGER, is_secure INTEGER, is_httponly INTEGER, samesite INTEGER)`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO meta VALUES ('version', ?)`, version); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if _, err := db.Exec(`INSERT INTO cookies VALUES (?, ?, ?, ?, '/', ?, 1, 1, 1)`, r...); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadCookiesFromFirefoxDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.sqlite")
+	createFirefoxDB(t, path)
+
+	cookies, err := LoadCookiesFromFirefoxDB(path)
+	if err != nil {
+		t.Fatalf("LoadCookiesFromFirefoxDB failed: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("Expected 2 perplexity cookies, got %d", len(cookies))
+	}
+	if !HasCSRFToken(cookies) {
+		t.Error("Expected CSRF token to be imported")
+	}
+
+	want := time.Unix(1900000000, 0)
+	for _, c := range cookies {
+		if !c.Expires.Equal(want) {
+			t.Errorf("Cookie %s expires %v, want %v", c.Name, c.Expires, want)
+		}
+	}
+}
+
+func TestLoadCookiesFromChromiumDB(t *testing.T) {
+	expires := int64(13_400_000_000_000_000)
+	hostHash := sha256.Sum256([]byte(".perplexity.ai"))
+
+	tests := []struct {
+		name    string
+		version int
+		keyring string
+		rows    [][]any
+	}{
+		{
+			name:    "v10",
+			version: 18,
+			rows: [][]any{
+				{".perplexity.ai", "next-auth.csrf-token", "", encryptChromium(t, "v10", "peanuts", []byte("csrf|hash")), expires},
+				{".perplexity.ai", "plain", "value", []byte{}, expires},
+				{".example.com", "other", "", encryptChromium(t, "v10", "peanuts", []byte("nope")), expires},
+			},
+		},
+		{
+			name:    "v11 with keyring",
+			version: 18,
+			keyring: "s3cret",
+			rows: [][]any{
+				{"www.perplexity.ai", "next-auth.csrf-token", "", encryptChromium(t, "v11", "s3cret", []byte("csrf|hash")), expires},
+				{".perplexity.ai", "plain", "", encryptChromium(t, "v11", "", []byte("value")), expires},
+			},
+		},
+		{
+			name:    "domain hash prefix",
+			version: 24,
+			rows: [][]any{
+				{".perplexity.ai", "next-auth.csrf-token", "", encryptChromium(t, "v10", "peanuts", append(hostHash[:], "csrf|hash"...)), expires},
+				{".perplexity.ai", "plain", "", encryptChromium(t, "v10", "peanuts", append(hostHash[:], "value"...)), expires},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Cookies")
+			createChromiumDB(t, path, tt.version, tt.rows)
+
+			cookies, err := LoadCookiesFromChromiumDB(path, tt.keyring)
+			if err != nil {
+				t.Fatalf("LoadCookiesFromChromiumDB failed: %v", err)
+			}
+			if len(cookies) != 2 {
+				t.Fatalf("Expected 2 perplexity cookies, got %d", len(cookies))
+			}
+
+			values := map[string]string{}
+			for _, c := range cookies {
+				values[c.Name] = c.Value
+			}
+			if values["next-auth.csrf-token"] != "csrf|hash" {
+				t.Errorf("csrf value = %q, want %q", values["next-auth.csrf-token"], "csrf|hash")
+			}
+			if values["plain"] != "value" {
+				t.Errorf("plain value = %q, want %q", values["plain"], "value")
+			}
+			if got := cookies[0].Expires.Year(); got != 2025 {
+				t.Errorf("Expected expiry in 2025, got %d", got)
+			}
+		})
+	}
+}
+
+func TestLoadCookiesFromChromiumDB_MissingKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Cookies")
+	createChromiumDB(t, path, 18, [][]any{
+		{".perplexity.ai", "session", "", encryptChromium(t, "v11", "s3cret", []byte("value")), int64(0)},
+	})
+
+	if _, err := LoadCookiesFromChromiumDB(path, ""); err == nil {
+		t.Error("Expected error decrypting v11 cookie without keyring password")
+	}
+}
+
+func TestFindFirefoxCookieDB(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("profile layout test assumes Linux paths")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := filepath.Join(home, ".mozilla", "firefox")
+	for _, dir := range []string{"abc.default", "xyz.default-release", "work.work"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0700); err != nil {
+			t.Fatal(err)
+		}
+		createFirefoxDB(t, filepath.Join(root, dir, "cookies.sqlite"))
+	}
+
+	ini := `[Profile2]
+Name=work
+IsRelative=1
+Path=work.work
+
+[Profile1]
+Name=default
+IsRelative=1
+Path=abc.default
+Default=1
+
+[Profile0]
+Name=default-release
+IsRelative=1
+Path=xyz.default-release
+
+[Install4F96D1932A9F858E]
+Default=xyz.default-release
+Locked=1
+`
+	if err := os.WriteFile(filepath.Join(root, "profiles.ini"), []byte(ini), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{"", "xyz.default-release"},
+		{"work", "work.work"},
+		{"abc.default", "abc.default"},
+	}
+	for _, tt := range tests {
+		path, err := FindFirefoxCookieDB(tt.profile)
+		if err != nil {
+			t.Fatalf("FindFirefoxCookieDB(%q) failed: %v", tt.profile, err)
+		}
+		if want := filepath.Join(root, tt.want, "cookies.sqlite"); path != want {
+			t.Errorf("FindFirefoxCookieDB(%q) = %s, want %s", tt.profile, path, want)
+		}
+	}
+
+	if _, err := FindFirefoxCookieDB("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestFindChromiumCookieDB(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	if runtime.GOOS != "linux" {
+		t.Skip("profile layout test assumes Linux paths")
+	}
+
+	root := filepath.Join(configDir, "chromium")
+	if err := os.MkdirAll(filepath.Join(root, "Default"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "Profile 1", "Network"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{
+		filepath.Join(root, "Default", "Cookies"),
+		filepath.Join(root, "Profile 1", "Network", "Cookies"),
+	} {
+		if err := os.WriteFile(p, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := `{"profile":{"info_cache":{"Default":{"name":"Person 1"},"Profile 1":{"name":"Work"}}}}`
+	if err := os.WriteFile(filepath.Join(root, "Local State"), []byte(state), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{"", filepath.Join(root, "Default", "Cookies")},
+		{"Work", filepath.Join(root, "Profile 1", "Network", "Cookies")},
+		{"Profile 1", filepath.Join(root, "Profile 1", "Network", "Cookies")},
+	}
+	for _, tt := range tests {
+		path, err := FindChromiumCookieDB(BrowserChromium, tt.profile)
+		if err != nil {
+			t.Fatalf("FindChromiumCookieDB(%q) failed: %v", tt.profile, err)
+		}
+		if path != tt.want {
+			t.Errorf("FindChromiumCookieDB(%q) = %s, want %s", tt.profile, path, tt.want)
+		}
+	}
+
+	if _, err := FindChromiumCookieDB(BrowserChrome, ""); err == nil {
+		t.Error("Expected error when Chrome is not installed")
+	}
+}
+
+func TestParseBrowser(t *testing.T) {
+	if b, err := ParseBrowser("Firefox"); err != nil || b != BrowserFirefox {
+		t.Errorf("ParseBrowser(Firefox) = %q, %v", b, err)
+	}
+	if _, err := ParseBrowser("safari"); err == nil {
+		t.Error("Expected error for unsupported browser")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/internal/secret"
 )
 
 // JSONCookie represents a cookie in JSON format (browser export).
@@ -25,9 +26,10 @@ type JSONCookie struct {
 	SameSite string  `json:"sameSite,omitempty"`
 }
 
-// LoadCookiesFromFile loads cookies from a JSON file.
+// LoadCookiesFromFile loads cookies from a JSON file, decrypting it first if
+// it is encrypted.
 func LoadCookiesFromFile(path string) ([]*http.Cookie, error) {
-	data, err := os.ReadFile(path)
+	data, _, err := secret.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read cookie file: %w", err)
 	}
@@ -150,7 +152,8 @@ func GetDefaultCookiePath() (string, error) {
 	return filepath.Join(home, ".perplexity-cli", "cookies.json"), nil
 }
 
-// SaveCookiesToFile saves cookies to a JSON file.
+// SaveCookiesToFile saves cookies to a JSON file. The file is encrypted when
+// encryption at rest is on or the existing file is already encrypted.
 func SaveCookiesToFile(cookies []*http.Cookie, path string) error {
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -191,7 +194,7 @@ func SaveCookiesToFile(cookies []*http.Cookie, path string) error {
 	}
 
 	// Write with restricted permissions
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := secret.WriteFile(path, data, secret.ShouldEncrypt(path)); err != nil {
 		return fmt.Errorf("failed to write cookie file: %w", err)
 	}
 
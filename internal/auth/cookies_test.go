@@ -1,11 +1,13 @@
 package auth
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
 
 	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/internal/secret"
 )
 
 func TestLoadCookiesFromFile(t *testing.T) {
@@ -236,6 +238,41 @@ func TestSaveCookiesToFile(t *testing.T) {
 	}
 }
 
+func TestSaveCookiesToFile_Encrypted(t *testing.T) {
+	secret.Configure(true, func(bool) ([]byte, error) { return []byte("passphrase"), nil })
+	defer secret.Configure(false, nil)
+
+	cookieFile := filepath.Join(t.TempDir(), "cookies.json")
+	cookies := []*http.Cookie{{Name: SessionTokenCookie, Value: "live-token", Domain: ".perplexity.ai", Path: "/"}}
+	if err := SaveCookiesToFile(cookies, cookieFile); err != nil {
+		t.Fatalf("SaveCookiesToFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(cookieFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !secret.IsEncrypted(data) || bytes.Contains(data, []byte("live-token")) {
+		t.Error("cookie file is not encrypted")
+	}
+
+	// An encrypted file stays encrypted after encryption is turned off
+	secret.Configure(false, func(bool) ([]byte, error) { return []byte("passphrase"), nil })
+	loaded, err := LoadCookiesFromFile(cookieFile)
+	if err != nil {
+		t.Fatalf("LoadCookiesFromFile() error = %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Value != "live-token" {
+		t.Errorf("loaded = %v, want the session token", loaded)
+	}
+	if err := SaveCookiesToFile(loaded, cookieFile); err != nil {
+		t.Fatal(err)
+	}
+	if !secret.IsEncryptedFile(cookieFile) {
+		t.Error("saving over an encrypted file wrote plaintext")
+	}
+}
+
 func TestGetDefaultCookiePath(t *testing.T) {
 	path, err := GetDefaultCookiePath()
 	if err != nil {
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// SessionTokenCookie is the name of the cookie holding the login session.
+const SessionTokenCookie = "__Secure-next-auth.session-token"
+
+// ExpiryState classifies a cookie by its expiration date.
+type ExpiryState string
+
+const (
+	// ExpirySession means the cookie has no expiration date.
+	ExpirySession ExpiryState = "session"
+	// ExpiryValid means the cookie is not close to expiring.
+	ExpiryValid ExpiryState = "valid"
+	// ExpirySoon means the cookie expires within the warning window.
+	ExpirySoon ExpiryState = "expiring"
+	// ExpiryExpired means the cookie has already expired.
+	ExpiryExpired ExpiryState = "expired"
+)
+
+// CookieExpiry classifies c at now, treating cookies that expire within
+// warnWithin as expiring soon.
+func CookieExpiry(c *http.Cookie, now time.Time, warnWithin time.Duration) ExpiryState {
+	switch {
+	case c.Expires.IsZero():
+		return ExpirySession
+	case !c.Expires.After(now):
+		return ExpiryExpired
+	case c.Expires.Sub(now) <= warnWithin:
+		return ExpirySoon
+	default:
+		return ExpiryValid
+	}
+}
+
+// DropExpired splits cookies into those still valid at now and those that
+// have expired.
+func DropExpired(cookies []*http.Cookie, now time.Time) (valid, expired []*http.Cookie) {
+	valid = make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		if CookieExpiry(c, now, 0) == ExpiryExpired {
+			expired = append(expired, c)
+			continue
+		}
+		valid = append(valid, c)
+	}
+	return valid, expired
+}
+
+// FindSessionToken returns the session-token cookie, or nil if absent.
+func FindSessionToken(cookies []*http.Cookie) *http.Cookie {
+	for _, c := range cookies {
+		if c.Name == SessionTokenCookie && c.Value != "" {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestCookieExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    ExpiryState
+	}{
+		{"session", time.Time{}, ExpirySession},
+		{"expired", now.Add(-time.Minute), ExpiryExpired},
+		{"expires now", now, ExpiryExpired},
+		{"within window", now.Add(3 * 24 * time.Hour), ExpirySoon},
+		{"outside window", now.Add(30 * 24 * time.Hour), ExpiryValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &http.Cookie{Name: "c", Value: "v", Expires: tt.expires}
+			if got := CookieExpiry(c, now, week); got != tt.want {
+				t.Errorf("CookieExpiry() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropExpired(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{
+		{Name: "session", Value: "a"},
+		{Name: "old", Value: "b", Expires: now.Add(-time.Hour)},
+		{Name: "fresh", Value: "c", Expires: now.Add(time.Hour)},
+	}
+
+	valid, expired := DropExpired(cookies, now)
+	if len(valid) != 2 || valid[0].Name != "session" || valid[1].Name != "fresh" {
+		t.Errorf("valid = %v, want session and fresh", valid)
+	}
+	if len(expired) != 1 || expired[0].Name != "old" {
+		t.Errorf("expired = %v, want old", expired)
+	}
+}
+
+func TestFindSessionToken(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "next-auth.csrf-token", Value: "csrf"},
+		{Name: SessionTokenCookie, Value: "token"},
+	}
+
+	if c := FindSessionToken(cookies); c == nil || c.Value != "token" {
+		t.Errorf("FindSessionToken() = %v, want token cookie", c)
+	}
+	if c := FindSessionToken(cookies[:1]); c != nil {
+		t.Errorf("FindSessionToken() = %v, want nil", c)
+	}
+}
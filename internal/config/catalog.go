@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// catalogFileName is the user model catalog override in the config directory.
+const catalogFileName = "models.json"
+
+// LoadCatalog returns the built-in model catalog merged with the override
+// file at path. A missing file yields the built-in catalog.
+func LoadCatalog(path string) (*models.Catalog, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return models.DefaultCatalog(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog: %w", err)
+	}
+
+	override, err := models.ParseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model catalog %s: %w", path, err)
+	}
+
+	catalog := models.DefaultCatalog().Merge(override)
+	if err := catalog.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid model catalog %s: %w", path, err)
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/viper"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, catalogFileName)
+
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+	if catalog != models.DefaultCatalog() {
+		t.Error("missing override file should return the built-in catalog")
+	}
+
+	override := `{"models":[{"id":"newmodel","name":"New Model","aliases":["nm"],"modes":["pro"]}]}`
+	if err := os.WriteFile(path, []byte(override), 0600); err != nil {
+		t.Fatalf("Failed to write catalog: %v", err)
+	}
+	catalog, err = LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+	if _, ok := catalog.Model("nm"); !ok {
+		t.Error("override model should be in the catalog")
+	}
+	if _, ok := catalog.Model("gpt51"); !ok {
+		t.Error("built-in models should be kept")
+	}
+
+	for _, bad := range []string{`not json`, `{"models":[{"id":"x","modes":["nope"]}]}`} {
+		if err := os.WriteFile(path, []byte(bad), 0600); err != nil {
+			t.Fatalf("Failed to write catalog: %v", err)
+		}
+		if _, err := LoadCatalog(path); err == nil {
+			t.Errorf("expected error for catalog %s", bad)
+		}
+	}
+}
+
+func TestManager_Load_CatalogOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.json")
+	defer models.UseCatalog(models.DefaultCatalog())
+
+	if err := os.WriteFile(configFile, []byte(`{"default_model":"nm"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	override := `{"models":[{"id":"newmodel","name":"New Model","aliases":["nm"],"modes":["pro"]}]}`
+	if err := os.WriteFile(filepath.Join(tmpDir, catalogFileName), []byte(override), 0600); err != nil {
+		t.Fatalf("Failed to write catalog: %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("json")
+	v.AddConfigPath(tmpDir)
+	mgr := &Manager{v: v, cfgDir: tmpDir, cfgFile: configFile}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultModel != "newmodel" {
+		t.Errorf("DefaultModel = %q, want newmodel (alias resolved)", cfg.DefaultModel)
+	}
+	if !models.IsValidModel("newmodel") {
+		t.Error("Load() should activate the catalog override")
+	}
+}
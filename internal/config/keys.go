@@ -0,0 +1,251 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// Origins of configuration values, from lowest to highest precedence.
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginProject = "project"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// Keys returns the configuration keys in alphabetical order.
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsValidKey reports whether key is a configuration key.
+func IsValidKey(key string) bool {
+	_, ok := configField(reflect.ValueOf(&Config{}).Elem(), key)
+	return ok
+}
+
+// configField returns the Config field tagged with key.
+func configField(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Get returns the value of a configuration key as a string, bool, int or
+// []string.
+func (c *Config) Get(key string) (any, error) {
+	field, ok := configField(reflect.ValueOf(c).Elem(), key)
+	if !ok {
+		return nil, unknownKeyError(key)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return field.Bool(), nil
+	case reflect.Int:
+		return int(field.Int()), nil
+	case reflect.Slice:
+		list := make([]string, field.Len())
+		for i := range list {
+			list[i] = field.Index(i).String()
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unsupported type for %s", key)
+}
+
+// FormatValue formats a configuration value for display.
+func FormatValue(value any) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// ParseValue converts a string into the type of a configuration key. Booleans
+// accept the values understood by ParseBoolean and lists are comma-separated.
+func ParseValue(key, raw string) (any, error) {
+	field, ok := configField(reflect.ValueOf(&Config{}).Elem(), key)
+	if !ok {
+		return nil, unknownKeyError(key)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if key == "default_model" {
+			if model, ok := models.ResolveModel(raw); ok {
+				return string(model), nil
+			}
+		}
+		return raw, nil
+	case reflect.Bool:
+		// A value parsed differently with both defaults is not a boolean
+		if ParseBoolean(raw, true) != ParseBoolean(raw, false) {
+			return nil, fmt.Errorf("invalid %s: %q (expected true or false)", key, raw)
+		}
+		return ParseBoolean(raw, false), nil
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q (expected a number)", key, raw)
+		}
+		return n, nil
+	case reflect.Slice:
+		list := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		if key == "default_sources" {
+			for _, s := range list {
+				if !models.IsValidSource(models.Source(s)) {
+					return nil, fmt.Errorf("invalid source: %s", s)
+				}
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unsupported type for %s", key)
+}
+
+// Origin reports where the loaded value of key came from: the environment,
+// the project config, the user config file or the built-in default.
+func (m *Manager) Origin(key string) string {
+	if _, ok := os.LookupEnv(envVarName(key)); ok {
+		return OriginEnv
+	}
+	if m.project != nil && m.project.InConfig(key) {
+		return OriginProject
+	}
+	if m.v.InConfig(key) {
+		return OriginFile
+	}
+	return OriginDefault
+}
+
+// SetValue parses value, validates it together with the rest of the config
+// file and writes it to the config file. Environment overrides are neither
+// validated nor written.
+func (m *Manager) SetValue(key, value string) error {
+	parsed, err := ParseValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	values, err := m.readFileValues()
+	if err != nil {
+		return err
+	}
+	values[key] = parsed
+
+	if err := m.validateFileValues(values); err != nil {
+		return err
+	}
+	return m.writeFileValues(values)
+}
+
+// UnsetValue removes key from the config file so its default applies again.
+// It reports whether the key was set.
+func (m *Manager) UnsetValue(key string) (bool, error) {
+	if !IsValidKey(key) {
+		return false, unknownKeyError(key)
+	}
+
+	values, err := m.readFileValues()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := values[key]; !ok {
+		return false, nil
+	}
+	delete(values, key)
+
+	return true, m.writeFileValues(values)
+}
+
+// readFileValues reads the raw values of the config file.
+func (m *Manager) readFileValues() (map[string]any, error) {
+	values := map[string]any{}
+
+	data, err := os.ReadFile(m.cfgFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return values, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return values, nil
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return values, nil
+}
+
+// writeFileValues replaces the config file with values, stamped with the
+// current schema version.
+func (m *Manager) writeFileValues(values map[string]any) error {
+	values[schemaVersionKey] = CurrentSchemaVersion
+
+	if err := os.MkdirAll(m.cfgDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(m.cfgFile, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// validateFileValues validates config file values on top of the defaults.
+func (m *Manager) validateFileValues(values map[string]any) error {
+	scratch := &Manager{v: viper.New(), cfgDir: m.cfgDir, cfgFile: m.cfgFile}
+	scratch.setDefaults()
+	if err := scratch.v.MergeConfigMap(values); err != nil {
+		return err
+	}
+
+	cfg := scratch.decode()
+	if model, ok := models.ResolveModel(string(cfg.DefaultModel)); ok {
+		cfg.DefaultModel = model
+	}
+	return scratch.validate(cfg)
+}
+
+// envVarName returns the environment variable overriding key.
+func envVarName(key string) string {
+	return "PERPLEXITY_" + strings.ToUpper(key)
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key: %s (valid: %s)", key, strings.Join(Keys(), ", "))
+}
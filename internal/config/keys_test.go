@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+	"github.com/spf13/viper"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("json")
+	v.AddConfigPath(dir)
+	m := &Manager{v: v, cfgDir: dir, cfgFile: filepath.Join(dir, "config.json")}
+	m.setDefaults()
+	return m
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	for _, key := range []string{"default_model", "streaming", "history_max_entries", "debug_redact", "cookie_expiry_warning_days"} {
+		if !IsValidKey(key) {
+			t.Errorf("IsValidKey(%q) = false", key)
+		}
+	}
+	if IsValidKey("nope") {
+		t.Error("IsValidKey(nope) = true")
+	}
+	if len(keys) != reflect.TypeOf(Config{}).NumField() {
+		t.Errorf("Keys() returned %d keys, want one per Config field", len(keys))
+	}
+}
+
+func TestConfigGet(t *testing.T) {
+	cfg := &Config{
+		DefaultModel:      models.ModelGPT51,
+		DefaultSources:    []models.Source{models.SourceWeb, models.SourceScholar},
+		Streaming:         true,
+		HistoryMaxEntries: 5,
+	}
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"default_model", "gpt51"},
+		{"default_sources", []string{"web", "scholar"}},
+		{"streaming", true},
+		{"history_max_entries", 5},
+	}
+	for _, tt := range tests {
+		got, err := cfg.Get(tt.key)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", tt.key, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Get(%q) = %#v, want %#v", tt.key, got, tt.want)
+		}
+	}
+
+	if _, err := cfg.Get("nope"); err == nil {
+		t.Error("Get(nope) expected error")
+	}
+	if got := FormatValue([]string{"web", "scholar"}); got != "web,scholar" {
+		t.Errorf("FormatValue() = %q", got)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{"streaming", "off", false, false},
+		{"streaming", "YES", true, false},
+		{"streaming", "maybe", nil, true},
+		{"history_max_entries", "20", 20, false},
+		{"history_max_entries", "many", nil, true},
+		{"default_sources", "web, scholar", []string{"web", "scholar"}, false},
+		{"default_sources", "web,foo", nil, true},
+		{"default_model", "claude", "claude45sonnet", false},
+		{"default_language", "pt-BR", "pt-BR", false},
+		{"nope", "1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseValue(tt.key, tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseValue(%q, %q) error = %v, wantErr %v", tt.key, tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseValue(%q, %q) = %#v, want %#v", tt.key, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestManager_SetUnsetValue(t *testing.T) {
+	m := newTestManager(t)
+
+	if err := m.SetValue("default_mode", "pro"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if err := m.SetValue("streaming", "no"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if err := m.SetValue("default_language", "portuguese"); err == nil {
+		t.Error("SetValue() expected validation error")
+	}
+	if err := m.SetValue("default_mode", "turbo"); err == nil {
+		t.Error("SetValue() expected error for invalid mode")
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultMode != models.ModePro || cfg.Streaming {
+		t.Errorf("Load() = mode %q, streaming %v; want pro, false", cfg.DefaultMode, cfg.Streaming)
+	}
+	if got := m.Origin("default_mode"); got != OriginFile {
+		t.Errorf("Origin(default_mode) = %q, want file", got)
+	}
+	if got := m.Origin("default_language"); got != OriginDefault {
+		t.Errorf("Origin(default_language) = %q, want default", got)
+	}
+	t.Setenv("PERPLEXITY_DEFAULT_LANGUAGE", "de-DE")
+	if got := m.Origin("default_language"); got != OriginEnv {
+		t.Errorf("Origin(default_language) = %q, want env", got)
+	}
+
+	removed, err := m.UnsetValue("default_mode")
+	if err != nil || !removed {
+		t.Fatalf("UnsetValue() = %v, %v", removed, err)
+	}
+	removed, err = m.UnsetValue("default_mode")
+	if err != nil || removed {
+		t.Errorf("UnsetValue() on unset key = %v, %v", removed, err)
+	}
+	if _, err := m.UnsetValue("nope"); err == nil {
+		t.Error("UnsetValue() expected error for unknown key")
+	}
+
+	data, err := os.ReadFile(m.cfgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "default_mode") || !strings.Contains(string(data), "streaming") {
+		t.Errorf("unexpected config file:\n%s", data)
+	}
+}
@@ -4,9 +4,13 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/diogo/perplexity-go/pkg/models"
 	"github.com/spf13/viper"
@@ -24,33 +28,90 @@ type Config struct {
 	DefaultMode     models.Mode     `mapstructure:"default_mode"`
 	DefaultLanguage string          `mapstructure:"default_language"`
 	DefaultSources  []models.Source `mapstructure:"default_sources"`
+	PromptPrefix    string          `mapstructure:"prompt_prefix"`
 	Streaming       bool            `mapstructure:"streaming"`
 	Incognito       bool            `mapstructure:"incognito"`
 	CookieFile      string          `mapstructure:"cookie_file"`
 	HistoryFile     string          `mapstructure:"history_file"`
+	PromptsDir      string          `mapstructure:"prompts_dir"`
+
+	// History retention; zero values keep everything.
+	HistoryMaxEntries int    `mapstructure:"history_max_entries"`
+	HistoryMaxAge     string `mapstructure:"history_max_age"`
+	HistoryMaxSizeMB  int    `mapstructure:"history_max_size_mb"`
+
+	// DebugRedact lists extra cookie, header and field name patterns masked
+	// in HTTP debug logs, on top of the built-in ones.
+	DebugRedact []string `mapstructure:"debug_redact"`
+
+	// CookieExpiryWarningDays is how many days before the session token
+	// expires to start warning; zero disables the warning.
+	CookieExpiryWarningDays int `mapstructure:"cookie_expiry_warning_days"`
+
+	// EncryptAtRest encrypts the cookie file and history with a passphrase;
+	// PassphraseFile optionally holds the passphrase.
+	EncryptAtRest  bool   `mapstructure:"encrypt_at_rest"`
+	PassphraseFile string `mapstructure:"passphrase_file"`
+
+	// Piped input given with a query argument: StdinMode is context, query
+	// or ignore, StdinLayout puts the fenced input after (query-first) or
+	// before (stdin-first) the query, and input larger than StdinAttachKB
+	// is uploaded as an attachment instead; zero never uploads.
+	StdinMode     string `mapstructure:"stdin_mode"`
+	StdinLayout   string `mapstructure:"stdin_layout"`
+	StdinAttachKB int    `mapstructure:"stdin_attach_kb"`
 }
 
+// Values of stdin_mode and stdin_layout.
+const (
+	StdinContext = "context"
+	StdinQuery   = "query"
+	StdinIgnore  = "ignore"
+
+	StdinQueryFirst = "query-first"
+	StdinStdinFirst = "stdin-first"
+)
+
 // Manager handles configuration loading and saving.
 type Manager struct {
 	v       *viper.Viper
 	cfgDir  string
 	cfgFile string
+	profile string
+
+	// Project-local config layered on top of the user config; workDir
+	// overrides the directory the lookup starts from.
+	workDir     string
+	project     *viper.Viper
+	projectFile string
+	loaded      *Config
+
+	warnings []string
 }
 
-// NewManager creates a new configuration manager.
+// NewManager creates a new configuration manager for the default profile.
 func NewManager() (*Manager, error) {
-	home, err := os.UserHomeDir()
+	return NewManagerForProfile(DefaultProfile)
+}
+
+// NewManagerForProfile creates a configuration manager for a named profile.
+func NewManagerForProfile(profile string) (*Manager, error) {
+	store, err := NewProfileStore()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
+	}
+	if profile == "" {
+		profile = DefaultProfile
 	}
 
-	cfgDir := filepath.Join(home, configDirName)
+	cfgDir := store.Dir(profile)
 	cfgFile := filepath.Join(cfgDir, configFileName+"."+configFileType)
 
 	m := &Manager{
 		v:       viper.New(),
 		cfgDir:  cfgDir,
 		cfgFile: cfgFile,
+		profile: profile,
 	}
 
 	// Set defaults
@@ -60,7 +121,6 @@ func NewManager() (*Manager, error) {
 	m.v.SetConfigName(configFileName)
 	m.v.SetConfigType(configFileType)
 	m.v.AddConfigPath(cfgDir)
-	m.v.AddConfigPath(".")
 
 	// Environment variable support
 	m.v.SetEnvPrefix("PERPLEXITY")
@@ -76,10 +136,22 @@ func (m *Manager) setDefaults() {
 	m.v.SetDefault("default_mode", string(models.ModeDefault))
 	m.v.SetDefault("default_language", "en-US")
 	m.v.SetDefault("default_sources", []string{string(models.SourceWeb)})
+	m.v.SetDefault("prompt_prefix", "")
 	m.v.SetDefault("streaming", true)
 	m.v.SetDefault("incognito", false)
 	m.v.SetDefault("cookie_file", filepath.Join(m.cfgDir, "cookies.json"))
 	m.v.SetDefault("history_file", filepath.Join(m.cfgDir, "history.jsonl"))
+	m.v.SetDefault("prompts_dir", filepath.Join(m.cfgDir, "prompts"))
+	m.v.SetDefault("history_max_entries", 0)
+	m.v.SetDefault("history_max_age", "")
+	m.v.SetDefault("history_max_size_mb", 0)
+	m.v.SetDefault("debug_redact", []string{})
+	m.v.SetDefault("cookie_expiry_warning_days", 7)
+	m.v.SetDefault("stdin_mode", StdinContext)
+	m.v.SetDefault("stdin_layout", StdinQueryFirst)
+	m.v.SetDefault("stdin_attach_kb", 32)
+	m.v.SetDefault("encrypt_at_rest", false)
+	m.v.SetDefault("passphrase_file", "")
 }
 
 // Load reads configuration from file and environment.
@@ -89,6 +161,19 @@ func (m *Manager) Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	// Load the model catalog before migrating and validating models
+	catalog, err := LoadCatalog(m.GetCatalogFile())
+	if err != nil {
+		return nil, err
+	}
+	models.UseCatalog(catalog)
+
+	// Upgrade config files written by older versions
+	m.warnings = nil
+	if err := m.migrateConfigFile(); err != nil {
+		return nil, err
+	}
+
 	// Try to read config file (ignore if not exists)
 	if err := m.v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -97,16 +182,53 @@ func (m *Manager) Load() (*Config, error) {
 		}
 	}
 
+	// Layer the project config found up the directory tree
+	if err := m.loadProjectConfig(); err != nil {
+		return nil, err
+	}
+
+	cfg := m.decode()
+
+	// Accept model aliases (e.g. "claude") in the config file
+	if model, ok := models.ResolveModel(string(cfg.DefaultModel)); ok {
+		cfg.DefaultModel = model
+	}
+
+	// Replace invalid values with defaults
+	m.sanitize(cfg)
+
+	loaded := *cfg
+	loaded.DefaultSources = slices.Clone(cfg.DefaultSources)
+	loaded.DebugRedact = slices.Clone(cfg.DebugRedact)
+	m.loaded = &loaded
+
+	return cfg, nil
+}
+
+// decode builds a Config from the viper values.
+func (m *Manager) decode() *Config {
 	cfg := &Config{}
 
 	// Manual parsing to handle type conversions
 	cfg.DefaultModel = models.Model(m.v.GetString("default_model"))
 	cfg.DefaultMode = models.Mode(m.v.GetString("default_mode"))
 	cfg.DefaultLanguage = m.v.GetString("default_language")
+	cfg.PromptPrefix = m.v.GetString("prompt_prefix")
 	cfg.Streaming = m.v.GetBool("streaming")
 	cfg.Incognito = m.v.GetBool("incognito")
 	cfg.CookieFile = m.v.GetString("cookie_file")
 	cfg.HistoryFile = m.v.GetString("history_file")
+	cfg.PromptsDir = m.v.GetString("prompts_dir")
+	cfg.HistoryMaxEntries = m.v.GetInt("history_max_entries")
+	cfg.HistoryMaxAge = m.v.GetString("history_max_age")
+	cfg.HistoryMaxSizeMB = m.v.GetInt("history_max_size_mb")
+	cfg.DebugRedact = m.v.GetStringSlice("debug_redact")
+	cfg.CookieExpiryWarningDays = m.v.GetInt("cookie_expiry_warning_days")
+	cfg.StdinMode = m.v.GetString("stdin_mode")
+	cfg.StdinLayout = m.v.GetString("stdin_layout")
+	cfg.StdinAttachKB = m.v.GetInt("stdin_attach_kb")
+	cfg.EncryptAtRest = m.v.GetBool("encrypt_at_rest")
+	cfg.PassphraseFile = m.v.GetString("passphrase_file")
 
 	// Parse sources
 	sourcesRaw := m.v.GetStringSlice("default_sources")
@@ -119,12 +241,7 @@ func (m *Manager) Load() (*Config, error) {
 	}
 	cfg.DefaultSources = parseSources(sourcesRaw)
 
-	// Validate configuration
-	if err := m.validate(cfg); err != nil {
-		return nil, err
-	}
-
-	return cfg, nil
+	return cfg
 }
 
 // Save writes configuration to file.
@@ -137,45 +254,195 @@ func (m *Manager) Save(cfg *Config) error {
 	m.v.Set("default_model", string(cfg.DefaultModel))
 	m.v.Set("default_mode", string(cfg.DefaultMode))
 	m.v.Set("default_language", cfg.DefaultLanguage)
+	m.v.Set("prompt_prefix", cfg.PromptPrefix)
 	m.v.Set("streaming", cfg.Streaming)
 	m.v.Set("incognito", cfg.Incognito)
 	m.v.Set("cookie_file", cfg.CookieFile)
 	m.v.Set("history_file", cfg.HistoryFile)
+	m.v.Set("prompts_dir", cfg.PromptsDir)
+	m.v.Set("history_max_entries", cfg.HistoryMaxEntries)
+	m.v.Set("history_max_age", cfg.HistoryMaxAge)
+	m.v.Set("history_max_size_mb", cfg.HistoryMaxSizeMB)
+	m.v.Set("debug_redact", cfg.DebugRedact)
+	m.v.Set("cookie_expiry_warning_days", cfg.CookieExpiryWarningDays)
+	m.v.Set("stdin_mode", cfg.StdinMode)
+	m.v.Set("stdin_layout", cfg.StdinLayout)
+	m.v.Set("stdin_attach_kb", cfg.StdinAttachKB)
+	m.v.Set("encrypt_at_rest", cfg.EncryptAtRest)
+	m.v.Set("passphrase_file", cfg.PassphraseFile)
 
 	sources := make([]string, len(cfg.DefaultSources))
 	for i, s := range cfg.DefaultSources {
 		sources[i] = string(s)
 	}
 	m.v.Set("default_sources", sources)
+	m.v.Set(schemaVersionKey, CurrentSchemaVersion)
+
+	if err := m.keepProjectValuesOut(cfg); err != nil {
+		return err
+	}
 
 	return m.v.WriteConfigAs(m.cfgFile)
 }
 
-// validate checks configuration values.
-func (m *Manager) validate(cfg *Config) error {
-	// Validate model
-	if cfg.DefaultModel != "" && !models.IsValidModel(cfg.DefaultModel) {
-		return fmt.Errorf("invalid model: %s", cfg.DefaultModel)
-	}
+// configCheck validates the value of one configuration key.
+type configCheck struct {
+	key   string
+	check func(cfg *Config) error
+}
+
+// configChecks lists the validation rules in the order they are reported.
+var configChecks = []configCheck{
+	{"default_model", func(cfg *Config) error {
+		if cfg.DefaultModel != "" && !models.IsValidModel(cfg.DefaultModel) {
+			return fmt.Errorf("invalid model: %s", cfg.DefaultModel)
+		}
+		return nil
+	}},
+	{"default_mode", func(cfg *Config) error {
+		if cfg.DefaultMode != "" && !models.IsValidMode(cfg.DefaultMode) {
+			return fmt.Errorf("invalid mode: %s", cfg.DefaultMode)
+		}
+		return nil
+	}},
+	// Language format (xx-XX)
+	{"default_language", func(cfg *Config) error {
+		if cfg.DefaultLanguage != "" && !IsValidLanguage(cfg.DefaultLanguage) {
+			return fmt.Errorf("invalid language format: %s (expected xx-XX)", cfg.DefaultLanguage)
+		}
+		return nil
+	}},
+	{"default_sources", func(cfg *Config) error {
+		for _, s := range cfg.DefaultSources {
+			if !models.IsValidSource(s) {
+				return fmt.Errorf("invalid source: %s", s)
+			}
+		}
+		return nil
+	}},
+	// History retention
+	{"history_max_entries", func(cfg *Config) error {
+		if cfg.HistoryMaxEntries < 0 {
+			return fmt.Errorf("invalid history_max_entries: %d", cfg.HistoryMaxEntries)
+		}
+		return nil
+	}},
+	{"history_max_size_mb", func(cfg *Config) error {
+		if cfg.HistoryMaxSizeMB < 0 {
+			return fmt.Errorf("invalid history_max_size_mb: %d", cfg.HistoryMaxSizeMB)
+		}
+		return nil
+	}},
+	{"history_max_age", func(cfg *Config) error {
+		if _, err := models.ParseSince(cfg.HistoryMaxAge, time.Now()); err != nil {
+			return fmt.Errorf("invalid history_max_age: %w", err)
+		}
+		return nil
+	}},
+	// Debug redaction patterns
+	{"debug_redact", func(cfg *Config) error {
+		for _, p := range cfg.DebugRedact {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid debug_redact pattern: %s", p)
+			}
+		}
+		return nil
+	}},
+	{"cookie_expiry_warning_days", func(cfg *Config) error {
+		if cfg.CookieExpiryWarningDays < 0 {
+			return fmt.Errorf("invalid cookie_expiry_warning_days: %d", cfg.CookieExpiryWarningDays)
+		}
+		return nil
+	}},
+	// Piped input handling
+	{"stdin_mode", func(cfg *Config) error {
+		return ValidateStdinMode(cfg.StdinMode)
+	}},
+	{"stdin_layout", func(cfg *Config) error {
+		switch cfg.StdinLayout {
+		case "", StdinQueryFirst, StdinStdinFirst:
+			return nil
+		}
+		return fmt.Errorf("invalid stdin_layout: %s (valid: %s, %s)", cfg.StdinLayout, StdinQueryFirst, StdinStdinFirst)
+	}},
+	{"stdin_attach_kb", func(cfg *Config) error {
+		if cfg.StdinAttachKB < 0 {
+			return fmt.Errorf("invalid stdin_attach_kb: %d", cfg.StdinAttachKB)
+		}
+		return nil
+	}},
+}
 
-	// Validate mode
-	if cfg.DefaultMode != "" && !models.IsValidMode(cfg.DefaultMode) {
-		return fmt.Errorf("invalid mode: %s", cfg.DefaultMode)
+// ValidateStdinMode checks a stdin_mode or --stdin value.
+func ValidateStdinMode(mode string) error {
+	switch mode {
+	case "", StdinContext, StdinQuery, StdinIgnore:
+		return nil
 	}
+	return fmt.Errorf("invalid stdin mode: %s (valid: %s, %s, %s)", mode, StdinContext, StdinQuery, StdinIgnore)
+}
 
-	// Validate language format (xx-XX)
-	if cfg.DefaultLanguage != "" && !isValidLanguage(cfg.DefaultLanguage) {
-		return fmt.Errorf("invalid language format: %s (expected xx-XX)", cfg.DefaultLanguage)
+// validate checks configuration values.
+func (m *Manager) validate(cfg *Config) error {
+	for _, c := range configChecks {
+		if err := c.check(cfg); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Validate sources
-	for _, s := range cfg.DefaultSources {
-		if !models.IsValidSource(s) {
-			return fmt.Errorf("invalid source: %s", s)
+// sanitize replaces invalid values with their defaults and records a warning
+// for each, so a stale value does not stop the CLI from starting.
+func (m *Manager) sanitize(cfg *Config) {
+	var defaults *Config
+	for _, c := range configChecks {
+		err := c.check(cfg)
+		if err == nil {
+			continue
+		}
+		if defaults == nil {
+			defaults = m.defaultConfig()
 		}
+
+		field, _ := configField(reflect.ValueOf(cfg).Elem(), c.key)
+		value, _ := configField(reflect.ValueOf(defaults).Elem(), c.key)
+		field.Set(value)
+
+		def, _ := defaults.Get(c.key)
+		m.warnf("%v (from %s); using default %s=%s", err, m.Origin(c.key), c.key, FormatValue(def))
 	}
+}
 
-	return nil
+// defaultConfig returns the built-in default configuration.
+func (m *Manager) defaultConfig() *Config {
+	defaults := &Manager{v: viper.New(), cfgDir: m.cfgDir}
+	defaults.setDefaults()
+	return defaults.decode()
+}
+
+// Defaults returns the built-in default configuration, for commands that
+// keep running when the config file fails to load.
+func (m *Manager) Defaults() *Config {
+	return m.defaultConfig()
+}
+
+// Warnings returns the problems found by Load that did not stop it, such as
+// migrated settings and invalid values replaced by defaults.
+func (m *Manager) Warnings() []string {
+	return m.warnings
+}
+
+func (m *Manager) warnf(format string, args ...any) {
+	m.warnings = append(m.warnings, fmt.Sprintf(format, args...))
+}
+
+// Profile returns the name of the profile being managed.
+func (m *Manager) Profile() string {
+	if m.profile == "" {
+		return DefaultProfile
+	}
+	return m.profile
 }
 
 // GetConfigDir returns the configuration directory path.
@@ -188,6 +455,11 @@ func (m *Manager) GetConfigFile() string {
 	return m.cfgFile
 }
 
+// GetCatalogFile returns the model catalog override file path.
+func (m *Manager) GetCatalogFile() string {
+	return filepath.Join(m.cfgDir, catalogFileName)
+}
+
 // parseSources converts string slice to Source slice.
 func parseSources(raw []string) []models.Source {
 	sources := make([]models.Source, 0, len(raw))
@@ -209,10 +481,10 @@ func parseSources(raw []string) []models.Source {
 	return sources
 }
 
-// isValidLanguage checks if the language format is valid (xx-XX).
+// IsValidLanguage checks if the language format is valid (xx-XX).
 var languageRegex = regexp.MustCompile(`^[a-z]{2}-[A-Z]{2}$`)
 
-func isValidLanguage(lang string) bool {
+func IsValidLanguage(lang string) bool {
 	return languageRegex.MatchString(lang)
 }
 
@@ -68,9 +68,9 @@ func TestIsValidLanguage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isValidLanguage(tt.lang)
+			got := IsValidLanguage(tt.lang)
 			if got != tt.want {
-				t.Errorf("isValidLanguage(%q) = %v, want %v", tt.lang, got, tt.want)
+				t.Errorf("IsValidLanguage(%q) = %v, want %v", tt.lang, got, tt.want)
 			}
 		})
 	}
@@ -340,6 +340,40 @@ func TestManager_Validate(t *testing.T) {
 			t.Error("Expected error for invalid source")
 		}
 	})
+
+	t.Run("history retention", func(t *testing.T) {
+		cfg := &Config{
+			HistoryMaxEntries: 1000,
+			HistoryMaxAge:     "90d",
+			HistoryMaxSizeMB:  50,
+		}
+		if err := mgr.validate(cfg); err != nil {
+			t.Errorf("validate() error = %v", err)
+		}
+
+		cfg.HistoryMaxAge = "ninety days"
+		if err := mgr.validate(cfg); err == nil {
+			t.Error("Expected error for invalid history max age")
+		}
+
+		cfg.HistoryMaxAge = ""
+		cfg.HistoryMaxEntries = -1
+		if err := mgr.validate(cfg); err == nil {
+			t.Error("Expected error for negative history max entries")
+		}
+	})
+
+	t.Run("debug redact patterns", func(t *testing.T) {
+		cfg := &Config{DebugRedact: []string{"x-internal-*", "my_cookie"}}
+		if err := mgr.validate(cfg); err != nil {
+			t.Errorf("validate() error = %v", err)
+		}
+
+		cfg.DebugRedact = []string{"[unclosed"}
+		if err := mgr.validate(cfg); err == nil {
+			t.Error("Expected error for invalid debug redact pattern")
+		}
+	})
 }
 
 func TestManagerGetPaths(t *testing.T) {
@@ -431,6 +465,27 @@ func TestManagerValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid stdin mode",
+			cfg: &Config{
+				StdinMode: "append",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid stdin layout",
+			cfg: &Config{
+				StdinLayout: "stdin-last",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative stdin attachment threshold",
+			cfg: &Config{
+				StdinAttachKB: -1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty config (uses defaults)",
 			cfg: &Config{
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// CurrentSchemaVersion is the config file schema written by this version.
+// Files without a schema_version are version 0.
+const CurrentSchemaVersion = 1
+
+const schemaVersionKey = "schema_version"
+
+// migration upgrades config file values to version, returning a description
+// of each change.
+type migration struct {
+	version int
+	apply   func(values map[string]any) []string
+}
+
+// migrations lists the schema upgrades in version order.
+var migrations = []migration{
+	{version: 1, apply: migrateV1},
+}
+
+// legacyKeys maps key names used by early releases to current keys.
+var legacyKeys = map[string]string{
+	"model":       "default_model",
+	"mode":        "default_mode",
+	"language":    "default_language",
+	"sources":     "default_sources",
+	"cookies":     "cookie_file",
+	"cookie_path": "cookie_file",
+	"history":     "history_file",
+}
+
+// legacyModels maps retired model ids to their replacements.
+var legacyModels = map[string]models.Model{
+	"gpt5":          models.ModelGPT51,
+	"gpt5_thinking": models.ModelGPT51Thinking,
+	"grok4":         models.ModelGrok41NonReasoning,
+}
+
+// legacyModes maps API mode names once accepted as modes to current modes.
+var legacyModes = map[string]models.Mode{
+	"concise": models.ModeFast,
+	"copilot": models.ModePro,
+}
+
+// migrateV1 renames legacy keys, splits comma-separated sources and rewrites
+// retired model ids, model aliases and legacy mode names.
+func migrateV1(values map[string]any) []string {
+	var changes []string
+
+	for old, key := range legacyKeys {
+		value, ok := values[old]
+		if !ok {
+			continue
+		}
+		delete(values, old)
+		if _, exists := values[key]; exists {
+			changes = append(changes, fmt.Sprintf("removed %s (superseded by %s)", old, key))
+			continue
+		}
+		values[key] = value
+		changes = append(changes, fmt.Sprintf("renamed %s to %s", old, key))
+	}
+
+	if s, ok := values["default_sources"].(string); ok {
+		var sources []string
+		for _, source := range strings.Split(s, ",") {
+			if source = strings.TrimSpace(source); source != "" {
+				sources = append(sources, source)
+			}
+		}
+		values["default_sources"] = sources
+		changes = append(changes, "converted default_sources to a list")
+	}
+
+	if model, ok := values["default_model"].(string); ok && model != "" {
+		replacement, retired := legacyModels[model]
+		if !retired {
+			replacement, _ = models.ResolveModel(model)
+		}
+		if replacement != "" && string(replacement) != model {
+			values["default_model"] = string(replacement)
+			changes = append(changes, fmt.Sprintf("changed default_model %s to %s", model, replacement))
+		}
+	}
+
+	if mode, ok := values["default_mode"].(string); ok {
+		if replacement, legacy := legacyModes[mode]; legacy {
+			values["default_mode"] = string(replacement)
+			changes = append(changes, fmt.Sprintf("changed default_mode %s to %s", mode, replacement))
+		}
+	}
+
+	return changes
+}
+
+// schemaVersion returns the schema version recorded in config values.
+func schemaVersion(values map[string]any) int {
+	switch v := values[schemaVersionKey].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// migrateConfigFile upgrades the user config file to the current schema,
+// keeping a backup of the original next to it.
+func (m *Manager) migrateConfigFile() error {
+	if _, err := os.Stat(m.cfgFile); err != nil {
+		return nil
+	}
+
+	values, err := m.readFileValues()
+	if err != nil {
+		return err
+	}
+
+	version := schemaVersion(values)
+	if version == CurrentSchemaVersion {
+		return nil
+	}
+	if version > CurrentSchemaVersion {
+		m.warnf("config %s has schema version %d, newer than this version supports (%d); some settings may be ignored",
+			m.cfgFile, version, CurrentSchemaVersion)
+		return nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", m.cfgFile, version)
+	if err := copyProfileFile(m.cfgFile, backup); err != nil {
+		return fmt.Errorf("failed to back up config: %w", err)
+	}
+
+	var changes []string
+	for _, mg := range migrations {
+		if mg.version > version {
+			changes = append(changes, mg.apply(values)...)
+		}
+	}
+
+	if err := m.writeFileValues(values); err != nil {
+		return err
+	}
+
+	if len(changes) > 0 {
+		m.warnf("migrated config %s to schema version %d (backup: %s): %s",
+			m.cfgFile, CurrentSchemaVersion, backup, strings.Join(changes, "; "))
+	}
+	return nil
+}
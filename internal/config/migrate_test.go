@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestMigrateV1(t *testing.T) {
+	values := map[string]any{
+		"model":        "gpt5",
+		"mode":         "copilot",
+		"sources":      "web, scholar",
+		"language":     "pt-BR",
+		"history_file": "/tmp/h.jsonl",
+		"history":      "/tmp/old.jsonl",
+	}
+
+	changes := migrateV1(values)
+
+	want := map[string]any{
+		"default_model":    "gpt51",
+		"default_mode":     "pro",
+		"default_sources":  []string{"web", "scholar"},
+		"default_language": "pt-BR",
+		"history_file":     "/tmp/h.jsonl",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("migrateV1() values = %#v, want %#v", values, want)
+	}
+	if len(changes) != 8 {
+		t.Errorf("migrateV1() reported %d changes, want 8: %v", len(changes), changes)
+	}
+}
+
+func TestMigrateV1_ModelAlias(t *testing.T) {
+	values := map[string]any{"default_model": "claude"}
+	migrateV1(values)
+	if values["default_model"] != string(models.ModelClaude45Sonnet) {
+		t.Errorf("default_model = %v, want %s", values["default_model"], models.ModelClaude45Sonnet)
+	}
+
+	values = map[string]any{"default_model": "gpt51"}
+	if changes := migrateV1(values); len(changes) != 0 {
+		t.Errorf("migrateV1() changed a current config: %v", changes)
+	}
+}
+
+func TestManager_Load_MigratesConfig(t *testing.T) {
+	m := newTestManager(t)
+	m.workDir = t.TempDir()
+
+	original := `{"model": "gpt5", "default_mode": "concise", "streaming": false}`
+	if err := os.WriteFile(m.cfgFile, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultModel != models.ModelGPT51 || cfg.DefaultMode != models.ModeFast || cfg.Streaming {
+		t.Errorf("Load() = model %q, mode %q, streaming %v", cfg.DefaultModel, cfg.DefaultMode, cfg.Streaming)
+	}
+	if len(m.Warnings()) != 1 || !strings.Contains(m.Warnings()[0], "migrated config") {
+		t.Errorf("Warnings() = %v, want a migration notice", m.Warnings())
+	}
+
+	backup, err := os.ReadFile(m.cfgFile + ".v0.bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup = %q, want original file", backup)
+	}
+
+	data, err := os.ReadFile(m.cfgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+	if schemaVersion(values) != CurrentSchemaVersion {
+		t.Errorf("schema_version = %v, want %d", values[schemaVersionKey], CurrentSchemaVersion)
+	}
+	if _, ok := values["model"]; ok {
+		t.Error("legacy key model was kept")
+	}
+
+	// A second load has nothing to migrate
+	if _, err := m.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(m.Warnings()) != 0 {
+		t.Errorf("Warnings() after migration = %v", m.Warnings())
+	}
+}
+
+func TestManager_Load_InvalidValuesFallBack(t *testing.T) {
+	m := newTestManager(t)
+	m.workDir = t.TempDir()
+
+	data := `{"schema_version": 1, "default_model": "gpt9", "default_language": "english", "default_mode": "pro"}`
+	if err := os.WriteFile(m.cfgFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultModel != models.ModelPplxPro {
+		t.Errorf("DefaultModel = %q, want default", cfg.DefaultModel)
+	}
+	if cfg.DefaultLanguage != "en-US" {
+		t.Errorf("DefaultLanguage = %q, want default", cfg.DefaultLanguage)
+	}
+	if cfg.DefaultMode != models.ModePro {
+		t.Errorf("DefaultMode = %q, want valid file value kept", cfg.DefaultMode)
+	}
+	if len(m.Warnings()) != 2 {
+		t.Errorf("Warnings() = %v, want 2", m.Warnings())
+	}
+}
+
+func TestManager_Load_NewerSchema(t *testing.T) {
+	m := newTestManager(t)
+	m.workDir = t.TempDir()
+
+	if err := os.WriteFile(m.cfgFile, []byte(`{"schema_version": 99}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(m.Warnings()) != 1 || !strings.Contains(m.Warnings()[0], "newer") {
+		t.Errorf("Warnings() = %v, want a newer schema warning", m.Warnings())
+	}
+	if _, err := os.Stat(m.cfgFile + ".v99.bak"); err == nil {
+		t.Error("newer config should not be rewritten")
+	}
+}
@@ -0,0 +1,294 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile stored directly in the config directory.
+	DefaultProfile = "default"
+
+	// ProfileEnvVar selects the profile when --profile is not given.
+	ProfileEnvVar = "PERPLEXITY_PROFILE"
+
+	profilesDirName       = "profiles"
+	activeProfileFileName = "active_profile"
+)
+
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ProfileStore manages named profiles. The default profile lives in the base
+// config directory; every other profile has its own directory with its own
+// config, cookies and history.
+type ProfileStore struct {
+	baseDir string
+}
+
+// NewProfileStore creates a profile store rooted at ~/.perplexity-cli.
+func NewProfileStore() (*ProfileStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &ProfileStore{baseDir: filepath.Join(home, configDirName)}, nil
+}
+
+// ValidateProfileName checks that a profile name is safe to use as a
+// directory name.
+func ValidateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid profile name: %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// Dir returns the configuration directory of a profile.
+func (s *ProfileStore) Dir(name string) string {
+	if name == "" || name == DefaultProfile {
+		return s.baseDir
+	}
+	return filepath.Join(s.baseDir, profilesDirName, name)
+}
+
+// Exists reports whether a profile exists. The default profile always does.
+func (s *ProfileStore) Exists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	info, err := os.Stat(s.Dir(name))
+	return err == nil && info.IsDir()
+}
+
+// List returns all profile names, starting with the default profile.
+func (s *ProfileStore) List() ([]string, error) {
+	names := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(s.baseDir, profilesDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var named []string
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil && e.Name() != DefaultProfile {
+			named = append(named, e.Name())
+		}
+	}
+	sort.Strings(named)
+	return append(names, named...), nil
+}
+
+// Create creates an empty profile.
+func (s *ProfileStore) Create(name string) error {
+	if err := s.checkNew(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir(name), 0700); err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a profile and all of its files. Deleting the active profile
+// makes the default profile active again.
+func (s *ProfileStore) Delete(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("cannot delete the default profile")
+	}
+	if err := s.checkExisting(name); err != nil {
+		return err
+	}
+
+	if active, err := s.Active(); err == nil && active == name {
+		if err := s.Use(DefaultProfile); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(s.Dir(name)); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	return nil
+}
+
+// Copy creates profile dst from the files of profile src. Paths in the
+// config that point into src are rewritten to point into dst.
+func (s *ProfileStore) Copy(src, dst string) error {
+	if err := s.checkExisting(src); err != nil {
+		return err
+	}
+	if err := s.checkNew(dst); err != nil {
+		return err
+	}
+
+	srcDir, dstDir := s.Dir(src), s.Dir(dst)
+	entries, err := os.ReadDir(srcDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read profile %s: %w", src, err)
+	}
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() || e.Name() == activeProfileFileName {
+			continue
+		}
+		from, to := filepath.Join(srcDir, e.Name()), filepath.Join(dstDir, e.Name())
+		if e.Name() == configFileName+"."+configFileType {
+			err = copyProfileConfig(from, to, srcDir, dstDir)
+		} else {
+			err = copyProfileFile(from, to)
+		}
+		if err != nil {
+			os.RemoveAll(dstDir)
+			return fmt.Errorf("failed to copy profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// Active returns the profile selected with 'profile use'.
+func (s *ProfileStore) Active() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.baseDir, activeProfileFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultProfile, nil
+		}
+		return "", fmt.Errorf("failed to read active profile: %w", err)
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	return name, nil
+}
+
+// Use makes a profile the active one.
+func (s *ProfileStore) Use(name string) error {
+	path := filepath.Join(s.baseDir, activeProfileFileName)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset active profile: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.checkExisting(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.baseDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save active profile: %w", err)
+	}
+	return nil
+}
+
+// Resolve picks the profile to use: the --profile flag, then the
+// PERPLEXITY_PROFILE environment variable, then the active profile. An
+// active profile that no longer exists falls back to the default.
+func (s *ProfileStore) Resolve(flag string) (string, error) {
+	name := flag
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+	if name != "" {
+		if err := s.checkExisting(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+
+	active, err := s.Active()
+	if err != nil {
+		return "", err
+	}
+	if ValidateProfileName(active) != nil || !s.Exists(active) {
+		return DefaultProfile, nil
+	}
+	return active, nil
+}
+
+// checkExisting validates name and checks that the profile exists.
+func (s *ProfileStore) checkExisting(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !s.Exists(name) {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	return nil
+}
+
+// checkNew validates name and checks that the profile does not exist yet.
+func (s *ProfileStore) checkNew(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if s.Exists(name) {
+		return fmt.Errorf("profile already exists: %s", name)
+	}
+	return nil
+}
+
+// copyProfileConfig copies a config file, pointing file paths that live in
+// srcDir at the same files in dstDir so the profiles stay isolated.
+func copyProfileConfig(from, to, srcDir, dstDir string) error {
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return copyProfileFile(from, to)
+	}
+
+	for key, v := range values {
+		path, ok := v.(string)
+		if !ok || !strings.HasSuffix(key, "_file") {
+			continue
+		}
+		if filepath.Dir(filepath.Clean(path)) == filepath.Clean(srcDir) {
+			values[key] = filepath.Join(dstDir, filepath.Base(path))
+		}
+	}
+
+	data, err = json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(to, data, 0600)
+}
+
+// copyProfileFile copies a file with owner-only permissions.
+func copyProfileFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"work", "Personal-2", "a.b_c"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("ValidateProfileName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../etc", "-x", "a/b", "with space"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("ValidateProfileName(%q) expected error", name)
+		}
+	}
+}
+
+func TestProfileStore_Lifecycle(t *testing.T) {
+	store := &ProfileStore{baseDir: t.TempDir()}
+
+	if err := store.Create("work"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := store.Create("work"); err == nil {
+		t.Error("Create() expected error for existing profile")
+	}
+	if err := store.Create(DefaultProfile); err == nil {
+		t.Error("Create() expected error for default profile")
+	}
+	if err := store.Create("alpha"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	names, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := []string{DefaultProfile, "alpha", "work"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+
+	if err := store.Use("work"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if active, _ := store.Active(); active != "work" {
+		t.Errorf("Active() = %q, want work", active)
+	}
+	if err := store.Use("missing"); err == nil {
+		t.Error("Use() expected error for missing profile")
+	}
+
+	if err := store.Delete(DefaultProfile); err == nil {
+		t.Error("Delete() expected error for default profile")
+	}
+	if err := store.Delete("work"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if store.Exists("work") {
+		t.Error("profile still exists after Delete()")
+	}
+	if active, _ := store.Active(); active != DefaultProfile {
+		t.Errorf("Active() after deleting active profile = %q, want default", active)
+	}
+}
+
+func TestProfileStore_Resolve(t *testing.T) {
+	store := &ProfileStore{baseDir: t.TempDir()}
+	for _, name := range []string{"work", "personal"} {
+		if err := store.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Use("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(ProfileEnvVar, "")
+	if got, _ := store.Resolve(""); got != "work" {
+		t.Errorf("Resolve() with active profile = %q, want work", got)
+	}
+
+	t.Setenv(ProfileEnvVar, "personal")
+	if got, _ := store.Resolve(""); got != "personal" {
+		t.Errorf("Resolve() with env = %q, want personal", got)
+	}
+	if got, _ := store.Resolve(DefaultProfile); got != DefaultProfile {
+		t.Errorf("Resolve() with flag = %q, want default", got)
+	}
+	if _, err := store.Resolve("missing"); err == nil {
+		t.Error("Resolve() expected error for missing profile")
+	}
+
+	// A stale active profile falls back to the default
+	t.Setenv(ProfileEnvVar, "")
+	if err := os.RemoveAll(store.Dir("work")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Resolve(""); err != nil || got != DefaultProfile {
+		t.Errorf("Resolve() with stale active profile = %q, %v; want default", got, err)
+	}
+}
+
+func TestProfileStore_Copy(t *testing.T) {
+	base := t.TempDir()
+	store := &ProfileStore{baseDir: base}
+
+	cfg := map[string]any{
+		"default_model": "gpt51",
+		"cookie_file":   filepath.Join(base, "cookies.json"),
+		"history_file":  "/elsewhere/history.jsonl",
+	}
+	data, _ := json.Marshal(cfg)
+	files := map[string]string{
+		"config.json":    string(data),
+		"cookies.json":   "[]",
+		"active_profile": "work\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(base, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Copy(DefaultProfile, "personal"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	dir := store.Dir("personal")
+
+	if _, err := os.Stat(filepath.Join(dir, "cookies.json")); err != nil {
+		t.Error("cookies.json was not copied")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "active_profile")); err == nil {
+		t.Error("active_profile should not be copied")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "profiles")); err == nil {
+		t.Error("profiles directory should not be copied")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied map[string]any
+	if err := json.Unmarshal(data, &copied); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := copied["cookie_file"], filepath.Join(dir, "cookies.json"); got != want {
+		t.Errorf("cookie_file = %v, want %v", got, want)
+	}
+	if got := copied["history_file"]; got != "/elsewhere/history.jsonl" {
+		t.Errorf("history_file outside the profile should be kept, got %v", got)
+	}
+	if got := copied["default_model"]; got != "gpt51" {
+		t.Errorf("default_model = %v, want gpt51", got)
+	}
+
+	if err := store.Copy(DefaultProfile, "personal"); err == nil {
+		t.Error("Copy() expected error for existing destination")
+	}
+	if err := store.Copy("missing", "other"); err == nil {
+		t.Error("Copy() expected error for missing source")
+	}
+}
+
+func TestNewManagerForProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	mgr, err := NewManagerForProfile("work")
+	if err != nil {
+		t.Fatalf("NewManagerForProfile() error = %v", err)
+	}
+	if mgr.Profile() != "work" {
+		t.Errorf("Profile() = %q, want work", mgr.Profile())
+	}
+	wantDir := filepath.Join(home, configDirName, profilesDirName, "work")
+	if mgr.GetConfigDir() != wantDir {
+		t.Errorf("GetConfigDir() = %q, want %q", mgr.GetConfigDir(), wantDir)
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.CookieFile != filepath.Join(wantDir, "cookies.json") {
+		t.Errorf("CookieFile = %q, want it inside the profile", cfg.CookieFile)
+	}
+	if cfg.HistoryFile != filepath.Join(wantDir, "history.jsonl") {
+		t.Errorf("HistoryFile = %q, want it inside the profile", cfg.HistoryFile)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// projectConfigName is the base name of project-local config files.
+const projectConfigName = ".perplexity"
+
+// projectConfigExts lists the supported project config formats in lookup
+// order.
+var projectConfigExts = []string{"json", "yaml", "yml", "toml"}
+
+// ProjectKeys lists the keys a project config may set. Paths and other
+// per-user settings stay in the user config so a repository cannot redirect
+// cookies or history.
+var ProjectKeys = []string{
+	"default_language",
+	"default_mode",
+	"default_model",
+	"default_sources",
+	"prompt_prefix",
+}
+
+// FindProjectConfig looks for a .perplexity.{json,yaml,yml,toml} file in dir
+// and its parents, returning the closest one or "" when there is none.
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, ext := range projectConfigExts {
+			path := filepath.Join(dir, projectConfigName+"."+ext)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				return path, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadProjectConfig layers the closest project config on top of the user
+// config.
+func (m *Manager) loadProjectConfig() error {
+	dir := m.workDir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+		dir = wd
+	}
+
+	path, err := FindProjectConfig(dir)
+	if err != nil || path == "" {
+		return err
+	}
+
+	pv := viper.New()
+	pv.SetConfigFile(path)
+	if err := pv.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read project config %s: %w", path, err)
+	}
+
+	allowed := make(map[string]bool, len(ProjectKeys))
+	for _, key := range ProjectKeys {
+		allowed[key] = true
+	}
+	keys := pv.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !allowed[key] {
+			return fmt.Errorf("unsupported key in project config %s: %s (allowed: %s)",
+				path, key, strings.Join(ProjectKeys, ", "))
+		}
+	}
+
+	if err := m.v.MergeConfigMap(pv.AllSettings()); err != nil {
+		return fmt.Errorf("failed to merge project config %s: %w", path, err)
+	}
+	m.project = pv
+	m.projectFile = path
+	return nil
+}
+
+// GetProjectFile returns the project config file in use, or "" when there
+// is none.
+func (m *Manager) GetProjectFile() string {
+	return m.projectFile
+}
+
+// keepProjectValuesOut stops Save from copying project values into the user
+// config: keys set by the project config and left unchanged keep their user
+// config or default value.
+func (m *Manager) keepProjectValuesOut(cfg *Config) error {
+	if m.project == nil || m.loaded == nil {
+		return nil
+	}
+
+	user, err := m.readFileValues()
+	if err != nil {
+		return err
+	}
+	defaults := &Manager{v: viper.New(), cfgDir: m.cfgDir}
+	defaults.setDefaults()
+
+	for _, key := range m.project.AllKeys() {
+		current, err := cfg.Get(key)
+		if err != nil {
+			return err
+		}
+		loaded, err := m.loaded.Get(key)
+		if err != nil {
+			return err
+		}
+		if FormatValue(current) != FormatValue(loaded) {
+			continue
+		}
+
+		if value, ok := user[key]; ok {
+			m.v.Set(key, value)
+		} else {
+			m.v.Set(key, defaults.v.Get(key))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, err := FindProjectConfig(nested); err != nil || path != "" {
+		t.Errorf("FindProjectConfig() without config = %q, %v", path, err)
+	}
+
+	rootCfg := filepath.Join(root, ".perplexity.toml")
+	if err := os.WriteFile(rootCfg, []byte(`default_mode = "pro"`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if path, _ := FindProjectConfig(nested); path != rootCfg {
+		t.Errorf("FindProjectConfig() = %q, want %q", path, rootCfg)
+	}
+
+	closer := filepath.Join(root, "a", ".perplexity.yaml")
+	if err := os.WriteFile(closer, []byte("default_mode: fast\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if path, _ := FindProjectConfig(nested); path != closer {
+		t.Errorf("FindProjectConfig() = %q, want closest %q", path, closer)
+	}
+}
+
+func TestManager_Load_ProjectConfig(t *testing.T) {
+	m := newTestManager(t)
+	project := t.TempDir()
+	m.workDir = project
+
+	user := `{"default_mode": "reasoning", "default_language": "pt-BR"}`
+	if err := os.WriteFile(m.cfgFile, []byte(user), 0600); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "default_mode: pro\ndefault_sources: [web, scholar]\nprompt_prefix: Be brief.\n"
+	if err := os.WriteFile(filepath.Join(project, ".perplexity.yaml"), []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultMode != models.ModePro {
+		t.Errorf("DefaultMode = %q, want project value pro", cfg.DefaultMode)
+	}
+	if cfg.DefaultLanguage != "pt-BR" {
+		t.Errorf("DefaultLanguage = %q, want user value pt-BR", cfg.DefaultLanguage)
+	}
+	if len(cfg.DefaultSources) != 2 {
+		t.Errorf("DefaultSources = %v, want web and scholar", cfg.DefaultSources)
+	}
+	if cfg.PromptPrefix != "Be brief." {
+		t.Errorf("PromptPrefix = %q", cfg.PromptPrefix)
+	}
+
+	origins := map[string]string{
+		"default_mode":     OriginProject,
+		"default_language": OriginFile,
+		"default_model":    OriginDefault,
+	}
+	for key, want := range origins {
+		if got := m.Origin(key); got != want {
+			t.Errorf("Origin(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if m.GetProjectFile() != filepath.Join(project, ".perplexity.yaml") {
+		t.Errorf("GetProjectFile() = %q", m.GetProjectFile())
+	}
+
+	// Saving keeps unchanged project values out of the user config
+	cfg.DefaultModel = models.ModelGPT51
+	if err := m.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved, err := m.readFileValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved["default_mode"] != "reasoning" {
+		t.Errorf("saved default_mode = %v, want user value reasoning", saved["default_mode"])
+	}
+	if saved["prompt_prefix"] != "" {
+		t.Errorf("saved prompt_prefix = %v, want default", saved["prompt_prefix"])
+	}
+	if saved["default_model"] != string(models.ModelGPT51) {
+		t.Errorf("saved default_model = %v, want gpt51", saved["default_model"])
+	}
+}
+
+func TestManager_Load_ProjectConfigRejectsUserKeys(t *testing.T) {
+	m := newTestManager(t)
+	m.workDir = t.TempDir()
+
+	data := `{"cookie_file": "/tmp/other-cookies.json"}`
+	if err := os.WriteFile(filepath.Join(m.workDir, ".perplexity.json"), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Load(); err == nil {
+		t.Error("Load() expected error for cookie_file in project config")
+	}
+}
+
+func TestManager_Load_ProjectConfigValidated(t *testing.T) {
+	m := newTestManager(t)
+	m.workDir = t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(m.workDir, ".perplexity.toml"), []byte(`default_mode = "turbo"`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultMode != models.ModeDefault {
+		t.Errorf("DefaultMode = %q, want default for invalid project value", cfg.DefaultMode)
+	}
+	if len(m.Warnings()) != 1 || !strings.Contains(m.Warnings()[0], "project") {
+		t.Errorf("Warnings() = %v, want one warning naming the project layer", m.Warnings())
+	}
+}
@@ -0,0 +1,222 @@
+// Package diff computes word-level differences between texts.
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Op is the kind of change a chunk represents.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Chunk is a run of text that is unchanged, inserted or deleted.
+type Chunk struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the LCS table size for a single block; larger changed
+// blocks are reported as a whole deletion plus insertion.
+const maxCells = 4 << 20
+
+// Words returns the word-level diff turning a into b. Texts are first
+// aligned line by line, then changed blocks are compared word by word, so
+// long answers stay cheap to diff.
+func Words(a, b string) []Chunk {
+	chunks := merge(diffTokens(splitLines(a), splitLines(b)))
+
+	// Refine adjacent delete/insert line blocks at word level.
+	var out []Chunk
+	for i := 0; i < len(chunks); i++ {
+		c := chunks[i]
+		if c.Op == Delete && i+1 < len(chunks) && chunks[i+1].Op == Insert {
+			out = append(out, diffTokens(splitWords(c.Text), splitWords(chunks[i+1].Text))...)
+			i++
+			continue
+		}
+		out = append(out, c)
+	}
+
+	return merge(out)
+}
+
+// Changed reports whether the diff contains any insertion or deletion.
+func Changed(chunks []Chunk) bool {
+	for _, c := range chunks {
+		if c.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets compares two lists of strings (e.g. source URLs), returning the items
+// only in b (added), only in a (removed) and the number present in both.
+func Sets(a, b []string) (added, removed []string, kept int) {
+	inA := make(map[string]bool, len(a))
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		if !inB[s] {
+			inB[s] = true
+			if inA[s] {
+				kept++
+			} else {
+				added = append(added, s)
+			}
+		}
+	}
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		if !inB[s] && !seen[s] {
+			removed = append(removed, s)
+		}
+		seen[s] = true
+	}
+	return added, removed, kept
+}
+
+// diffTokens diffs two token lists using a longest common subsequence,
+// after trimming their common prefix and suffix.
+func diffTokens(a, b []string) []Chunk {
+	var prefix, suffix []Chunk
+
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, Chunk{Equal, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]Chunk{{Equal, a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var middle []Chunk
+	switch {
+	case len(a) == 0 && len(b) == 0:
+	case len(a)*len(b) > maxCells:
+		middle = []Chunk{{Delete, strings.Join(a, "")}, {Insert, strings.Join(b, "")}}
+	default:
+		middle = lcs(a, b)
+	}
+
+	return append(append(prefix, middle...), suffix...)
+}
+
+// lcs computes a minimal diff with a dynamic-programming LCS table.
+func lcs(a, b []string) []Chunk {
+	n, m := len(a), len(b)
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var chunks []Chunk
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			chunks = append(chunks, Chunk{Equal, a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			chunks = append(chunks, Chunk{Delete, a[i]})
+			i++
+		default:
+			chunks = append(chunks, Chunk{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		chunks = append(chunks, Chunk{Delete, a[i]})
+	}
+	for ; j < m; j++ {
+		chunks = append(chunks, Chunk{Insert, b[j]})
+	}
+	return chunks
+}
+
+// merge joins consecutive chunks with the same op and orders each changed
+// run as deletions before insertions.
+func merge(chunks []Chunk) []Chunk {
+	var out []Chunk
+	var del, ins strings.Builder
+
+	flush := func() {
+		if del.Len() > 0 {
+			out = append(out, Chunk{Delete, del.String()})
+			del.Reset()
+		}
+		if ins.Len() > 0 {
+			out = append(out, Chunk{Insert, ins.String()})
+			ins.Reset()
+		}
+	}
+
+	for _, c := range chunks {
+		switch c.Op {
+		case Delete:
+			del.WriteString(c.Text)
+		case Insert:
+			ins.WriteString(c.Text)
+		default:
+			flush()
+			if n := len(out); n > 0 && out[n-1].Op == Equal {
+				out[n-1].Text += c.Text
+			} else {
+				out = append(out, c)
+			}
+		}
+	}
+	flush()
+
+	return out
+}
+
+// splitLines splits text into lines, keeping the line terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords splits text into alternating word and whitespace tokens, so
+// joining the tokens reproduces the text.
+func splitWords(s string) []string {
+	var tokens []string
+	start := 0
+	inSpace := false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// render formats chunks as [-deleted-]{+inserted+} for comparison.
+func render(chunks []Chunk) string {
+	var b strings.Builder
+	for _, c := range chunks {
+		switch c.Op {
+		case Delete:
+			b.WriteString("[-" + c.Text + "-]")
+		case Insert:
+			b.WriteString("{+" + c.Text + "+}")
+		default:
+			b.WriteString(c.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"identical", "Go is fast.", "Go is fast.", "Go is fast."},
+		{"word replaced", "Go is fast.", "Go is quick.", "Go is [-fast.-]{+quick.+}"},
+		{"word inserted", "Go is fast.", "Go is very fast.", "Go is {+very +}fast."},
+		{"word deleted", "Go is very fast.", "Go is fast.", "Go is [-very -]fast."},
+		{"empty to text", "", "new", "{+new+}"},
+		{"text to empty", "old", "", "[-old-]"},
+		{"unchanged lines kept", "Title\nGo is fast.\nEnd\n", "Title\nGo is quick.\nEnd\n", "Title\nGo is [-fast.-]{+quick.+}\nEnd\n"},
+		{"unicode", "Café é bom", "Café é ótimo", "Café é [-bom-]{+ótimo+}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Words(tt.a, tt.b)
+			if got := render(chunks); got != tt.want {
+				t.Errorf("Words() = %q, want %q", got, tt.want)
+			}
+
+			// Equal+Delete rebuilds a, Equal+Insert rebuilds b.
+			var a, b strings.Builder
+			for _, c := range chunks {
+				if c.Op != Insert {
+					a.WriteString(c.Text)
+				}
+				if c.Op != Delete {
+					b.WriteString(c.Text)
+				}
+			}
+			if a.String() != tt.a || b.String() != tt.b {
+				t.Errorf("chunks do not rebuild inputs: %q / %q", a.String(), b.String())
+			}
+
+			if Changed(chunks) != (tt.a != tt.b) {
+				t.Errorf("Changed() = %v", Changed(chunks))
+			}
+		})
+	}
+}
+
+func TestWords_LargeBlockFallback(t *testing.T) {
+	a := strings.Repeat("alpha ", 3000)
+	b := strings.Repeat("beta ", 3000)
+
+	chunks := Words(a, b)
+	// Only the shared trailing space may follow the replacement.
+	if len(chunks) > 3 || chunks[0].Op != Delete || chunks[1].Op != Insert {
+		t.Errorf("expected whole-block replacement, got %d chunks", len(chunks))
+	}
+}
+
+func TestSets(t *testing.T) {
+	added, removed, kept := Sets(
+		[]string{"https://a", "https://b", "https://c", "https://b"},
+		[]string{"https://b", "https://d", "https://a", "https://d"},
+	)
+
+	if strings.Join(added, ",") != "https://d" {
+		t.Errorf("added = %v", added)
+	}
+	if strings.Join(removed, ",") != "https://c" {
+		t.Errorf("removed = %v", removed)
+	}
+	if kept != 2 {
+		t.Errorf("kept = %d, want 2", kept)
+	}
+}
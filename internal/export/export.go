@@ -0,0 +1,167 @@
+// Package export writes search answers to shareable documents.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// Format represents an export document format.
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+)
+
+// AvailableFormats contains all valid export format names.
+var AvailableFormats = []Format{
+	FormatText,
+	FormatMarkdown,
+	FormatHTML,
+	FormatJSON,
+}
+
+// Document holds everything needed to export a single answer.
+type Document struct {
+	Query       string             `json:"query"`
+	Answer      string             `json:"answer"`
+	Mode        string             `json:"mode,omitempty"`
+	Model       string             `json:"model,omitempty"`
+	Language    string             `json:"language,omitempty"`
+	BackendUUID string             `json:"backend_uuid,omitempty"`
+	Date        time.Time          `json:"date"`
+	Sources     []models.WebResult `json:"sources,omitempty"`
+}
+
+// ParseFormat converts a format name (or common alias) to a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "text", "txt", "raw":
+		return FormatText, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid export format: %s (valid: text, markdown, html, json)", name)
+}
+
+// FormatFromPath picks an export format based on the file extension.
+// Unknown extensions fall back to plain text.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".html", ".htm":
+		return FormatHTML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatText
+	}
+}
+
+// Write renders the document in the given format.
+func Write(w io.Writer, doc Document, format Format) error {
+	switch format {
+	case FormatText, "":
+		_, err := io.WriteString(w, doc.Answer)
+		return err
+	case FormatMarkdown:
+		return writeMarkdown(w, doc)
+	case FormatHTML:
+		return writeHTML(w, doc)
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+// WriteFile renders the document to a file.
+func WriteFile(path string, doc Document, format Format) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if err := Write(file, doc, format); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// citationPattern matches inline citation markers such as [1] that are not
+// already followed by a link target.
+var citationPattern = regexp.MustCompile(`\[(\d+)\](\()?`)
+
+// ResolveCitations turns [n] markers into markdown links pointing at the
+// source with citation number n. Markers without a matching source are
+// left untouched.
+func ResolveCitations(text string, sources []models.WebResult) string {
+	urls := make(map[int]string, len(sources))
+	for i, src := range sources {
+		urls[src.CitationNumber(i)] = src.URL
+	}
+
+	return citationPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := citationPattern.FindStringSubmatch(match)
+		if groups[2] != "" {
+			return match
+		}
+
+		n, err := strconv.Atoi(groups[1])
+		if err != nil || urls[n] == "" {
+			return match
+		}
+
+		return fmt.Sprintf("[\\[%d\\]](%s)", n, urls[n])
+	})
+}
+
+// sourceTitle returns the best human-readable label for a source.
+func sourceTitle(wr models.WebResult) string {
+	if wr.Title != "" {
+		return wr.Title
+	}
+	if wr.Name != "" {
+		return wr.Name
+	}
+	return wr.URL
+}
+
+// slugPattern matches runs of characters not allowed in file name slugs.
+var slugPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// FileName builds a filesystem-safe file name for the document from its date
+// and query, e.g. "2025-01-02-1504-what-is-go.md".
+func FileName(doc Document, ext string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(doc.Query), "-"), "-")
+	if runes := []rune(slug); len(runes) > 60 {
+		slug = strings.TrimRight(string(runes[:60]), "-")
+	}
+	if slug == "" {
+		slug = "untitled"
+	}
+	return doc.Date.Format("2006-01-02-1504") + "-" + slug + ext
+}
@@ -0,0 +1,368 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func testDocument() Document {
+	return Document{
+		Query:       "What is Go?",
+		Answer:      "Go is a programming language [1][2]. See [3] and [link](https://x.test).",
+		Mode:        "pro",
+		Model:       "gpt51",
+		Language:    "en-US",
+		BackendUUID: "uuid-123",
+		Date:        time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Sources: []models.WebResult{
+			{Title: "The Go Programming Language", URL: "https://go.dev"},
+			{Name: "Wikipedia", URL: "https://en.wikipedia.org/wiki/Go"},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"text", FormatText, false},
+		{"txt", FormatText, false},
+		{"markdown", FormatMarkdown, false},
+		{"MD", FormatMarkdown, false},
+		{"html", FormatHTML, false},
+		{"htm", FormatHTML, false},
+		{"json", FormatJSON, false},
+		{"pdf", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Format
+	}{
+		{"report.md", FormatMarkdown},
+		{"report.MARKDOWN", FormatMarkdown},
+		{"report.html", FormatHTML},
+		{"report.htm", FormatHTML},
+		{"report.json", FormatJSON},
+		{"report.txt", FormatText},
+		{"report", FormatText},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFromPath(tt.path); got != tt.want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolveCitations(t *testing.T) {
+	doc := testDocument()
+	got := ResolveCitations(doc.Answer, doc.Sources)
+
+	if !strings.Contains(got, "[\\[1\\]](https://go.dev)") {
+		t.Errorf("citation [1] not resolved: %s", got)
+	}
+	if !strings.Contains(got, "[\\[2\\]](https://en.wikipedia.org/wiki/Go)") {
+		t.Errorf("citation [2] not resolved: %s", got)
+	}
+	if !strings.Contains(got, "See [3]") {
+		t.Errorf("citation without source should be untouched: %s", got)
+	}
+	if !strings.Contains(got, "[link](https://x.test)") {
+		t.Errorf("existing link should be untouched: %s", got)
+	}
+}
+
+func TestResolveCitationsFilteredSources(t *testing.T) {
+	// The first server result was filtered out; the answer still cites [2]
+	doc := testDocument()
+	doc.Answer = "Go is fast [2]. Details [1]."
+	doc.Sources = []models.WebResult{{Title: "Go", URL: "https://go.dev", Index: 2}}
+
+	got := ResolveCitations(doc.Answer, doc.Sources)
+	if !strings.Contains(got, "[\\[2\\]](https://go.dev)") {
+		t.Errorf("citation [2] not resolved through Index: %s", got)
+	}
+	if !strings.Contains(got, "Details [1].") {
+		t.Errorf("citation of a filtered-out source should be untouched: %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc, FormatMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "- \\[2\\] [Go](https://go.dev)\n") {
+		t.Errorf("markdown source list should keep the citation number:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, doc, FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<li value="2">`) {
+		t.Errorf("HTML source list should keep the citation number")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	doc := testDocument()
+
+	if err := Write(&buf, doc, FormatText); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if buf.String() != doc.Answer {
+		t.Errorf("text export = %q, want raw answer", buf.String())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testDocument(), FormatMarkdown); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"---\nquery: \"What is Go?\"\n",
+		"model: \"gpt51\"\n",
+		"date: \"2025-01-02T03:04:05Z\"\n",
+		"backend_uuid: \"uuid-123\"\n",
+		"# What is Go?",
+		"](https://go.dev)",
+		"## Sources",
+		"1. [The Go Programming Language](https://go.dev)",
+		"2. [Wikipedia](https://en.wikipedia.org/wiki/Go)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown export missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_QuotesFrontMatter(t *testing.T) {
+	var buf bytes.Buffer
+	doc := testDocument()
+	doc.Query = "Say \"hi\"\nthen: leave"
+
+	if err := Write(&buf, doc, FormatMarkdown); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `query: "Say \"hi\"\nthen: leave"`) {
+		t.Errorf("query not safely quoted:\n%s", buf.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	doc := testDocument()
+	doc.Answer += "\n\n<script>alert(1)</script>"
+
+	if err := Write(&buf, doc, FormatHTML); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<style>",
+		"<title>What is Go?</title>",
+		`<a href="https://go.dev">[1]</a>`,
+		`<li value="2"><a href="https://en.wikipedia.org/wiki/Go">Wikipedia</a>`,
+		"<dt>Model</dt><dd>gpt51</dd>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html export missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("html export should not embed raw script tags")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	doc := testDocument()
+
+	if err := Write(&buf, doc, FormatJSON); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got Document
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Query != doc.Query || got.Model != doc.Model || len(got.Sources) != 2 {
+		t.Errorf("JSON round trip mismatch: %+v", got)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+
+	if err := WriteFile(path, testDocument(), FormatMarkdown); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "---\n") {
+		t.Errorf("expected front matter, got %q", string(data)[:20])
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testDocument(), Format("pdf")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	date := time.Date(2025, 1, 2, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"What is Go?", "2025-01-02-1504-what-is-go.md"},
+		{"Café: naïve / résumé", "2025-01-02-1504-café-naïve-résumé.md"},
+		{"???", "2025-01-02-1504-untitled.md"},
+		{strings.Repeat("word ", 30), "2025-01-02-1504-" + strings.TrimSuffix(strings.Repeat("word-", 12), "-") + ".md"},
+	}
+	for _, tt := range tests {
+		if got := FileName(Document{Query: tt.query, Date: date}, ".md"); got != tt.want {
+			t.Errorf("FileName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWriteHTMLCollection(t *testing.T) {
+	docs := []Document{
+		{Query: "First <question>", Answer: "**bold** answer", Date: time.Now()},
+		{Query: "Second", Answer: "see [1]", Sources: []models.WebResult{{Title: "Src", URL: "https://example.com"}}, Date: time.Now()},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTMLCollection(&buf, "History", docs); err != nil {
+		t.Fatalf("WriteHTMLCollection() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"<title>History</title>", "First &lt;question&gt;", "<strong>bold</strong>", `href="https://example.com"`, `href="#entry-1"`, "<style>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q\n%s", want, out)
+		}
+	}
+}
+
+func testTranscript() Transcript {
+	second := testDocument()
+	second.Query = "And its history?"
+	second.Answer = "Created at Google."
+	second.Mode = "reasoning"
+	second.BackendUUID = "uuid-456"
+	second.Sources = nil
+
+	return Transcript{
+		Title: "Go research",
+		Date:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Turns: []Document{testDocument(), second},
+	}
+}
+
+func TestWriteTranscriptMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTranscript(&buf, testTranscript(), FormatMarkdown); err != nil {
+		t.Fatalf("WriteTranscript() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"---\ntitle: \"Go research\"\n",
+		"backend_uuid: \"uuid-456\"\n",
+		"turns: 2\n",
+		"# Go research\n",
+		"## What is Go?\n\n_Model: gpt51 · Mode: pro_\n",
+		"### Sources",
+		"1. [The Go Programming Language](https://go.dev)",
+		"## And its history?\n\n_Model: gpt51 · Mode: reasoning_\n\nCreated at Google.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown transcript missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTranscriptFormats(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTranscript(&buf, testTranscript(), FormatJSON); err != nil {
+		t.Fatalf("WriteTranscript(json) error = %v", err)
+	}
+	var decoded Transcript
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Title != "Go research" || len(decoded.Turns) != 2 || decoded.Turns[1].BackendUUID != "uuid-456" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	buf.Reset()
+	if err := WriteTranscript(&buf, testTranscript(), FormatText); err != nil {
+		t.Fatalf("WriteTranscript(text) error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "> What is Go?\n\nGo is") || !strings.Contains(buf.String(), "> And its history?\n\nCreated at Google.\n") {
+		t.Errorf("text transcript:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteTranscript(&buf, testTranscript(), FormatHTML); err != nil {
+		t.Fatalf("WriteTranscript(html) error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "<title>Go research</title>") {
+		t.Errorf("html transcript missing title")
+	}
+
+	if err := WriteTranscript(&buf, testTranscript(), Format("pdf")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestWriteTranscriptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.md")
+	if err := WriteTranscriptFile(path, testTranscript(), FormatMarkdown); err != nil {
+		t.Fatalf("WriteTranscriptFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "## And its history?") {
+		t.Errorf("file content:\n%s", data)
+	}
+}
@@ -0,0 +1,206 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// htmlTemplates holds self-contained pages with embedded CSS suitable for
+// sharing or printing: "page" for a single answer and "collection" for many.
+var htmlTemplates = template.Must(template.New("style").Parse(`<style>
+  :root { --accent: #E36414; --muted: #6b6b6b; --border: #e2e2e2; }
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif;
+         line-height: 1.6; color: #1e1e1e; max-width: 50rem; margin: 2rem auto; padding: 0 1.25rem; }
+  header { border-bottom: 2px solid var(--accent); margin-bottom: 1.5rem; padding-bottom: 0.75rem; }
+  header h1 { font-size: 1.6rem; margin: 0 0 0.5rem; }
+  dl.meta { display: grid; grid-template-columns: max-content 1fr; gap: 0.15rem 1rem; margin: 0;
+            color: var(--muted); font-size: 0.85rem; }
+  dl.meta dt { font-weight: 600; }
+  dl.meta dd { margin: 0; }
+  a { color: var(--accent); }
+  pre { background: #f6f6f6; border: 1px solid var(--border); border-radius: 4px; padding: 0.75rem; overflow-x: auto; }
+  code { font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, monospace; font-size: 0.9em; }
+  table { border-collapse: collapse; }
+  th, td { border: 1px solid var(--border); padding: 0.3rem 0.6rem; }
+  blockquote { border-left: 3px solid var(--border); margin-left: 0; padding-left: 1rem; color: var(--muted); }
+  section.sources { border-top: 1px solid var(--border); margin-top: 2rem; font-size: 0.9rem; }
+  section.sources li { margin-bottom: 0.35rem; word-break: break-word; }
+  section.sources .url { color: var(--muted); font-size: 0.8rem; display: block; }
+  article { border-bottom: 1px solid var(--border); padding-bottom: 1.5rem; margin-bottom: 2rem; }
+  nav.toc ol { padding-left: 1.25rem; }
+  @media print { body { margin: 0; max-width: none; } a { color: inherit; } }
+</style>`))
+
+var htmlTemplate = template.Must(htmlTemplates.New("page").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="perplexity-cli">
+<title>{{.Query}}</title>
+{{template "style"}}
+</head>
+<body>
+<header>
+<h1>{{.Query}}</h1>
+<dl class="meta">
+{{- if .Model}}<dt>Model</dt><dd>{{.Model}}</dd>{{end}}
+{{- if .Mode}}<dt>Mode</dt><dd>{{.Mode}}</dd>{{end}}
+{{- if .Language}}<dt>Language</dt><dd>{{.Language}}</dd>{{end}}
+<dt>Date</dt><dd>{{.Date}}</dd>
+{{- if .BackendUUID}}<dt>Thread</dt><dd>{{.BackendUUID}}</dd>{{end}}
+</dl>
+</header>
+<main>
+{{.Answer}}
+</main>
+{{- if .Sources}}
+<section class="sources">
+<h2>Sources</h2>
+<ol>
+{{- range .Sources}}
+<li value="{{.Index}}"><a href="{{.URL}}">{{.Title}}</a><span class="url">{{.URL}}</span></li>
+{{- end}}
+</ol>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+var htmlCollectionTemplate = template.Must(htmlTemplates.New("collection").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="perplexity-cli">
+<title>{{.Title}}</title>
+{{template "style"}}
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+</header>
+{{- if .Pages}}
+<nav class="toc">
+<ol>
+{{- range $i, $p := .Pages}}
+<li><a href="#entry-{{$i}}">{{$p.Query}}</a> <small>{{$p.Date}}</small></li>
+{{- end}}
+</ol>
+</nav>
+{{- end}}
+{{- range $i, $p := .Pages}}
+<article id="entry-{{$i}}">
+<h2>{{$p.Query}}</h2>
+<dl class="meta">
+{{- if $p.Model}}<dt>Model</dt><dd>{{$p.Model}}</dd>{{end}}
+{{- if $p.Mode}}<dt>Mode</dt><dd>{{$p.Mode}}</dd>{{end}}
+<dt>Date</dt><dd>{{$p.Date}}</dd>
+</dl>
+{{$p.Answer}}
+{{- if $p.Sources}}
+<section class="sources">
+<h3>Sources</h3>
+<ol>
+{{- range $p.Sources}}
+<li value="{{.Index}}"><a href="{{.URL}}">{{.Title}}</a><span class="url">{{.URL}}</span></li>
+{{- end}}
+</ol>
+</section>
+{{- end}}
+</article>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlSource struct {
+	Index int
+	Title string
+	URL   string
+}
+
+type htmlPage struct {
+	Query       string
+	Model       string
+	Mode        string
+	Language    string
+	Lang        string
+	BackendUUID string
+	Date        string
+	Answer      template.HTML
+	Sources     []htmlSource
+}
+
+// writeHTML renders the document as a standalone HTML page.
+func writeHTML(w io.Writer, doc Document) error {
+	page, err := newHTMLPage(doc)
+	if err != nil {
+		return err
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+// WriteHTMLCollection renders several documents as a single HTML page with
+// a table of contents.
+func WriteHTMLCollection(w io.Writer, title string, docs []Document) error {
+	data := struct {
+		Title string
+		Pages []htmlPage
+	}{Title: title}
+
+	for _, doc := range docs {
+		page, err := newHTMLPage(doc)
+		if err != nil {
+			return err
+		}
+		data.Pages = append(data.Pages, page)
+	}
+
+	return htmlCollectionTemplate.Execute(w, data)
+}
+
+// newHTMLPage converts a document into template data.
+func newHTMLPage(doc Document) (htmlPage, error) {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	var answer bytes.Buffer
+	if err := md.Convert([]byte(ResolveCitations(doc.Answer, doc.Sources)), &answer); err != nil {
+		return htmlPage{}, fmt.Errorf("failed to convert answer to HTML: %w", err)
+	}
+
+	page := htmlPage{
+		Query:       singleLine(doc.Query),
+		Model:       doc.Model,
+		Mode:        doc.Mode,
+		Language:    doc.Language,
+		Lang:        doc.Language,
+		BackendUUID: doc.BackendUUID,
+		Date:        doc.Date.Format(time.RFC1123),
+		// goldmark escapes raw HTML in the answer by default, so the output is safe to embed.
+		Answer: template.HTML(answer.String()),
+	}
+	if page.Lang == "" {
+		page.Lang = "en"
+	}
+
+	for i, src := range doc.Sources {
+		if src.URL == "" {
+			continue
+		}
+		page.Sources = append(page.Sources, htmlSource{
+			Index: src.CitationNumber(i),
+			Title: singleLine(sourceTitle(src)),
+			URL:   src.URL,
+		})
+	}
+
+	return page, nil
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// writeMarkdown renders the document as markdown with YAML front matter.
+func writeMarkdown(w io.Writer, doc Document) error {
+	_, err := io.WriteString(w, renderMarkdown(doc))
+	return err
+}
+
+// renderMarkdown builds the markdown body shared by the markdown and HTML exporters.
+func renderMarkdown(doc Document) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	writeFrontMatter(&b, "query", doc.Query)
+	writeFrontMatter(&b, "model", doc.Model)
+	writeFrontMatter(&b, "mode", doc.Mode)
+	writeFrontMatter(&b, "language", doc.Language)
+	writeFrontMatter(&b, "date", doc.Date.Format(time.RFC3339))
+	writeFrontMatter(&b, "backend_uuid", doc.BackendUUID)
+	fmt.Fprintf(&b, "sources: %d\n", len(doc.Sources))
+	b.WriteString("---\n\n")
+
+	b.WriteString(markdownBody(doc))
+
+	return b.String()
+}
+
+// markdownBody renders the query heading, answer and sources without front matter.
+func markdownBody(doc Document) string {
+	return fmt.Sprintf("# %s\n\n", singleLine(doc.Query)) + markdownAnswer(doc, "##")
+}
+
+// markdownAnswer renders the answer and its sources, listed under a heading
+// of the given level.
+func markdownAnswer(doc Document, sourcesHeading string) string {
+	var b strings.Builder
+
+	b.WriteString(strings.TrimSpace(ResolveCitations(doc.Answer, doc.Sources)))
+	b.WriteString("\n")
+
+	if len(doc.Sources) > 0 {
+		fmt.Fprintf(&b, "\n%s Sources\n\n", sourcesHeading)
+		// An ordered list renumbers its items, so filtered or reordered
+		// sources are listed with their citation number as text instead
+		numbered := sourcesInPlace(doc.Sources)
+		for i, src := range doc.Sources {
+			if src.URL == "" {
+				continue
+			}
+			if numbered {
+				fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, escapeLinkText(sourceTitle(src)), src.URL)
+			} else {
+				fmt.Fprintf(&b, "- \\[%d\\] [%s](%s)\n", src.CitationNumber(i), escapeLinkText(sourceTitle(src)), src.URL)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// writeFrontMatter writes a single YAML key with a safely quoted value.
+// JSON string encoding is valid YAML, so it is used for quoting.
+func writeFrontMatter(b *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	quoted, _ := json.Marshal(value)
+	fmt.Fprintf(b, "%s: %s\n", key, quoted)
+}
+
+// singleLine collapses whitespace so a value fits on one markdown line.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// escapeLinkText escapes characters that would break markdown link text.
+func escapeLinkText(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(singleLine(s))
+}
+
+// sourcesInPlace reports whether every source's citation number is its
+// position in the list.
+func sourcesInPlace(sources []models.WebResult) bool {
+	for i, src := range sources {
+		if src.CitationNumber(i) != i+1 {
+			return false
+		}
+	}
+	return true
+}
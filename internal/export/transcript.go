@@ -0,0 +1,101 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript holds the turns of a conversation in one thread.
+type Transcript struct {
+	Title string     `json:"title"`
+	Date  time.Time  `json:"date"`
+	Turns []Document `json:"turns"`
+}
+
+// WriteTranscript renders the transcript in the given format.
+func WriteTranscript(w io.Writer, t Transcript, format Format) error {
+	switch format {
+	case FormatText, "":
+		_, err := io.WriteString(w, renderTranscriptText(t))
+		return err
+	case FormatMarkdown:
+		_, err := io.WriteString(w, renderTranscriptMarkdown(t))
+		return err
+	case FormatHTML:
+		return WriteHTMLCollection(w, t.Title, t.Turns)
+	case FormatJSON:
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal transcript: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+// WriteTranscriptFile renders the transcript to a file.
+func WriteTranscriptFile(path string, t Transcript, format Format) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if err := WriteTranscript(file, t, format); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// renderTranscriptText lists each query followed by its answer.
+func renderTranscriptText(t Transcript) string {
+	var b strings.Builder
+	for i, doc := range t.Turns {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "> %s\n\n%s\n", singleLine(doc.Query), strings.TrimSpace(doc.Answer))
+	}
+	return b.String()
+}
+
+// renderTranscriptMarkdown renders the transcript with YAML front matter and
+// one section per turn.
+func renderTranscriptMarkdown(t Transcript) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	writeFrontMatter(&b, "title", t.Title)
+	writeFrontMatter(&b, "date", t.Date.Format(time.RFC3339))
+	if n := len(t.Turns); n > 0 {
+		writeFrontMatter(&b, "backend_uuid", t.Turns[n-1].BackendUUID)
+	}
+	fmt.Fprintf(&b, "turns: %d\n", len(t.Turns))
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n", singleLine(t.Title))
+	for _, doc := range t.Turns {
+		fmt.Fprintf(&b, "\n## %s\n\n", singleLine(doc.Query))
+
+		var meta []string
+		if doc.Model != "" {
+			meta = append(meta, "Model: "+doc.Model)
+		}
+		if doc.Mode != "" {
+			meta = append(meta, "Mode: "+doc.Mode)
+		}
+		if len(meta) > 0 {
+			fmt.Fprintf(&b, "_%s_\n\n", strings.Join(meta, " · "))
+		}
+
+		b.WriteString(markdownAnswer(doc, "###"))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diogo/perplexity-go/internal/secret"
+)
+
+// SetEncrypted encrypts or decrypts the history database, its archives and
+// the backups of migrated JSONL history in place and returns the paths of
+// the files it rewrote. Files already in the requested state are left alone.
+func SetEncrypted(historyFile string, encrypt bool) ([]string, error) {
+	var changed []string
+
+	path := DBPath(historyFile)
+	if _, err := os.Stat(path); err == nil && secret.IsEncryptedFile(path) != encrypt {
+		if err := convertStore(historyFile, encrypt); err != nil {
+			return changed, err
+		}
+		changed = append(changed, path)
+	}
+
+	archives, err := filepath.Glob(filepath.Join(ArchiveDir(historyFile), "*"+archiveExt))
+	if err != nil {
+		return changed, err
+	}
+	backups, err := filepath.Glob(legacyPath(historyFile) + "*" + migratedExt)
+	if err != nil {
+		return changed, err
+	}
+	for _, path := range append(archives, backups...) {
+		if secret.IsEncryptedFile(path) == encrypt {
+			continue
+		}
+		if err := convertFile(path, encrypt); err != nil {
+			return changed, err
+		}
+		changed = append(changed, path)
+	}
+
+	return changed, nil
+}
+
+// convertFile rewrites a file with or without encryption.
+func convertFile(path string, encrypt bool) error {
+	data, _, err := secret.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := secret.WriteFile(path, data, encrypt); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// convertStore rewrites the history database with or without encryption.
+func convertStore(historyFile string, encrypt bool) error {
+	s, err := OpenStore(historyFile)
+	if err != nil {
+		return err
+	}
+	// Close without flushing; the database is rewritten below, still locked
+	defer s.lock.release()
+	data, err := s.serialize()
+	s.db.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := secret.WriteFile(s.path, data, encrypt); err != nil {
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/secret"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func useTestPassphrase(t *testing.T, enabled bool) {
+	t.Helper()
+	secret.Configure(enabled, func(bool) ([]byte, error) { return []byte("test passphrase"), nil })
+	t.Cleanup(func() { secret.Configure(false, nil) })
+}
+
+func TestEncryptedStore(t *testing.T) {
+	useTestPassphrase(t, true)
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+
+	w, err := NewWriter(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Append(models.HistoryEntry{Query: "confidential merger plans", Response: "answer"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !secret.IsEncrypted(data) {
+		t.Fatal("history database is not encrypted")
+	}
+	if bytes.Contains(data, []byte("merger")) {
+		t.Error("encrypted database contains the query")
+	}
+
+	results, err := NewReader(historyFile).Search("merger")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Query != "confidential merger plans" {
+		t.Errorf("Search() = %v, want the stored entry", results)
+	}
+}
+
+func TestEncryptedStore_ReadDoesNotRewrite(t *testing.T) {
+	useTestPassphrase(t, true)
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+
+	w, _ := NewWriter(historyFile)
+	if err := w.Append(models.HistoryEntry{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(historyFile)
+
+	if _, err := NewReader(historyFile).ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.ReadFile(historyFile)
+	if !bytes.Equal(before, after) {
+		t.Error("reading the history rewrote the encrypted database")
+	}
+}
+
+func TestSetEncrypted(t *testing.T) {
+	useTestPassphrase(t, false)
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := OpenStore(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().AddDate(0, -6, 0)
+	if err := store.Insert(
+		models.HistoryEntry{Timestamp: old, Query: "archived question"},
+		models.HistoryEntry{Timestamp: time.Now(), Query: "recent question"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Prune(Retention{MaxAge: "30d"}, time.Now(), false); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	changed, err := SetEncrypted(historyFile, true)
+	if err != nil {
+		t.Fatalf("SetEncrypted(true) error = %v", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("SetEncrypted(true) changed %v, want the database and one archive", changed)
+	}
+	for _, path := range changed {
+		if !secret.IsEncryptedFile(path) {
+			t.Errorf("%s is not encrypted", path)
+		}
+	}
+
+	// Already encrypted files are left alone
+	if again, err := SetEncrypted(historyFile, true); err != nil || len(again) != 0 {
+		t.Errorf("second SetEncrypted(true) = %v, %v; want nothing changed", again, err)
+	}
+
+	results, err := NewReader(historyFile).Search("question")
+	if err != nil || len(results) != 2 {
+		t.Errorf("Search() = %v, %v; want both entries", results, err)
+	}
+
+	if _, err := SetEncrypted(historyFile, false); err != nil {
+		t.Fatalf("SetEncrypted(false) error = %v", err)
+	}
+	for _, path := range changed {
+		if secret.IsEncryptedFile(path) {
+			t.Errorf("%s is still encrypted", path)
+		}
+	}
+}
+
+func TestEncryptedStore_OverlappingStores(t *testing.T) {
+	useTestPassphrase(t, true)
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+
+	first, err := OpenStore(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		second, err := OpenStore(historyFile)
+		if err != nil {
+			done <- err
+			return
+		}
+		if err := second.Insert(models.HistoryEntry{Query: "from second", Timestamp: time.Now()}); err != nil {
+			second.Close()
+			done <- err
+			return
+		}
+		done <- second.Close()
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("second store finished while the first was open (err = %v)", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := first.Insert(models.HistoryEntry{Query: "from first", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("second store error = %v", err)
+	}
+
+	entries, err := NewReader(historyFile).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("ReadAll() = %d entries, want both stores' entries", len(entries))
+	}
+	if _, err := os.Stat(historyFile + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestEncryptedStore_Lock(t *testing.T) {
+	useTestPassphrase(t, true)
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+	lockFile := historyFile + ".lock"
+
+	orig := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	defer func() { lockTimeout = orig }()
+
+	if err := os.WriteFile(lockFile, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenStore(historyFile); err == nil || !strings.Contains(err.Error(), "locked by another process") {
+		t.Fatalf("OpenStore() error = %v, want locked", err)
+	}
+
+	old := time.Now().Add(-lockStale - time.Minute)
+	if err := os.Chtimes(lockFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenStore(historyFile)
+	if err != nil {
+		t.Fatalf("OpenStore() with a stale lock error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetEncrypted_MigratedBackup(t *testing.T) {
+	useTestPassphrase(t, false)
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(historyFile, []byte(`{"timestamp":"2024-01-01T10:00:00Z","query":"secret plans"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := OpenStore(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	backup := historyFile + migratedExt
+	changed, err := SetEncrypted(historyFile, true)
+	if err != nil {
+		t.Fatalf("SetEncrypted(true) error = %v", err)
+	}
+	found := false
+	for _, path := range changed {
+		found = found || path == backup
+	}
+	if !found {
+		t.Errorf("SetEncrypted(true) changed %v, want the migrated backup listed", changed)
+	}
+	if data, _ := os.ReadFile(backup); !secret.IsEncrypted(data) || bytes.Contains(data, []byte("secret plans")) {
+		t.Error("migrated backup is not encrypted")
+	}
+}
+
+func TestEncryptedStore_EncryptsMigratedBackup(t *testing.T) {
+	useTestPassphrase(t, true)
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(historyFile, []byte(`{"timestamp":"2024-01-01T10:00:00Z","query":"secret plans"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := NewReader(historyFile).ReadAll()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ReadAll() = %v, %v", entries, err)
+	}
+	if data, _ := os.ReadFile(historyFile + migratedExt); !secret.IsEncrypted(data) {
+		t.Error("migrated backup of an encrypted history is stored in plaintext")
+	}
+}
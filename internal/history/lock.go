@@ -0,0 +1,58 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout is how long OpenStore waits for another process to release an
+// encrypted database.
+var lockTimeout = 10 * time.Second
+
+// lockStale is the age after which a lock file is assumed to be left over
+// from a process that died without removing it.
+const lockStale = 2 * time.Minute
+
+const lockPoll = 50 * time.Millisecond
+
+// fileLock is an exclusive lock on a database file, held from loading an
+// encrypted database until it is written back so that two runs at once
+// can't overwrite each other's entries.
+type fileLock struct {
+	path string
+}
+
+// acquireLock creates path+".lock", waiting up to lockTimeout while another
+// process holds it.
+func acquireLock(path string) (*fileLock, error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return &fileLock{path: lockPath}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock history database: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("history database is locked by another process (remove %s if none is running)", lockPath)
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// release removes the lock file. It is a no-op on a nil lock.
+func (l *fileLock) release() {
+	if l != nil {
+		os.Remove(l.path)
+	}
+}
@@ -0,0 +1,427 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/secret"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+const (
+	archiveDirName = "history-archive"
+	archiveExt     = ".jsonl.gz"
+	archiveMonth   = "2006-01"
+
+	// vacuumThreshold is the amount of free space (in bytes) left by rotated
+	// entries before the database file is compacted.
+	vacuumThreshold = 1 << 20
+)
+
+// Retention limits how much history is kept in the store. Entries outside
+// the limits rotate into compressed monthly archives. Zero values disable a limit.
+type Retention struct {
+	MaxEntries int
+	// MaxAge is a relative age such as 90d, 6mo or 1y.
+	MaxAge   string
+	MaxBytes int64
+}
+
+// IsZero reports whether no retention limit is set.
+func (r Retention) IsZero() bool {
+	return r.MaxEntries <= 0 && r.MaxAge == "" && r.MaxBytes <= 0
+}
+
+// PruneResult describes entries rotated (or, in a dry run, to be rotated)
+// out of the store.
+type PruneResult struct {
+	Archived int
+	// ByMonth counts archived entries per archive month (YYYY-MM).
+	ByMonth map[string]int
+	Dir     string
+}
+
+// Months returns the archive months in chronological order.
+func (p PruneResult) Months() []string {
+	months := make([]string, 0, len(p.ByMonth))
+	for m := range p.ByMonth {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+	return months
+}
+
+// ArchiveDir returns the directory holding compressed history archives.
+func ArchiveDir(historyFile string) string {
+	return filepath.Join(filepath.Dir(DBPath(historyFile)), archiveDirName)
+}
+
+// archiveDir returns the directory holding the store's archives.
+func (s *Store) archiveDir() string {
+	return filepath.Join(filepath.Dir(s.path), archiveDirName)
+}
+
+// SetRetention makes Append, Import and Merge enforce the given retention
+// policy.
+func (w *Writer) SetRetention(r Retention) {
+	w.retention = r
+}
+
+// Prune moves entries outside the retention policy into monthly archives and
+// compacts the database. With dryRun set nothing is changed.
+func (s *Store) Prune(r Retention, now time.Time, dryRun bool) (PruneResult, error) {
+	result := PruneResult{ByMonth: make(map[string]int), Dir: s.archiveDir()}
+	if r.IsZero() {
+		return result, nil
+	}
+
+	ids, err := s.expiredIDs(r, now)
+	if err != nil || len(ids) == 0 {
+		return result, err
+	}
+
+	entries, err := s.byIDs(ids)
+	if err != nil {
+		return result, err
+	}
+
+	byMonth := make(map[string][]models.HistoryEntry)
+	for _, e := range entries {
+		month := e.Timestamp.UTC().Format(archiveMonth)
+		byMonth[month] = append(byMonth[month], e)
+		result.ByMonth[month]++
+	}
+	result.Archived = len(entries)
+
+	if dryRun {
+		return result, nil
+	}
+
+	// Archive first so a failure never loses entries.
+	for month, monthEntries := range byMonth {
+		if err := appendArchive(filepath.Join(result.Dir, month+archiveExt), monthEntries); err != nil {
+			return result, err
+		}
+	}
+
+	if err := s.deleteIDs(ids); err != nil {
+		return result, err
+	}
+
+	return result, s.compact()
+}
+
+// zeroTimestamp is how an entry without a timestamp is stored.
+var zeroTimestamp = time.Time{}.UnixMilli()
+
+// expiredIDs returns the IDs of entries outside the retention policy, in
+// ascending order. Entries are ranked newest first by timestamp; entries
+// without a timestamp never expire by age.
+func (s *Store) expiredIDs(r Retention, now time.Time) ([]int64, error) {
+	expired := make(map[int64]bool)
+
+	if r.MaxEntries > 0 {
+		if err := s.collectIDs(expired, `SELECT id FROM entries ORDER BY timestamp DESC, id DESC LIMIT -1 OFFSET ?`, r.MaxEntries); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.MaxAge != "" {
+		cutoff, err := models.ParseSince(r.MaxAge, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid history max age: %w", err)
+		}
+		if err := s.collectIDs(expired, `SELECT id FROM entries WHERE timestamp < ? AND timestamp NOT IN (0, ?)`, cutoff.UnixMilli(), zeroTimestamp); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.MaxBytes > 0 {
+		if err := s.collectOversized(expired, r.MaxBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int64, 0, len(expired))
+	for id := range expired {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// collectIDs adds the IDs returned by q to ids.
+func (s *Store) collectIDs(ids map[int64]bool, q string, args ...any) error {
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		ids[id] = true
+	}
+	return rows.Err()
+}
+
+// collectOversized adds to ids the entries that no longer fit in maxBytes
+// once every newer entry is kept.
+func (s *Store) collectOversized(ids map[int64]bool, maxBytes int64) error {
+	rows, err := s.db.Query(`SELECT id, length(data) + length(response) FROM entries ORDER BY timestamp DESC, id DESC`)
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var total int64
+	for rows.Next() {
+		var id, size int64
+		if err := rows.Scan(&id, &size); err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		total += size
+		if total > maxBytes {
+			ids[id] = true
+		}
+	}
+	return rows.Err()
+}
+
+// byIDs loads the entries with the given IDs.
+func (s *Store) byIDs(ids []int64) ([]models.HistoryEntry, error) {
+	var entries []models.HistoryEntry
+	for _, chunk := range chunkIDs(ids) {
+		found, err := s.query(`SELECT data, response FROM entries WHERE id IN (`+placeholders(len(chunk))+`) ORDER BY id`, chunk...)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+	return entries, nil
+}
+
+// deleteIDs removes the entries with the given IDs in one transaction.
+func (s *Store) deleteIDs(ids []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, chunk := range chunkIDs(ids) {
+		if _, err := tx.Exec(`DELETE FROM entries WHERE id IN (`+placeholders(len(chunk))+`)`, chunk...); err != nil {
+			return fmt.Errorf("failed to delete history entries: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// compact rebuilds the database file once enough space has been freed.
+func (s *Store) compact() error {
+	var free, pageSize int64
+	if err := s.db.QueryRow(`PRAGMA freelist_count`).Scan(&free); err != nil {
+		return err
+	}
+	if err := s.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return err
+	}
+	if free*pageSize < vacuumThreshold {
+		return nil
+	}
+
+	if _, err := s.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	return nil
+}
+
+// appendArchive appends entries to a gzip-compressed JSONL archive. Each call
+// adds a new gzip member, which readers decode as one continuous stream.
+func appendArchive(path string, entries []models.HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if secret.ShouldEncrypt(path) {
+		return appendEncryptedArchive(path, entries)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	enc := json.NewEncoder(gz)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return file.Close()
+}
+
+// appendEncryptedArchive rewrites an encrypted archive with a new gzip
+// member holding entries, since encrypted data cannot be appended to.
+func appendEncryptedArchive(path string, entries []models.HistoryEntry) error {
+	var data []byte
+	if _, err := os.Stat(path); err == nil {
+		if data, _, err = secret.ReadFile(path); err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+	}
+
+	buf := bytes.NewBuffer(data)
+	gz := gzip.NewWriter(buf)
+	enc := json.NewEncoder(gz)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := secret.WriteFile(path, buf.Bytes(), true); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// readArchive reads every entry of a compressed archive, skipping malformed
+// lines. Encrypted archives are decrypted first.
+func readArchive(path string) ([]models.HistoryEntry, error) {
+	data, _, err := secret.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", filepath.Base(path), err)
+	}
+	defer gz.Close()
+
+	var entries []models.HistoryEntry
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var entry models.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", filepath.Base(path), err)
+	}
+	return entries, nil
+}
+
+// searchArchives scans archives (newest month first) for entries matching
+// opts. Matching is a case-insensitive containment test for every word.
+func searchArchives(dir string, opts SearchOptions, limit int) ([]models.HistoryEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+archiveExt))
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	words := strings.Fields(opts.Text)
+	var results []models.HistoryEntry
+	for _, path := range paths {
+		month, err := time.Parse(archiveMonth, strings.TrimSuffix(filepath.Base(path), archiveExt))
+		if err == nil && !archiveInRange(month, opts) {
+			continue
+		}
+
+		entries, err := readArchive(path)
+		if err != nil {
+			return results, err
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if matchesEntry(entries[i], opts, words) {
+				results = append(results, entries[i])
+				if limit > 0 && len(results) >= limit {
+					return results, nil
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// archiveInRange reports whether an archive month can hold entries within
+// the search date range.
+func archiveInRange(month time.Time, opts SearchOptions) bool {
+	end := month.AddDate(0, 1, 0)
+	if !opts.Since.IsZero() && !end.After(opts.Since) {
+		return false
+	}
+	if !opts.Until.IsZero() && month.After(opts.Until) {
+		return false
+	}
+	return true
+}
+
+// matchesEntry applies search filters to an archived entry.
+func matchesEntry(e models.HistoryEntry, opts SearchOptions, words []string) bool {
+	if !opts.Since.IsZero() && e.Timestamp.Before(opts.Since) {
+		return false
+	}
+	if !opts.Until.IsZero() && e.Timestamp.After(opts.Until) {
+		return false
+	}
+	if opts.Mode != "" && !strings.EqualFold(e.Mode, opts.Mode) {
+		return false
+	}
+	if opts.Model != "" && !strings.EqualFold(e.Model, opts.Model) {
+		return false
+	}
+	for _, w := range words {
+		if !containsIgnoreCase(e.Query, w) && !containsIgnoreCase(e.Response, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkIDs splits ids into groups small enough for one SQL statement.
+func chunkIDs(ids []int64) [][]any {
+	const size = 500
+	var chunks [][]any
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		chunk := make([]any, 0, end-start)
+		for _, id := range ids[start:end] {
+			chunk = append(chunk, id)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// placeholders returns n comma-separated SQL parameter placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
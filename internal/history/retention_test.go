@@ -0,0 +1,203 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// seedHistory writes one entry per month from Jan to Jun 2025.
+func seedHistory(t *testing.T) (string, *Store) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for m := 1; m <= 6; m++ {
+		err := store.Insert(models.HistoryEntry{
+			Timestamp: time.Date(2025, time.Month(m), 15, 12, 0, 0, 0, time.UTC),
+			Query:     time.Month(m).String() + " question",
+			Response:  "answer",
+			Mode:      "pro",
+		})
+		if err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+	return path, store
+}
+
+func TestStorePrune(t *testing.T) {
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		retention Retention
+		want      int
+		months    []string
+	}{
+		{"no limits", Retention{}, 0, nil},
+		{"max age", Retention{MaxAge: "90d"}, 3, []string{"2025-01", "2025-02", "2025-03"}},
+		{"max entries", Retention{MaxEntries: 4}, 2, []string{"2025-01", "2025-02"}},
+		{"max bytes", Retention{MaxBytes: 1}, 6, []string{"2025-01", "2025-02", "2025-03", "2025-04", "2025-05", "2025-06"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, store := seedHistory(t)
+
+			result, err := store.Prune(tt.retention, now, false)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			if result.Archived != tt.want {
+				t.Errorf("Archived = %d, want %d", result.Archived, tt.want)
+			}
+			months := result.Months()
+			if len(months) != len(tt.months) {
+				t.Fatalf("Months() = %v, want %v", months, tt.months)
+			}
+			for i := range months {
+				if months[i] != tt.months[i] {
+					t.Errorf("Months() = %v, want %v", months, tt.months)
+				}
+			}
+
+			if n, _ := store.Count(); n != 6-tt.want {
+				t.Errorf("Count() = %d, want %d", n, 6-tt.want)
+			}
+		})
+	}
+}
+
+func TestStorePrune_OrdersByTimestamp(t *testing.T) {
+	_, store := seedHistory(t)
+	// Imported late, so it has the highest id but the oldest timestamp.
+	if err := store.Insert(models.HistoryEntry{Timestamp: time.Date(2024, 12, 15, 12, 0, 0, 0, time.UTC), Query: "December question"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Prune(Retention{MaxEntries: 6}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), false)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if months := result.Months(); len(months) != 1 || months[0] != "2024-12" {
+		t.Errorf("Months() = %v, want the oldest entry archived", months)
+	}
+}
+
+func TestStorePrune_KeepsUndatedEntriesByAge(t *testing.T) {
+	_, store := seedHistory(t)
+	if err := store.Insert(models.HistoryEntry{Query: "undated question"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Prune(Retention{MaxAge: "90d"}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), false)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if result.Archived != 3 {
+		t.Errorf("Archived = %d, want 3 (the undated entry kept)", result.Archived)
+	}
+	if found, err := store.Search(SearchOptions{Text: "undated"}); err != nil || len(found) != 1 {
+		t.Errorf("undated entry = %d, %v; want it kept in the store", len(found), err)
+	}
+}
+
+func TestStorePrune_DryRun(t *testing.T) {
+	_, store := seedHistory(t)
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := store.Prune(Retention{MaxAge: "90d"}, now, true)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if result.Archived != 3 {
+		t.Errorf("Archived = %d, want 3", result.Archived)
+	}
+	if n, _ := store.Count(); n != 6 {
+		t.Errorf("dry run removed entries: Count() = %d", n)
+	}
+	if _, err := os.Stat(result.Dir); !os.IsNotExist(err) {
+		t.Error("dry run should not create archives")
+	}
+}
+
+func TestPrune_ArchivesStaySearchable(t *testing.T) {
+	path, store := seedHistory(t)
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two prunes into the same month append a second gzip member.
+	if _, err := store.Prune(Retention{MaxEntries: 5}, now, false); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if err := store.Insert(models.HistoryEntry{Timestamp: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), Query: "late January question"}); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if _, err := store.Prune(Retention{MaxAge: "120d"}, now, false); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	archived, err := readArchive(filepath.Join(ArchiveDir(path), "2025-01.jsonl.gz"))
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	if len(archived) != 2 {
+		t.Errorf("January archive has %d entries, want 2", len(archived))
+	}
+
+	reader := NewReader(path)
+	tests := []struct {
+		opts SearchOptions
+		want int
+	}{
+		{SearchOptions{Text: "january"}, 2},
+		{SearchOptions{Text: "QUESTION"}, 7},
+		{SearchOptions{Text: "question", Limit: 3}, 3},
+		{SearchOptions{Text: "question", Until: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}, 2},
+		{SearchOptions{Text: "question", Mode: "fast"}, 0},
+	}
+	for _, tt := range tests {
+		got, err := reader.SearchWith(tt.opts)
+		if err != nil {
+			t.Fatalf("SearchWith(%+v) error = %v", tt.opts, err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("SearchWith(%+v) = %d entries, want %d", tt.opts, len(got), tt.want)
+		}
+	}
+}
+
+func TestWriterAppend_AppliesRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	w.SetRetention(Retention{MaxEntries: 2})
+
+	for _, q := range []string{"one", "two", "three"} {
+		if err := w.Append(models.HistoryEntry{Query: q}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Query != "two" {
+		t.Errorf("entries = %v", queries(entries))
+	}
+
+	found, err := NewReader(path).Search("one")
+	if err != nil || len(found) != 1 {
+		t.Errorf("archived entry not searchable: %d, %v", len(found), err)
+	}
+}
@@ -0,0 +1,614 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/diogo/perplexity-go/internal/secret"
+	"github.com/diogo/perplexity-go/pkg/models"
+
+	_ "modernc.org/sqlite" // pure-Go SQLite driver with FTS5
+	"modernc.org/sqlite/vfs"
+)
+
+// storeSchemaVersion is stored in PRAGMA user_version.
+const storeSchemaVersion = 1
+
+// migratedExt is appended to a legacy JSONL history file once it has been
+// migrated into the database.
+const migratedExt = ".migrated"
+
+// snapshotName is the file name of a decrypted database in its in-memory VFS.
+const snapshotName = "history.db"
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS entries (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp    INTEGER NOT NULL,
+	query        TEXT    NOT NULL,
+	response     TEXT    NOT NULL DEFAULT '',
+	mode         TEXT    NOT NULL DEFAULT '',
+	model        TEXT    NOT NULL DEFAULT '',
+	backend_uuid TEXT    NOT NULL DEFAULT '',
+	data         TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_entries_timestamp ON entries(timestamp);
+CREATE INDEX IF NOT EXISTS idx_entries_mode ON entries(mode);
+CREATE INDEX IF NOT EXISTS idx_entries_model ON entries(model);
+CREATE INDEX IF NOT EXISTS idx_entries_backend_uuid ON entries(backend_uuid);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts5(
+	query, response,
+	content='entries', content_rowid='id',
+	tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS entries_ai AFTER INSERT ON entries BEGIN
+	INSERT INTO entries_fts(rowid, query, response) VALUES (new.id, new.query, new.response);
+END;
+CREATE TRIGGER IF NOT EXISTS entries_ad AFTER DELETE ON entries BEGIN
+	INSERT INTO entries_fts(entries_fts, rowid, query, response) VALUES ('delete', old.id, old.query, old.response);
+END;
+CREATE TRIGGER IF NOT EXISTS entries_au AFTER UPDATE ON entries BEGIN
+	INSERT INTO entries_fts(entries_fts, rowid, query, response) VALUES ('delete', old.id, old.query, old.response);
+	INSERT INTO entries_fts(rowid, query, response) VALUES (new.id, new.query, new.response);
+END;
+`
+
+// Store is an indexed history database backed by SQLite with FTS5.
+//
+// An encrypted database is decrypted into memory when opened and written
+// back encrypted on Close if it changed. The file stays locked in between,
+// so other processes wait rather than load a snapshot that is about to be
+// replaced.
+type Store struct {
+	db        *sql.DB
+	path      string
+	encrypted bool
+	changes   int64
+	lock      *fileLock
+}
+
+// SearchOptions filters and ranks history searches.
+type SearchOptions struct {
+	// Text is matched against query and answer; empty matches everything.
+	Text  string
+	Since time.Time
+	Until time.Time
+	Mode  string
+	Model string
+	// Limit caps the number of results (0 = unlimited).
+	Limit int
+}
+
+// DBPath returns the database path for a history file setting. A legacy
+// ".jsonl" path maps to a ".db" file alongside it; any other name without
+// a ".db" extension gets ".db" appended, so a custom legacy history file is
+// never opened as a database.
+func DBPath(historyFile string) string {
+	switch filepath.Ext(historyFile) {
+	case ".db":
+		return historyFile
+	case ".jsonl":
+		return strings.TrimSuffix(historyFile, ".jsonl") + ".db"
+	}
+	return historyFile + ".db"
+}
+
+// legacyPath returns the JSONL history file migrated into the database.
+func legacyPath(historyFile string) string {
+	if filepath.Ext(historyFile) == ".db" {
+		return strings.TrimSuffix(historyFile, ".db") + ".jsonl"
+	}
+	return historyFile
+}
+
+// OpenStore opens (or creates) the history database for historyFile and
+// migrates any legacy JSONL history into it.
+func OpenStore(historyFile string) (*Store, error) {
+	path := DBPath(historyFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	var s *Store
+	var snapshot []byte
+	var err error
+	if secret.ShouldEncrypt(path) {
+		s, snapshot, err = openEncrypted(path)
+	} else {
+		s, err = openFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.init(); err != nil {
+		s.discard()
+		return nil, err
+	}
+	if s.encrypted {
+		if err := s.restore(snapshot); err != nil {
+			s.discard()
+			return nil, err
+		}
+	}
+
+	if err := s.migrateLegacy(legacyPath(historyFile)); err != nil {
+		s.discard()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// openFile opens a plaintext database file.
+func openFile(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	return &Store{db: db, path: path}, nil
+}
+
+// openEncrypted locks the encrypted database at path, opens an in-memory
+// database for it and returns the decrypted contents of the file, if any.
+func openEncrypted(path string) (*Store, []byte, error) {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var snapshot []byte
+	if _, err := os.Stat(path); err == nil {
+		if snapshot, _, err = secret.ReadFile(path); err != nil {
+			lock.release()
+			return nil, nil, fmt.Errorf("failed to open history database: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		lock.release()
+		return nil, nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// Every connection to :memory: is a separate database, so keep one
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	return &Store{db: db, path: path, encrypted: true, lock: lock}, snapshot, nil
+}
+
+// restore copies the entries of a decrypted database file into the
+// in-memory database and records the change count to detect later writes.
+// The file is read through a read-only VFS so plaintext never touches disk.
+func (s *Store) restore(snapshot []byte) error {
+	if len(snapshot) > 0 {
+		name, fsys, err := vfs.New(snapshotFS(snapshot))
+		if err != nil {
+			return fmt.Errorf("failed to load history database: %w", err)
+		}
+		defer fsys.Close()
+
+		const columns = `id, timestamp, query, response, mode, model, backend_uuid, data`
+		stmts := []string{
+			fmt.Sprintf(`ATTACH DATABASE 'file:%s?vfs=%s&immutable=1' AS snapshot`, snapshotName, name),
+			`INSERT INTO entries (` + columns + `) SELECT ` + columns + ` FROM snapshot.entries`,
+			`DETACH DATABASE snapshot`,
+		}
+		for _, stmt := range stmts {
+			if _, err := s.db.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to load history database: %w", err)
+			}
+		}
+	}
+
+	if err := s.db.QueryRow(`SELECT total_changes()`).Scan(&s.changes); err != nil {
+		return fmt.Errorf("failed to load history database: %w", err)
+	}
+	return nil
+}
+
+// snapshotFS is a read-only file system holding one decrypted database
+// file, named snapshotName.
+type snapshotFS []byte
+
+// Open implements fs.FS.
+func (f snapshotFS) Open(name string) (fs.File, error) {
+	if name != snapshotName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return snapshotFile{bytes.NewReader(f)}, nil
+}
+
+// snapshotFile is an open snapshotFS file. The VFS reads it through Seek
+// and Read, and it serves as its own fs.FileInfo.
+type snapshotFile struct {
+	*bytes.Reader
+}
+
+func (f snapshotFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f snapshotFile) Close() error               { return nil }
+func (f snapshotFile) Name() string               { return snapshotName }
+func (f snapshotFile) Mode() fs.FileMode          { return 0400 }
+func (f snapshotFile) ModTime() time.Time         { return time.Time{} }
+func (f snapshotFile) IsDir() bool                { return false }
+func (f snapshotFile) Sys() any                   { return nil }
+
+// sqliteConn is the part of the SQLite driver connection used to dump a
+// database to a byte slice.
+type sqliteConn interface {
+	Serialize() ([]byte, error)
+}
+
+// rawConn runs fn on the underlying driver connection.
+func (s *Store) rawConn(fn func(sqliteConn) error) error {
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(sqliteConn)
+		if !ok {
+			return fmt.Errorf("SQLite driver does not support serialization")
+		}
+		return fn(c)
+	})
+}
+
+// serialize returns the database as the bytes of a SQLite file.
+func (s *Store) serialize() ([]byte, error) {
+	var data []byte
+	err := s.rawConn(func(c sqliteConn) error {
+		var err error
+		data, err = c.Serialize()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize history database: %w", err)
+	}
+	return data, nil
+}
+
+// flush writes an encrypted in-memory database back to disk if it changed.
+func (s *Store) flush() error {
+	var changes int64
+	if err := s.db.QueryRow(`SELECT total_changes()`).Scan(&changes); err != nil {
+		return fmt.Errorf("failed to save history database: %w", err)
+	}
+	if changes == s.changes {
+		if _, err := os.Stat(s.path); err == nil {
+			return nil
+		}
+	}
+
+	data, err := s.serialize()
+	if err != nil {
+		return err
+	}
+	if err := secret.WriteFile(s.path, data, true); err != nil {
+		return fmt.Errorf("failed to save history database: %w", err)
+	}
+	s.changes = changes
+	return nil
+}
+
+// init creates the schema if needed.
+func (s *Store) init() error {
+	if _, err := s.db.Exec(storeSchema); err != nil {
+		return fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", storeSchemaVersion)); err != nil {
+		return fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	if s.encrypted {
+		return nil
+	}
+	if err := os.Chmod(s.path, 0600); err != nil {
+		return fmt.Errorf("failed to set history database permissions: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacy imports a JSONL history file and renames it out of the way.
+func (s *Store) migrateLegacy(legacy string) error {
+	info, err := os.Stat(legacy)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+
+	entries, err := readJSONL(legacy)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Insert(entries...); err != nil {
+		return fmt.Errorf("failed to migrate history: %w", err)
+	}
+
+	backup := legacy + migratedExt
+	if _, err := os.Stat(backup); err == nil {
+		backup = fmt.Sprintf("%s.%s%s", legacy, time.Now().Format("20060102150405"), migratedExt)
+	}
+	if err := os.Rename(legacy, backup); err != nil {
+		return fmt.Errorf("failed to rename migrated history: %w", err)
+	}
+	// The backup holds the same history, so protect it like the database
+	if s.encrypted && !secret.IsEncryptedFile(backup) {
+		if err := convertFile(backup, true); err != nil {
+			return fmt.Errorf("failed to encrypt migrated history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the database, saving an encrypted database first.
+func (s *Store) Close() error {
+	defer s.lock.release()
+	if s.encrypted {
+		if err := s.flush(); err != nil {
+			s.db.Close()
+			return err
+		}
+	}
+	return s.db.Close()
+}
+
+// discard closes the database without saving it and releases its lock.
+func (s *Store) discard() {
+	s.db.Close()
+	s.lock.release()
+}
+
+// closeStore closes s and reports its error through err unless err is
+// already set. Writers use it because an encrypted store saves on Close.
+func closeStore(s *Store, err *error) {
+	if cerr := s.Close(); *err == nil {
+		*err = cerr
+	}
+}
+
+// Encrypted reports whether the database is encrypted at rest.
+func (s *Store) Encrypted() bool {
+	return s.encrypted
+}
+
+// Path returns the database file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Insert adds entries in a single transaction.
+func (s *Store) Insert(entries ...models.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO entries (timestamp, query, response, mode, model, backend_uuid, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		response, data, err := encodeEntry(e)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(e.Timestamp.UnixMilli(), e.Query, response, e.Mode, e.Model, e.BackendUUID, data); err != nil {
+			return fmt.Errorf("failed to write history entry: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// update replaces the entry with the given id.
+func (s *Store) update(id int64, e models.HistoryEntry) error {
+	response, data, err := encodeEntry(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`UPDATE entries SET timestamp = ?, query = ?, response = ?, mode = ?, model = ?, backend_uuid = ?, data = ? WHERE id = ?`,
+		e.Timestamp.UnixMilli(), e.Query, response, e.Mode, e.Model, e.BackendUUID, data, id)
+	if err != nil {
+		return fmt.Errorf("failed to update history entry: %w", err)
+	}
+	return nil
+}
+
+// encodeEntry splits an entry into its response and the JSON of the rest;
+// the response is kept only in its own column, indexed by FTS.
+func encodeEntry(e models.HistoryEntry) (response, data string, err error) {
+	response = e.Response
+	e.Response = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	return response, string(b), nil
+}
+
+// HasBackendUUID reports whether an entry with the given backend UUID exists.
+func (s *Store) HasBackendUUID(uuid string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM entries WHERE backend_uuid = ?`, uuid).Scan(&n)
+	return n > 0, err
+}
+
+// storedThread describes the newest entry recorded for a backend UUID.
+type storedThread struct {
+	id        int64
+	timestamp time.Time
+	source    string
+}
+
+// newestByBackendUUID returns the newest entry with the given backend UUID.
+func (s *Store) newestByBackendUUID(uuid string) (storedThread, bool, error) {
+	var st storedThread
+	var ts int64
+	var source sql.NullString
+	err := s.db.QueryRow(`SELECT id, timestamp, json_extract(data, '$.source') FROM entries
+		WHERE backend_uuid = ? ORDER BY timestamp DESC, id DESC LIMIT 1`, uuid).Scan(&st.id, &ts, &source)
+	if err == sql.ErrNoRows {
+		return st, false, nil
+	}
+	if err != nil {
+		return st, false, err
+	}
+	st.timestamp = time.UnixMilli(ts)
+	st.source = source.String
+	return st, true, nil
+}
+
+// Count returns the number of stored entries.
+func (s *Store) Count() (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM entries`).Scan(&n)
+	return n, err
+}
+
+// All returns every entry in insertion order.
+func (s *Store) All() ([]models.HistoryEntry, error) {
+	return s.query(`SELECT data, response FROM entries ORDER BY id`)
+}
+
+// Last returns the last n entries in insertion order.
+func (s *Store) Last(n int) ([]models.HistoryEntry, error) {
+	if n <= 0 {
+		return []models.HistoryEntry{}, nil
+	}
+	return s.query(`SELECT data, response FROM (SELECT id, data, response FROM entries ORDER BY id DESC LIMIT ?) ORDER BY id`, n)
+}
+
+// Search returns entries matching opts. Text matches are ranked by relevance
+// (query matches weigh more than answer matches); otherwise newest first.
+func (s *Store) Search(opts SearchOptions) ([]models.HistoryEntry, error) {
+	var where []string
+	var args []any
+
+	if !opts.Since.IsZero() {
+		where = append(where, "e.timestamp >= ?")
+		args = append(args, opts.Since.UnixMilli())
+	}
+	if !opts.Until.IsZero() {
+		where = append(where, "e.timestamp <= ?")
+		args = append(args, opts.Until.UnixMilli())
+	}
+	if opts.Mode != "" {
+		where = append(where, "e.mode = ? COLLATE NOCASE")
+		args = append(args, opts.Mode)
+	}
+	if opts.Model != "" {
+		where = append(where, "e.model = ? COLLATE NOCASE")
+		args = append(args, opts.Model)
+	}
+
+	text := strings.TrimSpace(opts.Text)
+	match := ftsQuery(text)
+
+	var q string
+	if match != "" {
+		where = append([]string{"entries_fts MATCH ?"}, where...)
+		args = append([]any{match}, args...)
+		q = `SELECT e.data, e.response FROM entries_fts JOIN entries e ON e.id = entries_fts.rowid WHERE ` +
+			strings.Join(where, " AND ") + ` ORDER BY bm25(entries_fts, 10.0, 1.0), e.id DESC`
+	} else {
+		q = `SELECT e.data, e.response FROM entries e`
+		if len(where) > 0 {
+			q += ` WHERE ` + strings.Join(where, " AND ")
+		}
+		q += ` ORDER BY e.id DESC`
+	}
+
+	// Text without indexable tokens (e.g. only symbols) falls back to a
+	// substring scan, so the limit is applied afterwards.
+	fallback := text != "" && match == ""
+	if opts.Limit > 0 && !fallback {
+		q += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	entries, err := s.query(q, args...)
+	if err != nil || !fallback {
+		return entries, err
+	}
+
+	var results []models.HistoryEntry
+	for _, e := range entries {
+		if containsIgnoreCase(e.Query, text) || containsIgnoreCase(e.Response, text) {
+			results = append(results, e)
+			if opts.Limit > 0 && len(results) >= opts.Limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// Clear removes all entries.
+func (s *Store) Clear() error {
+	if _, err := s.db.Exec(`DELETE FROM entries`); err != nil {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+	return nil
+}
+
+// query runs a statement selecting the data and response columns and
+// decodes the entries.
+func (s *Store) query(q string, args ...any) ([]models.HistoryEntry, error) {
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.HistoryEntry{}
+	for rows.Next() {
+		var data, response string
+		if err := rows.Scan(&data, &response); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		var entry models.HistoryEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			continue
+		}
+		entry.Response = response
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ftsQuery converts free text into an FTS5 query where every word must
+// match as a prefix. It returns "" if the text has no indexable words.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
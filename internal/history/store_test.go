@@ -0,0 +1,237 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestDBPath(t *testing.T) {
+	tests := []struct {
+		in, db, legacy string
+	}{
+		{"/h/history.jsonl", "/h/history.db", "/h/history.jsonl"},
+		{"/h/history.db", "/h/history.db", "/h/history.jsonl"},
+		{"/h/history", "/h/history.db", "/h/history"},
+		{"/h/pplx-history.log", "/h/pplx-history.log.db", "/h/pplx-history.log"},
+		{"/h/history.json", "/h/history.json.db", "/h/history.json"},
+	}
+
+	for _, tt := range tests {
+		if got := DBPath(tt.in); got != tt.db {
+			t.Errorf("DBPath(%q) = %q, want %q", tt.in, got, tt.db)
+		}
+		if got := legacyPath(tt.in); got != tt.legacy {
+			t.Errorf("legacyPath(%q) = %q, want %q", tt.in, got, tt.legacy)
+		}
+	}
+}
+
+func TestOpenStore_MigratesCustomLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pplx-history.log")
+	content := `{"timestamp":"2024-01-01T10:00:00Z","query":"first","mode":"fast"}
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Query != "first" {
+		t.Errorf("ReadAll() = %v, want the legacy entry", entries)
+	}
+	if _, err := os.Stat(path + ".db"); err != nil {
+		t.Errorf("database not created alongside the legacy file: %v", err)
+	}
+	if _, err := os.Stat(path + ".migrated"); err != nil {
+		t.Errorf("backup not created: %v", err)
+	}
+
+	// The history must still be found once the legacy file is gone.
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Append(models.HistoryEntry{Query: "second"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if entries, _ := NewReader(path).ReadAll(); len(entries) != 2 {
+		t.Errorf("ReadAll() after migration = %d entries, want 2", len(entries))
+	}
+}
+
+func TestOpenStore_MigratesJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"timestamp":"2024-01-01T10:00:00Z","query":"first","mode":"fast"}
+not json
+{"timestamp":"2024-01-02T10:00:00Z","query":"second","mode":"pro"}
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	if n, _ := store.Count(); n != 2 {
+		t.Errorf("Count() = %d, want 2", n)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("legacy JSONL file should have been renamed")
+	}
+	if _, err := os.Stat(path + ".migrated"); err != nil {
+		t.Errorf("backup not created: %v", err)
+	}
+
+	// Reopening must not import the entries again.
+	store.Close()
+	store, err = OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	if n, _ := store.Count(); n != 2 {
+		t.Errorf("Count() after reopen = %d, want 2", n)
+	}
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = store.Insert(
+		models.HistoryEntry{Timestamp: base, Query: "Café in Paris", Response: "Try the Marais.", Mode: "fast", Model: "turbo"},
+		models.HistoryEntry{Timestamp: base.AddDate(0, 1, 0), Query: "Go generics", Response: "Type parameters arrived in Go 1.18.", Mode: "pro", Model: "gpt51"},
+		models.HistoryEntry{Timestamp: base.AddDate(0, 2, 0), Query: "Rust vs Go", Response: "Both compile to native code; generics differ.", Mode: "pro", Model: "claude45sonnet"},
+		models.HistoryEntry{Timestamp: base.AddDate(0, 3, 0), Query: "ΑΘΗΝΑ weather", Response: "Sunny, C++ not needed.", Mode: "fast", Model: "turbo"},
+	)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return store
+}
+
+func queries(entries []models.HistoryEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Query
+	}
+	return out
+}
+
+func TestStoreSearch(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{"query match ranks first", SearchOptions{Text: "generics"}, []string{"Go generics", "Rust vs Go"}},
+		{"answer only", SearchOptions{Text: "marais"}, []string{"Café in Paris"}},
+		{"diacritics and case folded", SearchOptions{Text: "CAFE"}, []string{"Café in Paris"}},
+		{"unicode case folding", SearchOptions{Text: "αθηνα"}, []string{"ΑΘΗΝΑ weather"}},
+		{"prefix match", SearchOptions{Text: "gener"}, []string{"Go generics", "Rust vs Go"}},
+		{"all words required", SearchOptions{Text: "rust generics"}, []string{"Rust vs Go"}},
+		{"mode filter", SearchOptions{Text: "go", Mode: "PRO", Model: "gpt51"}, []string{"Go generics"}},
+		{"date range", SearchOptions{Since: base.AddDate(0, 1, 0), Until: base.AddDate(0, 2, 0)}, []string{"Rust vs Go", "Go generics"}},
+		{"limit", SearchOptions{Limit: 1}, []string{"ΑΘΗΝΑ weather"}},
+		{"symbol fallback", SearchOptions{Text: "++"}, []string{"ΑΘΗΝΑ weather"}},
+		{"no match", SearchOptions{Text: "python"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Search(tt.opts)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			gotQueries := queries(got)
+			if len(gotQueries) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", gotQueries, tt.want)
+			}
+			for i := range tt.want {
+				if gotQueries[i] != tt.want[i] {
+					t.Fatalf("Search() = %v, want %v", gotQueries, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreLastAndClear(t *testing.T) {
+	store := newTestStore(t)
+
+	last, err := store.Last(2)
+	if err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+	if got := queries(last); len(got) != 2 || got[0] != "Rust vs Go" || got[1] != "ΑΘΗΝΑ weather" {
+		t.Errorf("Last(2) = %v", got)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if n, _ := store.Count(); n != 0 {
+		t.Errorf("Count() after Clear = %d, want 0", n)
+	}
+	if got, _ := store.Search(SearchOptions{Text: "generics"}); len(got) != 0 {
+		t.Errorf("index not cleared: %v", queries(got))
+	}
+}
+
+func TestStore_FullResponseRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	long := strings.Repeat("Résumé de la réponse. ", 2000)
+	entry := models.HistoryEntry{
+		Query:      "long answer",
+		Response:   long,
+		WebResults: []models.WebResult{{Title: "Source", URL: "https://example.com"}},
+		Status:     models.HistoryStatusOK,
+		DurationMS: 1234,
+	}
+	if err := w.Append(entry); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ReadAll() = %d entries, err %v", len(entries), err)
+	}
+
+	got := entries[0]
+	if got.Response != long {
+		t.Errorf("Response length = %d, want %d", len(got.Response), len(long))
+	}
+	if got.Version != models.HistorySchemaVersion {
+		t.Errorf("Version = %d, want %d", got.Version, models.HistorySchemaVersion)
+	}
+	if len(got.WebResults) != 1 || got.DurationMS != 1234 || got.Status != models.HistoryStatusOK {
+		t.Errorf("entry = %+v", got)
+	}
+
+	found, err := NewReader(path).Search("réponse")
+	if err != nil || len(found) != 1 {
+		t.Errorf("Search() = %d entries, err %v", len(found), err)
+	}
+}
@@ -0,0 +1,132 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+const syncStateFileName = "library_sync.json"
+
+// SyncState records the progress of library synchronization.
+type SyncState struct {
+	// Cursor is the timestamp of the newest thread already synced.
+	Cursor   time.Time `json:"cursor,omitzero"`
+	LastSync time.Time `json:"last_sync,omitzero"`
+}
+
+// SyncStatePath returns the sync state file stored alongside the history file.
+func SyncStatePath(historyFile string) string {
+	return filepath.Join(filepath.Dir(historyFile), syncStateFileName)
+}
+
+// LoadSyncState reads the sync state, returning an empty state if none exists.
+func LoadSyncState(path string) (SyncState, error) {
+	var state SyncState
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse sync state: %w", err)
+	}
+
+	return state, nil
+}
+
+// SaveSyncState writes the sync state to path.
+func SaveSyncState(path string, state SyncState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+
+	return nil
+}
+
+// MergeResult describes the outcome of a Merge.
+type MergeResult struct {
+	Added int
+	// Updated counts library entries refreshed because their thread has a
+	// newer last query (e.g. after a follow-up).
+	Updated int
+	// Undated counts added entries without a timestamp, which were recorded
+	// with the time of the merge.
+	Undated int
+}
+
+// Merge adds entries whose backend UUID is not already in the history,
+// oldest first. Entries previously synced from the library are replaced
+// when the new entry is more recent; entries recorded locally are never
+// touched.
+func (w *Writer) Merge(entries []models.HistoryEntry) (result MergeResult, err error) {
+	store, err := OpenStore(w.path)
+	if err != nil {
+		return result, err
+	}
+	defer closeStore(store, &err)
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var fresh []models.HistoryEntry
+	for _, e := range entries {
+		if e.BackendUUID != "" {
+			if seen[e.BackendUUID] {
+				continue
+			}
+			seen[e.BackendUUID] = true
+
+			stored, exists, err := store.newestByBackendUUID(e.BackendUUID)
+			if err != nil {
+				return result, fmt.Errorf("failed to check history: %w", err)
+			}
+			if exists {
+				if stored.source == models.HistorySourceLibrary && e.Timestamp.After(stored.timestamp) {
+					if err := store.update(stored.id, e); err != nil {
+						return result, err
+					}
+					result.Updated++
+				}
+				continue
+			}
+		}
+		if e.Timestamp.IsZero() {
+			e.Timestamp = now
+			result.Undated++
+		}
+		fresh = append(fresh, e)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Timestamp.Before(fresh[j].Timestamp)
+	})
+
+	if err := store.Insert(fresh...); err != nil {
+		return result, err
+	}
+	result.Added = len(fresh)
+
+	if _, err := store.Prune(w.retention, now, false); err != nil {
+		return result, fmt.Errorf("failed to apply history retention: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestSyncState_RoundTrip(t *testing.T) {
+	path := SyncStatePath(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	state, err := LoadSyncState(path)
+	if err != nil {
+		t.Fatalf("LoadSyncState() error = %v", err)
+	}
+	if !state.Cursor.IsZero() {
+		t.Errorf("expected empty state, got %+v", state)
+	}
+
+	want := SyncState{
+		Cursor:   time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		LastSync: time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	if err := SaveSyncState(path, want); err != nil {
+		t.Fatalf("SaveSyncState() error = %v", err)
+	}
+
+	got, err := LoadSyncState(path)
+	if err != nil {
+		t.Fatalf("LoadSyncState() error = %v", err)
+	}
+	if !got.Cursor.Equal(want.Cursor) || !got.LastSync.Equal(want.LastSync) {
+		t.Errorf("LoadSyncState() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriterMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := w.Append(models.HistoryEntry{Query: "local", BackendUUID: "a", Timestamp: base}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	result, err := w.Merge([]models.HistoryEntry{
+		{Query: "newer", BackendUUID: "c", Timestamp: base.Add(2 * time.Hour)},
+		{Query: "duplicate", BackendUUID: "a", Timestamp: base},
+		{Query: "older", BackendUUID: "b", Timestamp: base.Add(time.Hour)},
+		{Query: "repeated", BackendUUID: "c", Timestamp: base.Add(2 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if result.Added != 2 || result.Updated != 0 {
+		t.Errorf("Merge() = %+v, want 2 added", result)
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	var queries []string
+	for _, e := range entries {
+		queries = append(queries, e.Query)
+	}
+	want := []string{"local", "older", "newer"}
+	if len(queries) != len(want) {
+		t.Fatalf("queries = %v, want %v", queries, want)
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("queries = %v, want %v", queries, want)
+			break
+		}
+	}
+
+	result, err = w.Merge([]models.HistoryEntry{{Query: "newer", BackendUUID: "c"}})
+	if err != nil || result.Added != 0 {
+		t.Errorf("second Merge() = %+v, %v; want nothing added", result, err)
+	}
+}
+
+func TestWriterMerge_RefreshesLibraryThreads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := w.Append(models.HistoryEntry{Query: "local", BackendUUID: "a", Timestamp: base}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Merge([]models.HistoryEntry{{Query: "synced", BackendUUID: "b", Timestamp: base, Source: models.HistorySourceLibrary}}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := w.Merge([]models.HistoryEntry{
+		{Query: "local follow-up", BackendUUID: "a", Timestamp: base.Add(time.Hour), Source: models.HistorySourceLibrary},
+		{Query: "synced follow-up", BackendUUID: "b", Timestamp: base.Add(time.Hour), Source: models.HistorySourceLibrary},
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if result.Added != 0 || result.Updated != 1 {
+		t.Errorf("Merge() = %+v, want 1 updated", result)
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ReadAll() = %d entries, %v", len(entries), err)
+	}
+	if entries[0].Query != "local" || entries[1].Query != "synced follow-up" || !entries[1].Timestamp.Equal(base.Add(time.Hour)) {
+		t.Errorf("entries = %+v, want the local entry kept and the synced one refreshed", entries)
+	}
+	if found, _ := NewReader(path).Search("follow-up"); len(found) != 1 {
+		t.Errorf("Search() = %d entries, want the refreshed entry indexed", len(found))
+	}
+}
+
+func TestWriterMerge_Undated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+
+	before := time.Now()
+	result, err := w.Merge([]models.HistoryEntry{{Query: "undated", BackendUUID: "a", Source: models.HistorySourceLibrary}})
+	if err != nil || result.Added != 1 || result.Undated != 1 {
+		t.Fatalf("Merge() = %+v, %v; want 1 undated entry added", result, err)
+	}
+
+	entries, err := NewReader(path).ReadAll()
+	if err != nil || len(entries) != 1 || entries[0].Timestamp.Before(before.Truncate(time.Millisecond)) {
+		t.Errorf("ReadAll() = %+v, %v; want the merge time", entries, err)
+	}
+
+	result, err = w.Merge([]models.HistoryEntry{{Query: "undated", BackendUUID: "a", Source: models.HistorySourceLibrary}})
+	if err != nil || result.Added != 0 || result.Updated != 0 {
+		t.Errorf("second Merge() = %+v, %v; want the undated thread left alone", result, err)
+	}
+}
@@ -0,0 +1,386 @@
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/export"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// ExportFormat represents a history export format.
+type ExportFormat string
+
+const (
+	ExportJSONL    ExportFormat = "jsonl"
+	ExportCSV      ExportFormat = "csv"
+	ExportMarkdown ExportFormat = "markdown"
+	ExportHTML     ExportFormat = "html"
+)
+
+// csvHeader lists the CSV export columns. attachments and filters hold
+// JSON; source_urls keeps only the web result URLs, so CSV drops their
+// titles and snippets along with the search sources. Use JSONL for a
+// lossless export.
+var csvHeader = []string{"timestamp", "query", "mode", "model", "language", "status", "error", "duration_ms", "backend_uuid", "title", "group", "attachments", "filters", "source_urls", "response"}
+
+// ParseExportFormat converts a format name (or common alias) to an ExportFormat.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "jsonl", "ndjson":
+		return ExportJSONL, nil
+	case "csv":
+		return ExportCSV, nil
+	case "markdown", "md":
+		return ExportMarkdown, nil
+	case "html", "htm":
+		return ExportHTML, nil
+	}
+	return "", fmt.Errorf("invalid export format: %s (valid: jsonl, csv, markdown, html)", name)
+}
+
+// Select returns entries from the store and archives matching the filters
+// in opts (Text and Limit are ignored), oldest first.
+func (r *Reader) Select(opts SearchOptions) ([]models.HistoryEntry, error) {
+	opts.Text = ""
+	opts.Limit = 0
+
+	entries, err := r.SearchWith(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// Export writes entries as JSONL, CSV or a single HTML page. Markdown
+// exports one file per entry; use ExportMarkdownDir.
+func Export(w io.Writer, entries []models.HistoryEntry, format ExportFormat) error {
+	switch format {
+	case ExportJSONL:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("failed to write entry: %w", err)
+			}
+		}
+		return nil
+	case ExportCSV:
+		return writeCSV(w, entries)
+	case ExportHTML:
+		docs := make([]export.Document, len(entries))
+		for i, e := range entries {
+			docs[i] = Document(e)
+		}
+		return export.WriteHTMLCollection(w, "Perplexity History", docs)
+	case ExportMarkdown:
+		return fmt.Errorf("markdown export writes one file per entry and needs an output directory")
+	}
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+// ExportMarkdownDir writes one markdown file with YAML front matter per
+// entry into dir (e.g. an Obsidian vault folder) and returns the file count.
+func ExportMarkdownDir(dir string, entries []models.HistoryEntry) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	used := make(map[string]int)
+	for i, e := range entries {
+		doc := Document(e)
+		base := export.FileName(doc, ".md")
+		used[base]++
+
+		name := base
+		if n := used[base]; n > 1 {
+			name = strings.TrimSuffix(base, ".md") + "-" + strconv.Itoa(n) + ".md"
+		}
+
+		if err := export.WriteFile(filepath.Join(dir, name), doc, export.FormatMarkdown); err != nil {
+			return i, err
+		}
+	}
+
+	return len(entries), nil
+}
+
+// Document converts a history entry into an export document.
+func Document(e models.HistoryEntry) export.Document {
+	return export.Document{
+		Query:       e.Query,
+		Answer:      e.Response,
+		Mode:        e.Mode,
+		Model:       e.Model,
+		Language:    e.Language,
+		BackendUUID: e.BackendUUID,
+		Date:        e.Timestamp,
+		Sources:     e.WebResults,
+	}
+}
+
+// writeCSV writes entries as CSV with a header row.
+func writeCSV(w io.Writer, entries []models.HistoryEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		urls := make([]string, 0, len(e.WebResults))
+		for _, wr := range e.WebResults {
+			if wr.URL != "" {
+				urls = append(urls, wr.URL)
+			}
+		}
+
+		attachments, err := csvJSON(e.Attachments, len(e.Attachments) > 0)
+		if err != nil {
+			return err
+		}
+		filters, err := csvJSON(e.Filters, e.Filters != nil)
+		if err != nil {
+			return err
+		}
+
+		record := []string{
+			e.Timestamp.Format(time.RFC3339Nano),
+			e.Query,
+			e.Mode,
+			e.Model,
+			e.Language,
+			e.Status,
+			e.Error,
+			strconv.FormatInt(e.DurationMS, 10),
+			e.BackendUUID,
+			e.Title,
+			e.Group,
+			attachments,
+			filters,
+			strings.Join(urls, " "),
+			e.Response,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvJSON encodes v for a CSV cell, or returns "" when set is false.
+func csvJSON(v any, set bool) (string, error) {
+	if !set {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode CSV field: %w", err)
+	}
+	return string(data), nil
+}
+
+// ReadImportFile reads entries from a JSONL (optionally gzip-compressed,
+// as in history archives) or CSV export.
+func ReadImportFile(path string) ([]models.HistoryEntry, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		return readCSV(file)
+	case strings.HasSuffix(lower, ".gz"):
+		return readArchive(path)
+	default:
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to open import file: %w", err)
+		}
+		return readJSONL(path)
+	}
+}
+
+// readCSV parses a CSV export, matching columns by header name. Exports
+// from older versions named the source_urls column sources.
+func readCSV(r io.Reader) ([]models.HistoryEntry, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	if _, ok := col["query"]; !ok {
+		return nil, fmt.Errorf("CSV has no query column")
+	}
+
+	var entries []models.HistoryEntry
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		entry := models.HistoryEntry{
+			Query:       get("query"),
+			Mode:        get("mode"),
+			Model:       get("model"),
+			Language:    get("language"),
+			Status:      get("status"),
+			Error:       get("error"),
+			BackendUUID: get("backend_uuid"),
+			Title:       get("title"),
+			Group:       get("group"),
+			Response:    get("response"),
+		}
+		if ts := get("timestamp"); ts != "" {
+			entry.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+		}
+		entry.DurationMS, _ = strconv.ParseInt(get("duration_ms"), 10, 64)
+		if v := get("attachments"); v != "" {
+			if err := json.Unmarshal([]byte(v), &entry.Attachments); err != nil {
+				return nil, fmt.Errorf("invalid attachments in CSV: %w", err)
+			}
+		}
+		if v := get("filters"); v != "" {
+			if err := json.Unmarshal([]byte(v), &entry.Filters); err != nil {
+				return nil, fmt.Errorf("invalid filters in CSV: %w", err)
+			}
+		}
+		urls := get("source_urls")
+		if _, ok := col["source_urls"]; !ok {
+			urls = get("sources")
+		}
+		for _, u := range strings.Fields(urls) {
+			entry.WebResults = append(entry.WebResults, models.WebResult{URL: u})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// entryKey identifies an entry for deduplication: its timestamp (to the
+// millisecond), query and backend UUID.
+type entryKey struct {
+	ts          int64
+	query, uuid string
+}
+
+func keyOf(e models.HistoryEntry) entryKey {
+	return entryKey{e.Timestamp.UnixMilli(), e.Query, e.BackendUUID}
+}
+
+// Import merges entries into the history, skipping entries already present
+// in the store or its archives (same timestamp, query and backend UUID),
+// and returns the number added. Entries without a schema version get the
+// current one. The writer's retention policy is applied afterwards.
+func (w *Writer) Import(entries []models.HistoryEntry) (n int, err error) {
+	store, err := OpenStore(w.path)
+	if err != nil {
+		return 0, err
+	}
+	defer closeStore(store, &err)
+
+	seen := make(map[entryKey]bool)
+	archived := make(map[string]map[entryKey]bool)
+
+	var fresh []models.HistoryEntry
+	for _, e := range entries {
+		k := keyOf(e)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		month := e.Timestamp.UTC().Format(archiveMonth)
+		if _, ok := archived[month]; !ok {
+			if archived[month], err = archiveKeys(filepath.Join(store.archiveDir(), month+archiveExt)); err != nil {
+				return 0, err
+			}
+		}
+		if archived[month][k] {
+			continue
+		}
+
+		exists, err := store.HasEntry(e)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check history: %w", err)
+		}
+		if !exists {
+			if e.Version == 0 {
+				e.Version = models.HistorySchemaVersion
+			}
+			fresh = append(fresh, e)
+		}
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Timestamp.Before(fresh[j].Timestamp)
+	})
+
+	if err := store.Insert(fresh...); err != nil {
+		return 0, err
+	}
+
+	if _, err := store.Prune(w.retention, time.Now(), false); err != nil {
+		return len(fresh), fmt.Errorf("failed to apply history retention: %w", err)
+	}
+	return len(fresh), nil
+}
+
+// archiveKeys returns the keys of the entries in an archive, or none if the
+// archive doesn't exist.
+func archiveKeys(path string) (map[entryKey]bool, error) {
+	keys := make(map[entryKey]bool)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return keys, nil
+	}
+
+	entries, err := readArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		keys[keyOf(e)] = true
+	}
+	return keys, nil
+}
+
+// HasEntry reports whether an entry with the same timestamp (to the
+// millisecond), query and backend UUID is stored.
+func (s *Store) HasEntry(e models.HistoryEntry) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM entries WHERE timestamp = ? AND query = ? AND backend_uuid = ?`,
+		e.Timestamp.UnixMilli(), e.Query, e.BackendUUID).Scan(&n)
+	return n > 0, err
+}
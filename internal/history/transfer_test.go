@@ -0,0 +1,280 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func transferEntries() []models.HistoryEntry {
+	base := time.Date(2025, 1, 2, 15, 4, 0, 0, time.UTC)
+	return []models.HistoryEntry{
+		{
+			Timestamp:   base,
+			Query:       "What is Go?",
+			Mode:        "pro",
+			Model:       "gpt51",
+			Response:    "Go is a language [1].\nIt has \"generics\", too.",
+			WebResults:  []models.WebResult{{Title: "Go", URL: "https://go.dev"}},
+			BackendUUID: "uuid-1",
+			Status:      models.HistoryStatusOK,
+			DurationMS:  1200,
+			Attachments: []string{"/tmp/notes, draft.txt"},
+			Filters:     &models.SearchFilters{Sites: []string{"go.dev"}, Since: base.AddDate(0, -1, 0)},
+			Group:       "golang",
+		},
+		{
+			Timestamp: base.Add(time.Hour),
+			Query:     "Café, naïve résumé",
+			Mode:      "fast",
+			Response:  "Unicode, commas",
+		},
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	for name, want := range map[string]ExportFormat{"jsonl": ExportJSONL, "CSV": ExportCSV, "md": ExportMarkdown, "html": ExportHTML} {
+		got, err := ParseExportFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseExportFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseExportFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		format ExportFormat
+		file   string
+	}{
+		{ExportJSONL, "history.jsonl"},
+		{ExportCSV, "history.csv"},
+	} {
+		t.Run(string(tt.format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+
+			var buf bytes.Buffer
+			if err := Export(&buf, transferEntries(), tt.format); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadImportFile(path)
+			if err != nil {
+				t.Fatalf("ReadImportFile() error = %v", err)
+			}
+			want := transferEntries()
+			if len(got) != len(want) {
+				t.Fatalf("len = %d, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].Query != want[i].Query || got[i].Response != want[i].Response ||
+					!got[i].Timestamp.Equal(want[i].Timestamp) || got[i].BackendUUID != want[i].BackendUUID {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+			if len(got[0].WebResults) != 1 || got[0].WebResults[0].URL != "https://go.dev" || got[0].DurationMS != 1200 {
+				t.Errorf("entry 0 = %+v", got[0])
+			}
+			if !reflect.DeepEqual(got[0].Attachments, want[0].Attachments) || got[0].Group != want[0].Group ||
+				got[0].Filters == nil || !reflect.DeepEqual(got[0].Filters.Sites, want[0].Filters.Sites) || !got[0].Filters.Since.Equal(want[0].Filters.Since) {
+				t.Errorf("entry 0 attachments, filters or group lost: %+v", got[0])
+			}
+			if got[1].Filters != nil || got[1].Attachments != nil {
+				t.Errorf("entry 1 = %+v, want no attachments or filters", got[1])
+			}
+		})
+	}
+}
+
+func TestReadCSV_LegacySourcesColumn(t *testing.T) {
+	in := "timestamp,query,sources\n2025-01-02T15:04:00Z,What is Go?,https://go.dev https://go.dev/doc\n"
+
+	got, err := readCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("readCSV() error = %v", err)
+	}
+	if len(got) != 1 || len(got[0].WebResults) != 2 || got[0].WebResults[1].URL != "https://go.dev/doc" {
+		t.Errorf("readCSV() = %+v, want the sources column read as URLs", got)
+	}
+}
+
+func TestExport_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, transferEntries(), ExportHTML); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "What is Go?", "Café, naïve résumé", `href="https://go.dev"`, `id="entry-1"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML should contain %q", want)
+		}
+	}
+
+	if err := Export(&buf, nil, ExportMarkdown); err == nil {
+		t.Error("expected error for markdown to a single stream")
+	}
+}
+
+func TestExportMarkdownDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vault")
+	entries := append(transferEntries(), transferEntries()[0])
+
+	n, err := ExportMarkdownDir(dir, entries)
+	if err != nil || n != 3 {
+		t.Fatalf("ExportMarkdownDir() = %d, %v", n, err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+	if len(files) != 3 {
+		t.Fatalf("files = %v, want 3 distinct files", files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "2025-01-02-1504-what-is-go.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "---\nquery: \"What is Go?\"\n") || !strings.Contains(content, "model: \"gpt51\"") {
+		t.Errorf("front matter missing:\n%s", content)
+	}
+	if !strings.Contains(content, "[\\[1\\]](https://go.dev)") {
+		t.Errorf("citations not resolved:\n%s", content)
+	}
+}
+
+func TestWriterImport_Dedup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	entries := transferEntries()
+	if err := w.Append(entries[0]); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// Same query at another time is a distinct entry.
+	other := entries[0]
+	other.Timestamp = other.Timestamp.Add(24 * time.Hour)
+
+	added, err := w.Import(append(entries, entries[1], other))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if added != 2 {
+		t.Errorf("Import() added = %d, want 2", added)
+	}
+
+	added, err = w.Import(entries)
+	if err != nil || added != 0 {
+		t.Errorf("second Import() = %d, %v; want 0", added, err)
+	}
+}
+
+func TestWriterImport_SkipsArchived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+	entries := transferEntries()
+	for _, e := range entries {
+		if err := w.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := store.Prune(Retention{MaxAge: "30d"}, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), false)
+	store.Close()
+	if err != nil || result.Archived != 2 {
+		t.Fatalf("Prune() = %+v, %v; want both entries archived", result, err)
+	}
+
+	exported, err := NewReader(path).Select(SearchOptions{})
+	if err != nil || len(exported) != 2 {
+		t.Fatalf("Select() = %d entries, %v", len(exported), err)
+	}
+	added, err := w.Import(exported)
+	if err != nil || added != 0 {
+		t.Errorf("Import() of archived entries = %d, %v; want 0", added, err)
+	}
+}
+
+func TestWriterImport_SetsVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+
+	entries := transferEntries()
+	entries[1].Version = 1
+	if _, err := w.Import(entries); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	stored, err := NewReader(path).ReadAll()
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("ReadAll() = %d entries, %v", len(stored), err)
+	}
+	versions := map[string]int{}
+	for _, e := range stored {
+		versions[e.Query] = e.Version
+	}
+	if versions["What is Go?"] != models.HistorySchemaVersion {
+		t.Errorf("Version = %d, want the current schema version", versions["What is Go?"])
+	}
+	if versions["Café, naïve résumé"] != 1 {
+		t.Errorf("Version = %d, want the imported version kept", versions["Café, naïve résumé"])
+	}
+}
+
+func TestWriterImport_AppliesRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+	w.SetRetention(Retention{MaxEntries: 1})
+
+	if _, err := w.Import(transferEntries()); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	stored, err := NewReader(path).ReadAll()
+	if err != nil || len(stored) != 1 || stored[0].Query != "Café, naïve résumé" {
+		t.Fatalf("ReadAll() = %v, %v; want only the newest entry kept", queries(stored), err)
+	}
+	if found, err := NewReader(path).Search("What is Go"); err != nil || len(found) != 1 {
+		t.Errorf("archived entry not searchable: %d, %v", len(found), err)
+	}
+}
+
+func TestReaderSelect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	w, _ := NewWriter(path)
+	entries := transferEntries()
+	// Insert newest first to check chronological ordering.
+	w.Append(entries[1])
+	w.Append(entries[0])
+
+	got, err := NewReader(path).Select(SearchOptions{Text: "ignored", Limit: 1})
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Query != "What is Go?" {
+		t.Errorf("Select() = %v", queries(got))
+	}
+
+	got, _ = NewReader(path).Select(SearchOptions{Mode: "fast"})
+	if len(got) != 1 || got[0].Mode != "fast" {
+		t.Errorf("Select(mode) = %v", queries(got))
+	}
+}
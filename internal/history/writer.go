@@ -7,14 +7,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/diogo/perplexity-go/pkg/models"
 )
 
+// maxLineSize bounds a single JSONL history line (full responses can be large).
+const maxLineSize = 16 * 1024 * 1024
+
 // Writer handles writing history entries.
 type Writer struct {
-	path string
+	path      string
+	retention Retention
 }
 
 // NewWriter creates a new history writer.
@@ -28,29 +33,28 @@ func NewWriter(path string) (*Writer, error) {
 	return &Writer{path: path}, nil
 }
 
-// Append adds a new entry to the history file.
-func (w *Writer) Append(entry models.HistoryEntry) error {
-	// Open file in append mode
-	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+// Append adds a new entry to the history store.
+func (w *Writer) Append(entry models.HistoryEntry) (err error) {
+	store, err := OpenStore(w.path)
 	if err != nil {
-		return fmt.Errorf("failed to open history file: %w", err)
+		return err
 	}
-	defer file.Close()
+	defer closeStore(store, &err)
 
 	// Set timestamp if not set
 	if entry.Timestamp.IsZero() {
 		entry.Timestamp = time.Now()
 	}
+	if entry.Version == 0 {
+		entry.Version = models.HistorySchemaVersion
+	}
 
-	// Marshal to JSON
-	data, err := json.Marshal(entry)
-	if err != nil {
-		return fmt.Errorf("failed to marshal history entry: %w", err)
+	if err := store.Insert(entry); err != nil {
+		return err
 	}
 
-	// Write with newline
-	if _, err := file.Write(append(data, '\n')); err != nil {
-		return fmt.Errorf("failed to write history entry: %w", err)
+	if _, err := store.Prune(w.retention, time.Now(), false); err != nil {
+		return fmt.Errorf("failed to apply history retention: %w", err)
 	}
 
 	return nil
@@ -66,9 +70,94 @@ func NewReader(path string) *Reader {
 	return &Reader{path: path}
 }
 
+// open opens the history store, reporting whether any history exists yet.
+func (r *Reader) open() (*Store, bool, error) {
+	if _, err := os.Stat(DBPath(r.path)); os.IsNotExist(err) {
+		if _, err := os.Stat(legacyPath(r.path)); os.IsNotExist(err) {
+			return nil, false, nil
+		}
+	}
+
+	store, err := OpenStore(r.path)
+	if err != nil {
+		return nil, false, err
+	}
+	return store, true, nil
+}
+
 // ReadAll reads all history entries.
 func (r *Reader) ReadAll() ([]models.HistoryEntry, error) {
-	file, err := os.Open(r.path)
+	store, ok, err := r.open()
+	if err != nil || !ok {
+		return []models.HistoryEntry{}, err
+	}
+	defer store.Close()
+
+	return store.All()
+}
+
+// ReadLast reads the last n entries.
+func (r *Reader) ReadLast(n int) ([]models.HistoryEntry, error) {
+	store, ok, err := r.open()
+	if err != nil || !ok {
+		return []models.HistoryEntry{}, err
+	}
+	defer store.Close()
+
+	return store.Last(n)
+}
+
+// Clear removes all history entries.
+func (r *Reader) Clear() (err error) {
+	store, err := OpenStore(r.path)
+	if err != nil {
+		return err
+	}
+	defer closeStore(store, &err)
+
+	return store.Clear()
+}
+
+// Search finds entries whose query or answer matches the text, ranked by relevance.
+func (r *Reader) Search(query string) ([]models.HistoryEntry, error) {
+	return r.SearchWith(SearchOptions{Text: query})
+}
+
+// SearchWith finds entries matching the given options. Archived entries are
+// searched too and listed after the ranked results from the store.
+func (r *Reader) SearchWith(opts SearchOptions) ([]models.HistoryEntry, error) {
+	results := []models.HistoryEntry{}
+
+	store, ok, err := r.open()
+	if err != nil {
+		return results, err
+	}
+	if ok {
+		results, err = store.Search(opts)
+		store.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := 0
+	if opts.Limit > 0 {
+		limit = opts.Limit - len(results)
+		if limit <= 0 {
+			return results, nil
+		}
+	}
+
+	archived, err := searchArchives(ArchiveDir(r.path), opts, limit)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, archived...), nil
+}
+
+// readJSONL reads entries from a JSONL history file, skipping malformed lines.
+func readJSONL(path string) ([]models.HistoryEntry, error) {
+	file, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []models.HistoryEntry{}, nil
@@ -79,6 +168,7 @@ func (r *Reader) ReadAll() ([]models.HistoryEntry, error) {
 
 	var entries []models.HistoryEntry
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -101,66 +191,7 @@ func (r *Reader) ReadAll() ([]models.HistoryEntry, error) {
 	return entries, nil
 }
 
-// ReadLast reads the last n entries.
-func (r *Reader) ReadLast(n int) ([]models.HistoryEntry, error) {
-	entries, err := r.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	if len(entries) <= n {
-		return entries, nil
-	}
-
-	return entries[len(entries)-n:], nil
-}
-
-// Clear removes all history entries.
-func (r *Reader) Clear() error {
-	// Truncate the file
-	return os.Truncate(r.path, 0)
-}
-
-// Search finds entries matching the query.
-func (r *Reader) Search(query string) ([]models.HistoryEntry, error) {
-	entries, err := r.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	var results []models.HistoryEntry
-	for _, entry := range entries {
-		if containsIgnoreCase(entry.Query, query) {
-			results = append(results, entry)
-		}
-	}
-
-	return results, nil
-}
-
-// containsIgnoreCase checks if s contains substr (case-insensitive).
+// containsIgnoreCase checks if s contains substr (Unicode case-insensitive).
 func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr || containsLower(toLower(s), toLower(substr)))
-}
-
-func toLower(s string) string {
-	b := make([]byte, len(s))
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c >= 'A' && c <= 'Z' {
-			c += 'a' - 'A'
-		}
-		b[i] = c
-	}
-	return string(b)
-}
-
-func containsLower(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
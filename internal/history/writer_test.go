@@ -49,9 +49,9 @@ func TestWriterAppend(t *testing.T) {
 		t.Fatalf("Append() error = %v", err)
 	}
 
-	// Verify file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		t.Error("History file was not created")
+	// Verify database exists
+	if _, err := os.Stat(DBPath(path)); os.IsNotExist(err) {
+		t.Error("History database was not created")
 	}
 
 	// Read and verify
@@ -0,0 +1,64 @@
+// Package prompt parses prompt files with YAML front matter and renders
+// reusable prompt templates.
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const frontMatterDelim = "---"
+
+// SplitFrontMatter separates a leading YAML front matter block, delimited by
+// "---" lines, from the body. It reports whether a block was found; without
+// one the whole input is the body.
+func SplitFrontMatter(data []byte) (front, body []byte, ok bool) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	rest, found := cutLine(data, frontMatterDelim)
+	if !found {
+		return nil, data, false
+	}
+
+	for offset := 0; offset < len(rest); {
+		line, _, more := bytes.Cut(rest[offset:], []byte("\n"))
+		end := offset + len(line)
+		if more {
+			end++
+		}
+		if string(bytes.TrimRight(line, " \t\r")) == frontMatterDelim {
+			return rest[:offset], rest[end:], true
+		}
+		offset = end
+	}
+
+	// An opening delimiter without a closing one is not front matter
+	return nil, data, false
+}
+
+// cutLine returns data after its first line if that line equals want,
+// ignoring trailing whitespace.
+func cutLine(data []byte, want string) ([]byte, bool) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	if string(bytes.TrimRight(line, " \t\r")) != want {
+		return nil, false
+	}
+	return rest, true
+}
+
+// ParseFrontMatter splits data and decodes its front matter into v, rejecting
+// unknown fields. It returns the body.
+func ParseFrontMatter(data []byte, v any) ([]byte, error) {
+	front, body, ok := SplitFrontMatter(data)
+	if !ok || len(bytes.TrimSpace(front)) == 0 {
+		return body, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(front))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,54 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantFront string
+		wantBody  string
+		wantOK    bool
+	}{
+		{"none", "just a query\n", "", "just a query\n", false},
+		{"block", "---\nmode: pro\n---\nbody\n", "mode: pro\n", "body\n", true},
+		{"crlf", "---\r\nmode: pro\r\n---\r\nbody", "mode: pro\r\n", "body", true},
+		{"bom", "\ufeff---\nmode: pro\n---\nbody", "mode: pro\n", "body", true},
+		{"empty block", "---\n---\nbody", "", "body", true},
+		{"unclosed", "---\nmode: pro\nbody", "", "---\nmode: pro\nbody", false},
+		{"closing at eof", "---\nmode: pro\n---", "mode: pro\n", "", true},
+		{"delimiter later", "intro\n---\nmore", "", "intro\n---\nmore", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front, body, ok := SplitFrontMatter([]byte(tt.data))
+			if ok != tt.wantOK || string(front) != tt.wantFront || string(body) != tt.wantBody {
+				t.Errorf("SplitFrontMatter() = (%q, %q, %v), want (%q, %q, %v)",
+					front, body, ok, tt.wantFront, tt.wantBody, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	var opts Options
+	body, err := ParseFrontMatter([]byte("---\nmode: pro\nsources: [web, scholar]\n---\nquery"), &opts)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	if string(body) != "query" {
+		t.Errorf("body = %q, want %q", body, "query")
+	}
+	if opts.Mode != "pro" || len(opts.Sources) != 2 || opts.Sources[1] != "scholar" {
+		t.Errorf("opts = %+v", opts)
+	}
+
+	_, err = ParseFrontMatter([]byte("---\nmodle: gpt51\n---\nquery"), &opts)
+	if err == nil || !strings.Contains(err.Error(), "modle") {
+		t.Errorf("ParseFrontMatter() error = %v, want unknown field modle", err)
+	}
+}
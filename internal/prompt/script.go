@@ -0,0 +1,136 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TurnDelim is the line that separates the turns of a conversation script.
+const TurnDelim = "+++"
+
+// Script is a conversation sent as a series of follow-ups in one thread.
+// Front matter at the top of the file sets the title and the options of
+// every turn; its attachments go with the first turn.
+type Script struct {
+	Path  string `yaml:"-"`
+	Title string `yaml:"title"`
+	Turns []Turn `yaml:"-"`
+
+	Options `yaml:",inline"`
+}
+
+// Turn is one query of a script. Its front matter overrides the script's
+// search options for that turn.
+type Turn struct {
+	Query   string
+	Options Options
+}
+
+// LoadScript reads and parses a conversation script, resolving attachments
+// relative to the script's directory.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	s, err := ParseScript(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", path, err)
+	}
+	s.Path = path
+	return s, nil
+}
+
+// ParseScript parses a conversation script: optional front matter, then
+// turns separated by "+++" lines, each with optional front matter of its
+// own.
+func ParseScript(data []byte, dir string) (*Script, error) {
+	s := &Script{}
+	body, err := ParseFrontMatter(data, s)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ResolvePaths(dir); err != nil {
+		return nil, err
+	}
+
+	for _, part := range splitTurns(body) {
+		part = bytes.TrimLeft(part, " \t\r\n")
+		if len(part) == 0 {
+			continue
+		}
+
+		n := len(s.Turns) + 1
+		var t Turn
+		text, err := ParseFrontMatter(part, &t.Options)
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %w", n, err)
+		}
+		if t.Options.Output != "" || t.Options.Incognito {
+			return nil, fmt.Errorf("turn %d: output and incognito can only be set for the whole script", n)
+		}
+		if err := t.Options.ResolvePaths(dir); err != nil {
+			return nil, fmt.Errorf("turn %d: %w", n, err)
+		}
+		t.Query = strings.TrimSpace(string(text))
+		if t.Query == "" {
+			return nil, fmt.Errorf("turn %d has no query", n)
+		}
+		s.Turns = append(s.Turns, t)
+	}
+
+	if len(s.Turns) == 0 {
+		return nil, fmt.Errorf("script has no turns")
+	}
+	return s, nil
+}
+
+// TurnOptions returns the options of turn i: the script's options with the
+// turn's set fields on top. Attachments are sent only with the turn that
+// declares them, the script's with the first turn.
+func (s *Script) TurnOptions(i int) Options {
+	opts := s.Options
+	opts.Attachments = nil
+	if i == 0 {
+		opts.Attachments = append(opts.Attachments, s.Attachments...)
+	}
+
+	t := s.Turns[i].Options
+	if t.Mode != "" {
+		opts.Mode = t.Mode
+	}
+	if t.Model != "" {
+		opts.Model = t.Model
+	}
+	if len(t.Sources) > 0 {
+		opts.Sources = t.Sources
+	}
+	if t.Language != "" {
+		opts.Language = t.Language
+	}
+	opts.Attachments = append(opts.Attachments, t.Attachments...)
+	return opts
+}
+
+// splitTurns splits data at lines consisting of the turn delimiter.
+func splitTurns(data []byte) [][]byte {
+	var parts [][]byte
+	start := 0
+	for offset := 0; offset < len(data); {
+		line, _, more := bytes.Cut(data[offset:], []byte("\n"))
+		end := offset + len(line)
+		if more {
+			end++
+		}
+		if string(bytes.TrimRight(line, " \t\r")) == TurnDelim {
+			parts = append(parts, data[start:offset])
+			start = end
+		}
+		offset = end
+	}
+	return append(parts, data[start:])
+}
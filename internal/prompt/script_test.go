@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const researchScript = `---
+title: CRDT research
+mode: pro
+sources: [web]
+output: crdt.md
+incognito: true
+attachments: [notes.txt]
+---
+What are CRDTs?
++++
+---
+mode: reasoning
+model: gpt51
+---
+How do they compare
+to operational transformation?
++++
+
+Which libraries implement them in Go?
++++
+`
+
+func TestParseScript(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ParseScript([]byte(researchScript), dir)
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+	if s.Title != "CRDT research" || s.Output != filepath.Join(dir, "crdt.md") || !s.Incognito {
+		t.Errorf("script = %+v", s)
+	}
+
+	queries := make([]string, len(s.Turns))
+	for i, turn := range s.Turns {
+		queries[i] = turn.Query
+	}
+	want := []string{
+		"What are CRDTs?",
+		"How do they compare\nto operational transformation?",
+		"Which libraries implement them in Go?",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+
+	first := s.TurnOptions(0)
+	if first.Mode != "pro" || !reflect.DeepEqual(first.Attachments, []string{filepath.Join(dir, "notes.txt")}) {
+		t.Errorf("TurnOptions(0) = %+v", first)
+	}
+	second := s.TurnOptions(1)
+	if second.Mode != "reasoning" || second.Model != "gpt51" || len(second.Sources) != 1 || second.Attachments != nil {
+		t.Errorf("TurnOptions(1) = %+v", second)
+	}
+	if third := s.TurnOptions(2); third.Mode != "pro" || third.Model != "" {
+		t.Errorf("TurnOptions(2) = %+v", third)
+	}
+}
+
+func TestParseScriptWithoutFrontMatter(t *testing.T) {
+	s, err := ParseScript([]byte("first\n+++\nsecond"), "")
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+	if len(s.Turns) != 2 || s.Turns[0].Query != "first" || s.Turns[1].Query != "second" {
+		t.Errorf("turns = %+v", s.Turns)
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"no turns", "---\ntitle: x\n---\n+++\n", "no turns"},
+		{"per-turn output", "q1\n+++\n---\noutput: x.md\n---\nq2", "turn 2: output and incognito"},
+		{"empty turn", "q1\n+++\n---\nmode: pro\n---\n", "turn 2 has no query"},
+		{"unknown field", "q1\n+++\n---\nmodle: gpt51\n---\nq2", "turn 2: invalid front matter"},
+		{"missing attachment", "q1\n+++\n---\nattachments: [missing.txt]\n---\nq2", "turn 2: attachment missing.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScript([]byte(tt.data), t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseScript() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.md")
+	if err := os.WriteFile(path, []byte("only turn"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadScript(path)
+	if err != nil {
+		t.Fatalf("LoadScript() error = %v", err)
+	}
+	if s.Path != path || len(s.Turns) != 1 {
+		t.Errorf("script = %+v", s)
+	}
+
+	if _, err := LoadScript(path + ".missing"); err == nil || !strings.Contains(err.Error(), "failed to read script") {
+		t.Errorf("LoadScript(missing) error = %v", err)
+	}
+}
@@ -0,0 +1,362 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	// TemplateExt is the file extension of templates in a library.
+	TemplateExt = ".md"
+
+	// VarEnvPrefix prefixes environment variables that supply template
+	// variables, e.g. PERPLEXITY_VAR_TOPIC for "topic".
+	VarEnvPrefix = "PERPLEXITY_VAR_"
+)
+
+var (
+	nameRegex    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	varNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Options are search defaults carried by a prompt file. Empty fields leave
+// the configured defaults alone, and command-line flags override them.
+type Options struct {
+	Mode     string   `yaml:"mode"`
+	Model    string   `yaml:"model"`
+	Sources  []string `yaml:"sources"`
+	Language string   `yaml:"language"`
+
+	// Attachments are files uploaded with the query, inside the prompt
+	// file's directory and relative to it, or URLs attached as is.
+	Attachments []string `yaml:"attachments"`
+	// Output saves the answer like --output, inside the prompt file's
+	// directory and relative to it.
+	Output string `yaml:"output"`
+	// Incognito turns incognito on; a prompt file can't turn it off.
+	Incognito bool `yaml:"incognito"`
+}
+
+// ResolveAttachments makes relative attachment paths relative to dir and
+// checks that the files exist. Paths that lead outside dir, absolute or
+// through "..", are rejected so a prompt file from elsewhere can't upload
+// arbitrary local files.
+func (o *Options) ResolveAttachments(dir string) error {
+	for i, a := range o.Attachments {
+		if IsURL(a) {
+			continue
+		}
+		path, err := resolveInside(dir, a)
+		if err != nil {
+			return fmt.Errorf("attachment %s: %w", a, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("attachment %s: %w", a, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("attachment %s is a directory", a)
+		}
+		o.Attachments[i] = path
+	}
+	return nil
+}
+
+// ResolvePaths resolves the attachments and output path relative to dir,
+// rejecting any that lead outside it.
+func (o *Options) ResolvePaths(dir string) error {
+	if err := o.ResolveAttachments(dir); err != nil {
+		return err
+	}
+	if o.Output != "" {
+		path, err := resolveInside(dir, o.Output)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", o.Output, err)
+		}
+		o.Output = path
+	}
+	return nil
+}
+
+// resolveInside resolves path relative to dir and fails unless the result,
+// with symlinks followed, stays inside dir.
+func resolveInside(dir, path string) (string, error) {
+	base, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	path = filepath.Clean(path)
+	if !isInside(base, path) {
+		return "", fmt.Errorf("outside %s", base)
+	}
+
+	// Follow symlinks for the file, or its directory if it doesn't exist yet
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		real, err = filepath.EvalSymlinks(filepath.Dir(path))
+	}
+	realBase, baseErr := filepath.EvalSymlinks(base)
+	if err == nil && baseErr == nil && !isInside(realBase, real) {
+		return "", fmt.Errorf("outside %s", base)
+	}
+	return path, nil
+}
+
+// isInside reports whether path is dir or below it.
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// AttachmentFiles returns the attachments that are local files.
+func (o Options) AttachmentFiles() []string {
+	var files []string
+	for _, a := range o.Attachments {
+		if !IsURL(a) {
+			files = append(files, a)
+		}
+	}
+	return files
+}
+
+// AttachmentURLs returns the attachments that are URLs.
+func (o Options) AttachmentURLs() []string {
+	var urls []string
+	for _, a := range o.Attachments {
+		if IsURL(a) {
+			urls = append(urls, a)
+		}
+	}
+	return urls
+}
+
+// IsURL reports whether an attachment is a URL rather than a file.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// Var declares a template variable.
+type Var struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Default     string `yaml:"default"`
+	Required    bool   `yaml:"required"`
+	// Stdin makes piped input the value when no flag sets it.
+	Stdin bool `yaml:"stdin"`
+}
+
+// Template is a named prompt with declared variables and search defaults.
+type Template struct {
+	Name string `yaml:"-"`
+	Path string `yaml:"-"`
+	Body string `yaml:"-"`
+
+	Description string `yaml:"description"`
+	Vars        []Var  `yaml:"vars"`
+	Options     `yaml:",inline"`
+
+	tmpl *template.Template
+}
+
+// ParseTemplate parses a template file: YAML front matter declaring the
+// description, variables and options, followed by a text/template body.
+func ParseTemplate(name string, data []byte) (*Template, error) {
+	t := &Template{Name: name}
+	body, err := ParseFrontMatter(data, t)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.Body = strings.TrimSpace(string(body))
+	if t.Body == "" {
+		return nil, fmt.Errorf("template %s has an empty body", name)
+	}
+
+	seen := make(map[string]bool, len(t.Vars))
+	stdinVars := 0
+	for _, v := range t.Vars {
+		if !varNameRegex.MatchString(v.Name) {
+			return nil, fmt.Errorf("template %s: invalid variable name %q", name, v.Name)
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("template %s: variable %s declared twice", name, v.Name)
+		}
+		seen[v.Name] = true
+		if v.Stdin {
+			stdinVars++
+		}
+	}
+	if stdinVars > 1 {
+		return nil, fmt.Errorf("template %s: only one variable can read stdin", name)
+	}
+
+	t.tmpl, err = template.New(name).Option("missingkey=error").Parse(t.Body)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// Var returns the declared variable with the given name.
+func (t *Template) Var(name string) (Var, bool) {
+	for _, v := range t.Vars {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
+
+// StdinVar returns the variable that reads piped input, if any.
+func (t *Template) StdinVar() (Var, bool) {
+	for _, v := range t.Vars {
+		if v.Stdin {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
+
+// Resolve fills in variable values. Explicit values win, then the
+// PERPLEXITY_VAR_<NAME> environment variable, then the declared default.
+// Undeclared and missing required variables are errors.
+func (t *Template) Resolve(given map[string]string, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	for name := range given {
+		if _, ok := t.Var(name); !ok {
+			return nil, fmt.Errorf("template %s has no variable %q (declared: %s)", t.Name, name, t.varNames())
+		}
+	}
+
+	values := make(map[string]string, len(t.Vars))
+	var missing []string
+	firstMissing := ""
+	for _, v := range t.Vars {
+		value, ok := given[v.Name]
+		if !ok && lookupEnv != nil {
+			value, ok = lookupEnv(EnvVarName(v.Name))
+		}
+		if !ok {
+			value = v.Default
+		}
+		if v.Required && strings.TrimSpace(value) == "" {
+			desc := v.Name
+			if v.Description != "" {
+				desc += " (" + v.Description + ")"
+			}
+			missing = append(missing, desc)
+			if firstMissing == "" {
+				firstMissing = v.Name
+			}
+			continue
+		}
+		values[v.Name] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template %s is missing required variables: %s (set them with --var %s=... or %s)",
+			t.Name, strings.Join(missing, ", "), firstMissing, EnvVarName(firstMissing))
+	}
+	return values, nil
+}
+
+// Render executes the template body with resolved variable values.
+func (t *Template) Render(values map[string]string) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, values); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", t.Name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (t *Template) varNames() string {
+	if len(t.Vars) == 0 {
+		return "none"
+	}
+	names := make([]string, len(t.Vars))
+	for i, v := range t.Vars {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// EnvVarName returns the environment variable that supplies a template
+// variable.
+func EnvVarName(name string) string {
+	return VarEnvPrefix + strings.ToUpper(name)
+}
+
+// Library is a directory of prompt templates, one file per template.
+type Library struct {
+	dir string
+}
+
+// NewLibrary returns the library stored in dir.
+func NewLibrary(dir string) *Library {
+	return &Library{dir: dir}
+}
+
+// Dir returns the library directory.
+func (l *Library) Dir() string {
+	return l.dir
+}
+
+// Path returns the file of the named template.
+func (l *Library) Path(name string) string {
+	return filepath.Join(l.dir, name+TemplateExt)
+}
+
+// Load reads and parses the named template.
+func (l *Library) Load(name string) (*Template, error) {
+	if !nameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid template name: %q", name)
+	}
+
+	path := l.Path(name)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("template %s not found in %s", name, l.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	t, err := ParseTemplate(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ResolvePaths(l.dir); err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.Path = path
+	return t, nil
+}
+
+// Names returns the names of the templates in the library, sorted.
+func (l *Library) Names() ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt library: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), TemplateExt)
+		if e.IsDir() || !ok || !nameRegex.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,222 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const compareTemplate = `---
+description: Compare two technologies
+mode: pro
+model: gpt51
+sources: [web]
+vars:
+  - name: a
+    description: first technology
+    required: true
+  - name: b
+    required: true
+  - name: focus
+    default: performance
+---
+Compare {{.a}} and {{.b}}, focusing on {{.focus}}.
+`
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("compare", []byte(compareTemplate))
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	if tmpl.Description != "Compare two technologies" || tmpl.Mode != "pro" || tmpl.Model != "gpt51" {
+		t.Errorf("template = %+v", tmpl)
+	}
+	if len(tmpl.Vars) != 3 || !tmpl.Vars[0].Required || tmpl.Vars[2].Default != "performance" {
+		t.Errorf("vars = %+v", tmpl.Vars)
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty body", "---\nmode: pro\n---\n", "empty body"},
+		{"bad var name", "---\nvars:\n  - name: my-var\n---\nx", `invalid variable name "my-var"`},
+		{"duplicate var", "---\nvars:\n  - name: a\n  - name: a\n---\nx", "declared twice"},
+		{"two stdin vars", "---\nvars:\n  - name: a\n    stdin: true\n  - name: b\n    stdin: true\n---\nx", "only one variable"},
+		{"unknown field", "---\nvarz: []\n---\nx", "varz"},
+		{"bad template", "---\n---\n{{.a", "template t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate("t", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseTemplate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tmpl, err := ParseTemplate("compare", []byte(compareTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"PERPLEXITY_VAR_B": "Rust", "PERPLEXITY_VAR_FOCUS": "safety"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	values, err := tmpl.Resolve(map[string]string{"a": "Go", "focus": "tooling"}, lookup)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := map[string]string{"a": "Go", "b": "Rust", "focus": "tooling"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Resolve() = %v, want %v", values, want)
+	}
+
+	values, err = tmpl.Resolve(map[string]string{"a": "Go", "b": "Zig"}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if values["focus"] != "performance" {
+		t.Errorf("focus = %q, want the default", values["focus"])
+	}
+
+	got, err := tmpl.Render(values)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != "Compare Go and Zig, focusing on performance." {
+		t.Errorf("Render() = %q", got)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tmpl, err := ParseTemplate("compare", []byte(compareTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tmpl.Resolve(map[string]string{"b": " "}, nil)
+	want := "template compare is missing required variables: a (first technology), b (set them with --var a=... or PERPLEXITY_VAR_A)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Resolve() error = %v, want %q", err, want)
+	}
+
+	_, err = tmpl.Resolve(map[string]string{"a": "Go", "b": "Rust", "topic": "x"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `no variable "topic" (declared: a, b, focus)`) {
+		t.Errorf("Resolve() error = %v, want unknown variable", err)
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	dir := t.TempDir()
+	lib := NewLibrary(dir)
+
+	names, err := lib.Names()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("Names() on empty dir = %v, %v", names, err)
+	}
+
+	for name, content := range map[string]string{
+		"compare.md": compareTemplate,
+		"a-first.md": "Hello",
+		"notes.txt":  "ignored",
+		".hidden.md": "ignored",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err = lib.Names()
+	if err != nil {
+		t.Fatalf("Names() error = %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"a-first", "compare"}) {
+		t.Errorf("Names() = %v", names)
+	}
+
+	tmpl, err := lib.Load("compare")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if tmpl.Path != filepath.Join(dir, "compare.md") {
+		t.Errorf("Path = %q", tmpl.Path)
+	}
+
+	if _, err := lib.Load("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+	if _, err := lib.Load("../compare"); err == nil || !strings.Contains(err.Error(), "invalid template name") {
+		t.Errorf("Load(../compare) error = %v", err)
+	}
+}
+
+func TestResolveAttachments(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.csv"), []byte("a,b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Attachments: []string{"data.csv", "https://example.com/a.pdf"}}
+	if err := opts.ResolveAttachments(dir); err != nil {
+		t.Fatalf("ResolveAttachments() error = %v", err)
+	}
+	if got := opts.AttachmentFiles(); !reflect.DeepEqual(got, []string{filepath.Join(dir, "data.csv")}) {
+		t.Errorf("AttachmentFiles() = %v", got)
+	}
+	if got := opts.AttachmentURLs(); !reflect.DeepEqual(got, []string{"https://example.com/a.pdf"}) {
+		t.Errorf("AttachmentURLs() = %v", got)
+	}
+
+	for _, a := range []string{"missing.txt", "."} {
+		opts := Options{Attachments: []string{a}}
+		if err := opts.ResolveAttachments(dir); err == nil {
+			t.Errorf("ResolveAttachments(%q) error = nil", a)
+		}
+	}
+}
+
+func TestResolveAttachmentsOutsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "prompts")
+	secret := filepath.Join(root, "id_rsa")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secret, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "ok.txt"), []byte("ok"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range []string{secret, "../id_rsa", "sub/../../id_rsa", "link"} {
+		opts := Options{Attachments: []string{a}}
+		if err := opts.ResolveAttachments(dir); err == nil || !strings.Contains(err.Error(), "outside") {
+			t.Errorf("ResolveAttachments(%q) error = %v, want outside", a, err)
+		}
+	}
+
+	for _, a := range []string{"sub/ok.txt", filepath.Join(dir, "sub", "ok.txt"), "sub/../sub/ok.txt"} {
+		opts := Options{Attachments: []string{a}}
+		if err := opts.ResolveAttachments(dir); err != nil {
+			t.Errorf("ResolveAttachments(%q) error = %v", a, err)
+		}
+	}
+}
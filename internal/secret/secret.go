@@ -0,0 +1,267 @@
+// Package secret encrypts files at rest with a passphrase-derived key.
+//
+// Encrypted files start with a fixed header followed by the scrypt
+// parameters, the salt, the nonce and the AES-256-GCM ciphertext:
+//
+//	"PPLXENC1" | logN | r | p | salt (16) | nonce (12) | ciphertext
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	magic     = "PPLXENC1"
+	saltSize  = 16
+	keySize   = 32
+	headerLen = len(magic) + 3 + saltSize
+
+	// scrypt cost parameters for new files (N = 1<<logN).
+	defaultLogN = 15
+	defaultR    = 8
+	defaultP    = 1
+)
+
+// ErrWrongPassphrase is returned when a file cannot be decrypted, either
+// because the passphrase is wrong or the file was modified.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+
+// Source returns the passphrase. confirm is set when the passphrase will
+// encrypt data before any file has been decrypted with it, so interactive
+// sources should ask twice.
+type Source func(confirm bool) ([]byte, error)
+
+// params identifies a derived key.
+type params struct {
+	logN, r, p byte
+	salt       [saltSize]byte
+}
+
+var (
+	mu         sync.Mutex
+	enabled    bool
+	source     Source = EnvSource
+	passphrase []byte
+	verified   bool
+	keys       = map[params][]byte{}
+	writeKey   *params
+)
+
+// Configure sets whether files are encrypted when written and where the
+// passphrase comes from. It forgets any passphrase and keys cached so far.
+func Configure(encrypt bool, src Source) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if src == nil {
+		src = EnvSource
+	}
+	enabled, source = encrypt, src
+	passphrase, verified = nil, false
+	keys, writeKey = map[params][]byte{}, nil
+}
+
+// Enabled reports whether encryption at rest is on.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// IsEncrypted reports whether data is in the encrypted format.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// IsEncryptedFile reports whether the file at path exists and is encrypted.
+func IsEncryptedFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, len(magic))
+	n, _ := f.Read(head)
+	return IsEncrypted(head[:n])
+}
+
+// Encrypt seals plaintext with a key derived from the configured passphrase.
+func Encrypt(plaintext []byte) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if writeKey == nil {
+		p := params{logN: defaultLogN, r: defaultR, p: defaultP}
+		if _, err := rand.Read(p.salt[:]); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		if _, err := deriveKey(p, !verified); err != nil {
+			return nil, err
+		}
+		writeKey = &p
+	}
+
+	gcm, err := newGCM(keys[*writeKey])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, headerLen+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, magic...)
+	out = append(out, writeKey.logN, writeKey.r, writeKey.p)
+	out = append(out, writeKey.salt[:]...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, out[:headerLen]), nil
+}
+
+// Decrypt opens data produced by Encrypt. Data that is not encrypted is
+// returned unchanged.
+func Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if len(data) < headerLen {
+		return nil, ErrWrongPassphrase
+	}
+
+	var p params
+	p.logN, p.r, p.p = data[len(magic)], data[len(magic)+1], data[len(magic)+2]
+	copy(p.salt[:], data[len(magic)+3:headerLen])
+	if p.logN < 10 || p.logN > 22 || p.r == 0 || p.p == 0 {
+		return nil, fmt.Errorf("unsupported encryption parameters")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	key, err := deriveKey(p, false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := data[headerLen:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], data[:headerLen])
+	if err != nil {
+		// Never encrypt with a passphrase that failed to decrypt
+		delete(keys, p)
+		if !verified {
+			passphrase, writeKey = nil, nil
+			keys = map[params][]byte{}
+		}
+		return nil, ErrWrongPassphrase
+	}
+
+	verified = true
+	return plaintext, nil
+}
+
+// ReadFile reads a file, decrypting it if needed, and reports whether it was
+// encrypted.
+func ReadFile(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !IsEncrypted(data) {
+		return data, false, nil
+	}
+
+	plaintext, err := Decrypt(data)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decrypt %s: %w", filepath.Base(path), err)
+	}
+	return plaintext, true, nil
+}
+
+// WriteFile atomically writes data to path with 0600 permissions, encrypting
+// it first when encrypt is set.
+func WriteFile(path string, data []byte, encrypt bool) error {
+	if encrypt {
+		var err error
+		if data, err = Encrypt(data); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ShouldEncrypt reports whether a write to path must be encrypted: always
+// when encryption at rest is on, and otherwise only if the existing file is
+// already encrypted, so turning the setting off never leaks a file.
+func ShouldEncrypt(path string) bool {
+	return Enabled() || IsEncryptedFile(path)
+}
+
+// deriveKey returns the key for p, asking the source for the passphrase the
+// first time. The caller must hold mu.
+func deriveKey(p params, confirm bool) ([]byte, error) {
+	if key, ok := keys[p]; ok {
+		return key, nil
+	}
+
+	if passphrase == nil {
+		pass, err := source(confirm)
+		if err != nil {
+			return nil, err
+		}
+		if len(pass) == 0 {
+			return nil, fmt.Errorf("empty passphrase")
+		}
+		passphrase = pass
+	}
+
+	key, err := scrypt.Key(passphrase, p.salt[:], 1<<p.logN, int(p.r), int(p.p), keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	keys[p] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,143 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func staticSource(pass string) Source {
+	return func(confirm bool) ([]byte, error) {
+		return []byte(pass), nil
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	Configure(true, staticSource("correct horse"))
+	defer Configure(false, nil)
+
+	plaintext := []byte(`[{"name":"__Secure-next-auth.session-token","value":"secret"}]`)
+	data, err := Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(data) {
+		t.Error("IsEncrypted() = false for encrypted data")
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	// A fresh process derives the key again from the passphrase
+	Configure(false, staticSource("correct horse"))
+	got, err := Decrypt(data)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt() = %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecrypt_WrongPassphrase(t *testing.T) {
+	Configure(true, staticSource("right"))
+	defer Configure(false, nil)
+
+	data, err := Encrypt([]byte("history"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Configure(false, staticSource("wrong"))
+	if _, err := Decrypt(data); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Decrypt() error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	Configure(true, staticSource("pass"))
+	defer Configure(false, nil)
+
+	data, err := Encrypt([]byte("history"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 1
+
+	if _, err := Decrypt(data); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Decrypt() error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestDecrypt_Plaintext(t *testing.T) {
+	got, err := Decrypt([]byte("[]"))
+	if err != nil || string(got) != "[]" {
+		t.Errorf("Decrypt() = %q, %v; want plaintext unchanged", got, err)
+	}
+}
+
+func TestReadWriteFile(t *testing.T) {
+	Configure(false, staticSource("pass"))
+	defer Configure(false, nil)
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := WriteFile(path, []byte("plain"), false); err != nil {
+		t.Fatal(err)
+	}
+	if ShouldEncrypt(path) {
+		t.Error("ShouldEncrypt() = true for a plaintext file with encryption off")
+	}
+
+	if err := WriteFile(path, []byte("sealed"), true); err != nil {
+		t.Fatal(err)
+	}
+	if !ShouldEncrypt(path) {
+		t.Error("ShouldEncrypt() = false for an encrypted file")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %04o, want 0600", perm)
+	}
+
+	data, encrypted, err := ReadFile(path)
+	if err != nil || !encrypted || string(data) != "sealed" {
+		t.Errorf("ReadFile() = %q, %v, %v; want sealed, true, nil", data, encrypted, err)
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv(PassphraseEnvVar, "")
+	if _, err := EnvSource(false); err == nil {
+		t.Error("EnvSource() should fail without the variable")
+	}
+
+	t.Setenv(PassphraseEnvVar, "from-env")
+	if pass, err := EnvSource(false); err != nil || string(pass) != "from-env" {
+		t.Errorf("EnvSource() = %q, %v", pass, err)
+	}
+}
+
+func TestChainSource_KeyFile(t *testing.T) {
+	t.Setenv(PassphraseEnvVar, "")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pass, err := ChainSource(keyFile)(false)
+	if err != nil || string(pass) != "from-file" {
+		t.Errorf("ChainSource() = %q, %v; want from-file", pass, err)
+	}
+
+	t.Setenv(PassphraseEnvVar, "from-env")
+	pass, err = ChainSource(keyFile)(false)
+	if err != nil || string(pass) != "from-env" {
+		t.Errorf("ChainSource() = %q, %v; want the variable to win", pass, err)
+	}
+}
@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/term"
+)
+
+const (
+	// PassphraseEnvVar holds the passphrase itself.
+	PassphraseEnvVar = "PERPLEXITY_PASSPHRASE"
+
+	ttyPath = "/dev/tty"
+)
+
+// EnvSource reads the passphrase from PERPLEXITY_PASSPHRASE.
+func EnvSource(confirm bool) ([]byte, error) {
+	if pass := os.Getenv(PassphraseEnvVar); pass != "" {
+		return []byte(pass), nil
+	}
+	return nil, fmt.Errorf("passphrase required: set %s", PassphraseEnvVar)
+}
+
+// ChainSource tries, in order, the PERPLEXITY_PASSPHRASE variable, the key
+// file (if set) and an interactive prompt on the terminal.
+func ChainSource(keyFile string) Source {
+	return func(confirm bool) ([]byte, error) {
+		if pass := os.Getenv(PassphraseEnvVar); pass != "" {
+			return []byte(pass), nil
+		}
+		if keyFile != "" {
+			return ReadKeyFile(keyFile)
+		}
+
+		pass, err := Prompt(confirm)
+		if err != nil {
+			return nil, fmt.Errorf("passphrase required: set %s, set passphrase_file or run in a terminal (%v)", PassphraseEnvVar, err)
+		}
+		return pass, nil
+	}
+}
+
+// ReadKeyFile reads a passphrase from the first line of a key file.
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if line, _, ok := bytes.Cut(data, []byte("\n")); ok {
+		data = line
+	}
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	if len(data) == 0 {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+	return data, nil
+}
+
+// Prompt asks for the passphrase on the controlling terminal, so it works
+// even when stdin is piped. With confirm set it asks twice.
+func Prompt(confirm bool) ([]byte, error) {
+	tty, err := openTTY()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	pass, err := readPassword(tty, "Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := readPassword(tty, "Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
+
+func openTTY() (*os.File, error) {
+	if runtime.GOOS == "windows" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("no terminal")
+		}
+		return os.NewFile(os.Stdin.Fd(), "stdin"), nil
+	}
+	return os.OpenFile(ttyPath, os.O_RDWR, 0)
+}
+
+func readPassword(tty *os.File, prompt string) ([]byte, error) {
+	fmt.Fprint(tty, prompt)
+	pass, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	return pass, err
+}
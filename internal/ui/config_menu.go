@@ -48,7 +48,7 @@ func RunInteractiveConfig(cfg *config.Config, cfgMgr *config.Manager) error {
 		selectForm := huh.NewForm(
 			huh.NewGroup(
 				huh.NewSelect[string]().
-					Title("Configuration").
+					Title(fmt.Sprintf("Configuration (profile: %s)", cfgMgr.Profile())).
 					Description("Select an option to modify").
 					Options(options...).
 					Value(&selected),
@@ -164,9 +164,14 @@ func handleConfigEdit(cfg *config.Config, key string) error {
 }
 
 func editModel(cfg *config.Config) error {
-	options := make([]huh.Option[string], len(models.AvailableModels))
-	for i, m := range models.AvailableModels {
-		options[i] = huh.NewOption(string(m), string(m))
+	catalog := models.ActiveCatalog()
+	options := make([]huh.Option[string], len(catalog.Models))
+	for i, m := range catalog.Models {
+		desc := m.Name
+		if m.Reasoning {
+			desc += " (reasoning)"
+		}
+		options[i] = huh.NewOption(fmt.Sprintf("%-24s %s", string(m.ID), DimStyle.Render(desc)), string(m.ID))
 	}
 
 	var selected string
@@ -192,13 +197,7 @@ func editModel(cfg *config.Config) error {
 }
 
 func editMode(cfg *config.Config) error {
-	modes := []models.Mode{
-		models.ModeFast,
-		models.ModePro,
-		models.ModeReasoning,
-		models.ModeDeepResearch,
-		models.ModeDefault,
-	}
+	modes := models.ActiveCatalog().ModeIDs()
 
 	options := make([]huh.Option[string], len(modes))
 	for i, m := range modes {
@@ -229,20 +228,8 @@ func editMode(cfg *config.Config) error {
 }
 
 func getModeDescription(m models.Mode) string {
-	switch m {
-	case models.ModeFast:
-		return "Quick responses"
-	case models.ModePro:
-		return "Balanced quality"
-	case models.ModeReasoning:
-		return "Deep analysis"
-	case models.ModeDeepResearch:
-		return "Comprehensive research"
-	case models.ModeDefault:
-		return "Standard mode"
-	default:
-		return ""
-	}
+	mode, _ := models.ActiveCatalog().Mode(m)
+	return mode.Description
 }
 
 func editLanguage(cfg *config.Config) error {
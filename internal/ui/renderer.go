@@ -2,14 +2,17 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/perplexity-go/internal/diff"
 	"github.com/diogo/perplexity-go/pkg/models"
 )
 
@@ -72,6 +75,13 @@ var (
 	DimStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245"))
 
+	DiffInsertStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("82"))
+
+	DiffDeleteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Strikethrough(true)
+
 	SpinnerChars = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 )
 
@@ -190,6 +200,90 @@ func (r *Renderer) RenderResponse(resp *models.SearchResponse) error {
 	return nil
 }
 
+// RenderThread renders every turn of a thread with its answer and sources.
+func (r *Renderer) RenderThread(thread *models.Thread) error {
+	r.RenderTitle(thread.Title)
+
+	for i, entry := range thread.Entries {
+		if i > 0 {
+			fmt.Fprintln(r.out)
+		}
+
+		fmt.Fprintf(r.out, "%s %s\n", CitationStyle.Render(fmt.Sprintf("[%d]", i+1)), entry.Query)
+
+		var meta []string
+		if !entry.CreatedAt.IsZero() {
+			meta = append(meta, entry.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		if entry.Mode != "" {
+			meta = append(meta, "Mode: "+entry.Mode)
+		}
+		if entry.Model != "" {
+			meta = append(meta, "Model: "+entry.Model)
+		}
+		if len(meta) > 0 {
+			fmt.Fprintln(r.out, DimStyle.Render("    "+strings.Join(meta, ", ")))
+		}
+
+		if entry.Answer != "" {
+			if err := r.RenderStyledResponse(entry.Answer); err != nil {
+				return err
+			}
+		}
+		r.RenderWebResults(entry.WebResults)
+	}
+
+	return nil
+}
+
+// RenderHistoryEntry renders a stored history entry with its metadata,
+// full answer and sources.
+func (r *Renderer) RenderHistoryEntry(entry models.HistoryEntry) error {
+	r.RenderTitle("History Entry")
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(r.out, "%-12s %s\n", label+":", value)
+		}
+	}
+
+	field("Timestamp", entry.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	field("Query", entry.Query)
+	field("Title", entry.Title)
+	field("Mode", entry.Mode)
+	field("Model", entry.Model)
+	field("Language", entry.Language)
+	if len(entry.Sources) > 0 {
+		sources := make([]string, len(entry.Sources))
+		for i, src := range entry.Sources {
+			sources[i] = string(src)
+		}
+		field("Sources", strings.Join(sources, ", "))
+	}
+	if !entry.Filters.IsEmpty() {
+		field("Filters", entry.Filters.String())
+	}
+	field("Attachments", strings.Join(entry.Attachments, ", "))
+	field("Status", entry.StatusOrDefault())
+	if entry.DurationMS > 0 {
+		field("Duration", entry.Duration().String())
+	}
+	field("Backend", entry.BackendUUID)
+	field("Origin", entry.Source)
+	field("Group", entry.Group)
+	field("Error", entry.Error)
+
+	if entry.Response != "" {
+		fmt.Fprintln(r.out)
+		if err := r.RenderStyledResponse(entry.Response); err != nil {
+			return err
+		}
+	}
+	r.RenderWebResults(entry.WebResults)
+
+	return nil
+}
+
 // RenderCitations renders source citations.
 func (r *Renderer) RenderCitations(citations []models.Citation) {
 	if len(citations) == 0 {
@@ -219,10 +313,12 @@ func (r *Renderer) RenderWebResults(results []models.WebResult) {
 		return
 	}
 
-	// Filter out internal calculator results
+	// Filter out internal calculator results, keeping each result's
+	// citation number
 	var filteredResults []models.WebResult
-	for _, wr := range results {
+	for i, wr := range results {
 		if wr.URL != "https://perplexity.ai" && wr.URL != "" {
+			wr.Index = wr.CitationNumber(i)
 			filteredResults = append(filteredResults, wr)
 		}
 	}
@@ -234,7 +330,7 @@ func (r *Renderer) RenderWebResults(results []models.WebResult) {
 	fmt.Fprintln(r.out)
 	fmt.Fprintln(r.out, DimStyle.Render("Sources:"))
 
-	for i, wr := range filteredResults {
+	for _, wr := range filteredResults {
 		title := wr.Title
 		if title == "" {
 			title = wr.Name
@@ -243,7 +339,7 @@ func (r *Renderer) RenderWebResults(results []models.WebResult) {
 			title = wr.URL
 		}
 
-		num := fmt.Sprintf("[%d]", i+1)
+		num := fmt.Sprintf("[%d]", wr.Index)
 		fmt.Fprintf(r.out, "%s %s\n", DimStyle.Render(num), CitationStyle.Render(title))
 		if wr.URL != "" && wr.URL != title {
 			fmt.Fprintf(r.out, "    %s\n", DimStyle.Render(wr.URL))
@@ -251,6 +347,176 @@ func (r *Renderer) RenderWebResults(results []models.WebResult) {
 	}
 }
 
+// RenderDiff renders a word-level diff. With colors, deletions are red and
+// struck through and insertions green; otherwise they are marked as
+// [-deleted-] and {+inserted+}.
+func (r *Renderer) RenderDiff(chunks []diff.Chunk) {
+	for _, c := range chunks {
+		switch c.Op {
+		case diff.Delete:
+			fmt.Fprint(r.out, r.diffText(c.Text, DiffDeleteStyle, "[-", "-]"))
+		case diff.Insert:
+			fmt.Fprint(r.out, r.diffText(c.Text, DiffInsertStyle, "{+", "+}"))
+		default:
+			fmt.Fprint(r.out, c.Text)
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
+// diffText styles changed text line by line so lipgloss does not pad
+// multi-line runs into a block.
+func (r *Renderer) diffText(text string, style lipgloss.Style, open, close string) string {
+	if !r.useColors {
+		return open + text + close
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderSourceDiff renders which source URLs were added and dropped.
+func (r *Renderer) RenderSourceDiff(added, removed []string, kept int) {
+	fmt.Fprintln(r.out)
+	fmt.Fprintln(r.out, DimStyle.Render(fmt.Sprintf("Sources: %d kept, %d added, %d dropped", kept, len(added), len(removed))))
+
+	for _, u := range added {
+		line := "+ " + u
+		if r.useColors {
+			line = DiffInsertStyle.Render(line)
+		}
+		fmt.Fprintln(r.out, line)
+	}
+	for _, u := range removed {
+		line := "- " + u
+		if r.useColors {
+			line = DiffDeleteStyle.Render(line)
+		}
+		fmt.Fprintln(r.out, line)
+	}
+}
+
+// MinComparisonColumnWidth is the narrowest column used when rendering a
+// comparison side by side.
+const MinComparisonColumnWidth = 40
+
+// ComparisonColumnStyle frames each answer of a side-by-side comparison.
+var ComparisonColumnStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(WarmColorPrimary).
+	Padding(0, 1)
+
+// RenderComparisonColumns renders the answers of a comparison side by side,
+// splitting width evenly between them.
+func (r *Renderer) RenderComparisonColumns(cmp *models.Comparison, width int) {
+	r.RenderTitle(cmp.Query)
+	if len(cmp.Answers) == 0 {
+		return
+	}
+
+	colWidth := width / len(cmp.Answers)
+	// Border and padding take two columns on each side
+	contentWidth := colWidth - 4
+	if contentWidth < 1 {
+		contentWidth = 1
+	}
+	md := newMarkdownRenderer(contentWidth, r.useColors)
+
+	columns := make([]string, len(cmp.Answers))
+	for i, a := range cmp.Answers {
+		var b strings.Builder
+		b.WriteString(r.bold(string(a.Model)) + "\n")
+		b.WriteString(DimStyle.Render(comparisonSummary(a)) + "\n")
+
+		switch {
+		case a.Error != "":
+			b.WriteString("\n" + ErrorStyle.Render("Error: "+a.Error))
+		case a.Answer != "":
+			body := normalizeMarkdownText(a.Answer)
+			if md != nil {
+				if rendered, err := md.Render(body); err == nil {
+					body = rendered
+				}
+			}
+			b.WriteString(strings.TrimRight(body, "\n "))
+		}
+
+		columns[i] = ComparisonColumnStyle.Width(colWidth - 2).Render(b.String())
+	}
+
+	fmt.Fprintln(r.out, lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+}
+
+// RenderComparisonStacked renders the answers of a comparison one after
+// another, for terminals too narrow for columns.
+func (r *Renderer) RenderComparisonStacked(cmp *models.Comparison) error {
+	r.RenderTitle(cmp.Query)
+
+	for i, a := range cmp.Answers {
+		if i > 0 {
+			fmt.Fprintln(r.out)
+		}
+
+		fmt.Fprintf(r.out, "%s %s\n", CitationStyle.Render(fmt.Sprintf("[%d]", i+1)), r.bold(string(a.Model)))
+		fmt.Fprintln(r.out, DimStyle.Render("    "+comparisonSummary(a)))
+
+		if a.Error != "" {
+			r.RenderError(errors.New(a.Error))
+			continue
+		}
+		if a.Answer != "" {
+			if err := r.RenderStyledResponse(a.Answer); err != nil {
+				return err
+			}
+		}
+		r.RenderWebResults(a.WebResults)
+	}
+
+	return nil
+}
+
+// comparisonSummary describes the mode, latency and source count of an answer.
+func comparisonSummary(a models.ComparisonAnswer) string {
+	sources := "1 source"
+	if n := len(a.WebResults); n != 1 {
+		sources = fmt.Sprintf("%d sources", n)
+	}
+	parts := []string{string(a.Mode), a.Duration().Round(100 * time.Millisecond).String(), sources}
+	if a.Status != "" && a.Status != models.HistoryStatusOK {
+		parts = append(parts, a.Status)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// bold renders text in bold when colors are enabled.
+func (r *Renderer) bold(text string) string {
+	if !r.useColors {
+		return text
+	}
+	return lipgloss.NewStyle().Bold(true).Render(text)
+}
+
+// newMarkdownRenderer creates a glamour renderer wrapping at width, or nil
+// if it cannot be created.
+func newMarkdownRenderer(width int, useColors bool) *glamour.TermRenderer {
+	style := "dark"
+	if !useColors {
+		style = "notty"
+	}
+	md, err := glamour.NewTermRenderer(
+		glamour.WithWordWrap(width),
+		glamour.WithStylePath(style),
+	)
+	if err != nil {
+		return nil
+	}
+	return md
+}
+
 // RenderStreamChunk renders a streaming chunk (token-by-token).
 func (r *Renderer) RenderStreamChunk(chunk models.StreamChunk) {
 	if chunk.Delta != "" {
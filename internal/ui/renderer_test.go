@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/diogo/perplexity-go/internal/diff"
 	"github.com/diogo/perplexity-go/pkg/models"
 )
 
@@ -317,6 +318,147 @@ func TestRenderResponseNoBlocks(t *testing.T) {
 	}
 }
 
+func TestRenderThread(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := NewRendererWithOptions(&buf, 80, false)
+
+	thread := &models.Thread{
+		Title: "Go questions",
+		Entries: []models.ThreadEntry{
+			{
+				Query:      "What is Go?",
+				Answer:     "Go is a programming language.",
+				Mode:       "copilot",
+				Model:      "gpt51",
+				WebResults: []models.WebResult{{Title: "Go", URL: "https://go.dev"}},
+			},
+			{
+				Query:  "And Rust?",
+				Answer: "Rust is a systems language.",
+			},
+		},
+	}
+
+	if err := r.RenderThread(thread); err != nil {
+		t.Fatalf("RenderThread() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"GO QUESTIONS",
+		"[1] What is Go?",
+		"Mode: copilot, Model: gpt51",
+		"Go is a programming language.",
+		"https://go.dev",
+		"[2] And Rust?",
+		"Rust is a systems language.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderHistoryEntry(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := NewRendererWithOptions(&buf, 80, false)
+
+	entry := models.HistoryEntry{
+		Query:       "What is Go?",
+		Mode:        "pro",
+		Model:       "gpt51",
+		Language:    "en-US",
+		Sources:     []models.Source{models.SourceWeb, models.SourceScholar},
+		Response:    "Go is a programming language [1].",
+		WebResults:  []models.WebResult{{Title: "The Go site", URL: "https://go.dev"}},
+		BackendUUID: "uuid-1",
+		DurationMS:  1500,
+		Status:      models.HistoryStatusError,
+		Error:       "stream closed",
+	}
+
+	if err := r.RenderHistoryEntry(entry); err != nil {
+		t.Fatalf("RenderHistoryEntry() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"What is Go?",
+		"gpt51",
+		"web, scholar",
+		"1.5s",
+		"error",
+		"stream closed",
+		"uuid-1",
+		"Go is a programming language",
+		"The Go site",
+		"https://go.dev",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := NewRendererWithOptions(&buf, 80, false)
+
+	r.RenderDiff(diff.Words("Go is fast.", "Go is quick."))
+	r.RenderSourceDiff([]string{"https://new.example"}, []string{"https://old.example"}, 2)
+
+	output := buf.String()
+	for _, want := range []string{
+		"Go is [-fast.-]{+quick.+}",
+		"2 kept, 1 added, 1 dropped",
+		"+ https://new.example",
+		"- https://old.example",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderComparison(t *testing.T) {
+	cmp := &models.Comparison{
+		Query: "What is Go?",
+		Answers: []models.ComparisonAnswer{
+			{Model: "gpt51", Mode: "pro", Answer: "Go is a language.", DurationMS: 1234, Status: models.HistoryStatusOK,
+				WebResults: []models.WebResult{{URL: "https://go.dev", Title: "Go"}}},
+			{Model: "gemini30pro", Mode: "reasoning", DurationMS: 500, Status: models.HistoryStatusError, Error: "rate limited"},
+		},
+	}
+
+	var buf bytes.Buffer
+	r, _ := NewRendererWithOptions(&buf, 80, false)
+	r.RenderComparisonColumns(cmp, 100)
+
+	output := buf.String()
+	for _, want := range []string{"gpt51", "gemini30pro", "pro · 1.2s · 1 source", "reasoning · 500ms · 0 sources · error", "Go is a language.", "rate limited"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("columns output should contain %q\n%s", want, output)
+		}
+	}
+	// Both answers start on the same line
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "gpt51") && !strings.Contains(line, "gemini30pro") {
+			t.Errorf("models should share a header line: %q", line)
+		}
+	}
+
+	buf.Reset()
+	if err := r.RenderComparisonStacked(cmp); err != nil {
+		t.Fatalf("RenderComparisonStacked() error = %v", err)
+	}
+	output = buf.String()
+	for _, want := range []string{"[1] gpt51", "[2] gemini30pro", "https://go.dev", "rate limited"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("stacked output should contain %q\n%s", want, output)
+		}
+	}
+}
+
 func TestSpinnerChars(t *testing.T) {
 	if len(SpinnerChars) == 0 {
 		t.Error("SpinnerChars should not be empty")
@@ -4,6 +4,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	http "github.com/bogdanfinn/fhttp"
 	"github.com/diogo/perplexity-go/internal/auth"
@@ -71,6 +72,8 @@ func New(cfg Config) (*Client, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to load cookies: %w", err)
 		}
+		// Expired cookies would only be rejected by the server
+		cookies, _ = auth.DropExpired(cookies, time.Now())
 		client.SetCookies(cookies)
 	} else if cfg.Cookies != nil {
 		client.SetCookies(cfg.Cookies)
@@ -135,6 +138,16 @@ func (c *Client) SearchStream(ctx context.Context, opts models.SearchOptions) (<
 	return c.searchStreamChannel(ctx, opts)
 }
 
+// SetDebugLogger logs the client's HTTP traffic to l with secrets masked.
+func (c *Client) SetDebugLogger(l *DebugLogger) error {
+	hc, ok := c.http.(*HTTPClient)
+	if !ok {
+		return fmt.Errorf("HTTP debug logging is not supported by this client")
+	}
+	hc.SetDebugLogger(l)
+	return nil
+}
+
 // Close closes the client and releases resources.
 func (c *Client) Close() error {
 	return c.http.Close()
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// maxDebugBody is the largest request body logged in full.
+const maxDebugBody = 64 << 10
+
+// DebugLogger writes HTTP traffic to a log with secrets masked by a
+// Redactor. It is safe for concurrent use.
+type DebugLogger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	redactor *Redactor
+	seq      int
+}
+
+// NewDebugLogger creates a logger writing to w.
+func NewDebugLogger(w io.Writer, redactor *Redactor) *DebugLogger {
+	return &DebugLogger{w: w, redactor: redactor}
+}
+
+// OpenDebugLog creates a logger appending to the file at path, masking
+// DefaultRedactPatterns plus extra patterns.
+func OpenDebugLog(path string, extra []string) (*DebugLogger, error) {
+	redactor, err := NewRedactor(extra)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open debug log: %w", err)
+	}
+
+	l := NewDebugLogger(f, redactor)
+	l.closer = f
+	return l, nil
+}
+
+// Redactor returns the redactor used by the logger.
+func (l *DebugLogger) Redactor() *Redactor {
+	return l.redactor
+}
+
+// Close closes the underlying log file, if the logger opened one.
+func (l *DebugLogger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// printf writes a line to the log.
+func (l *DebugLogger) printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format, args...)
+}
+
+// logRequest logs a request and returns its sequence number. The request
+// body is read and replaced so it can still be sent.
+func (l *DebugLogger) logRequest(req *http.Request, cookies []*http.Cookie) int {
+	l.redactor.AddCookies(cookies)
+
+	var b strings.Builder
+	l.mu.Lock()
+	l.seq++
+	id := l.seq
+	l.mu.Unlock()
+
+	fmt.Fprintf(&b, "=== #%d %s %s %s\n", id, time.Now().Format(time.RFC3339Nano), req.Method, l.redactor.URL(req.URL.String()))
+	writeDebugHeaders(&b, ">", req.Header, l.redactor)
+	if len(cookies) > 0 {
+		pairs := make([]string, len(cookies))
+		for i, cookie := range cookies {
+			pairs[i] = cookie.Name + "=" + cookie.Value
+		}
+		fmt.Fprintf(&b, "> Cookie: %s\n", l.redactor.Header("Cookie", strings.Join(pairs, "; ")))
+	}
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			fmt.Fprintf(&b, "> (failed to read body: %v)\n", err)
+		} else if len(data) > 0 {
+			fmt.Fprintf(&b, ">\n> %s\n", l.debugBody(data))
+		}
+	}
+
+	l.printf("%s", b.String())
+	return id
+}
+
+// debugBody formats a request body for the log, summarizing binary and
+// oversized bodies.
+func (l *DebugLogger) debugBody(data []byte) string {
+	if !utf8.Valid(data) {
+		return fmt.Sprintf("(%d bytes of binary data)", len(data))
+	}
+	if len(data) > maxDebugBody {
+		return fmt.Sprintf("%s... (%d bytes total)", l.redactor.Text(string(data[:maxDebugBody])), len(data))
+	}
+	return l.redactor.JSON(data)
+}
+
+// logResponse logs the status, timing and headers of a response and wraps
+// its body so raw events are logged as they are read.
+func (l *DebugLogger) logResponse(id int, resp *http.Response, err error, elapsed time.Duration) {
+	if err != nil {
+		l.printf("<<< #%d failed after %s: %s\n", id, elapsed.Round(time.Millisecond), l.redactor.Text(err.Error()))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<<< #%d %s (%s)\n", id, resp.Status, elapsed.Round(time.Millisecond))
+	writeDebugHeaders(&b, "<", resp.Header, l.redactor)
+	l.printf("%s", b.String())
+
+	resp.Body = &debugBody{ReadCloser: resp.Body, logger: l, id: id}
+}
+
+// writeDebugHeaders writes redacted headers in sorted order.
+func writeDebugHeaders(b *strings.Builder, prefix string, header http.Header, redactor *Redactor) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(b, "%s %s: %s\n", prefix, name, redactor.Header(name, value))
+		}
+	}
+}
+
+// debugBody logs a response body line by line as it is read, so streamed
+// SSE events appear in the log as they arrive.
+type debugBody struct {
+	io.ReadCloser
+	logger  *DebugLogger
+	id      int
+	pending []byte
+	total   int
+	closed  bool
+}
+
+func (d *debugBody) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	d.total += n
+	d.pending = append(d.pending, p[:n]...)
+	for {
+		i := bytes.IndexByte(d.pending, '\n')
+		if i < 0 {
+			break
+		}
+		d.logLine(d.pending[:i])
+		d.pending = d.pending[i+1:]
+	}
+	if err != nil {
+		d.flush()
+	}
+	return n, err
+}
+
+func (d *debugBody) Close() error {
+	d.flush()
+	return d.ReadCloser.Close()
+}
+
+// logLine logs a line of the body, masking secrets in SSE data payloads.
+func (d *debugBody) logLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+
+	text := string(line)
+	if data, ok := strings.CutPrefix(text, "data:"); ok {
+		text = "data: " + d.logger.redactor.JSON([]byte(strings.TrimSpace(data)))
+	} else if bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+		text = d.logger.redactor.JSON(line)
+	} else {
+		text = d.logger.redactor.Text(text)
+	}
+	d.logger.printf("< %s\n", text)
+}
+
+// flush logs any partial line and the body size once.
+func (d *debugBody) flush() {
+	if d.closed {
+		return
+	}
+	d.closed = true
+	if len(d.pending) > 0 {
+		d.logLine(d.pending)
+		d.pending = nil
+	}
+	d.logger.printf("--- #%d body done (%d bytes)\n", d.id, d.total)
+}
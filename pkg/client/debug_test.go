@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	redactor, _ := NewRedactor(nil)
+	logger := NewDebugLogger(&buf, redactor)
+
+	body := `{"query_str":"What is Go?","params":{"csrf_token":"payload-secret"}}`
+	req, _ := http.NewRequest("POST", baseURL+searchPath, strings.NewReader(body))
+	req.Header = defaultHeaders(map[string]string{"X-Session-Id": "header-secret"})
+	cookies := []*http.Cookie{
+		{Name: "__Secure-next-auth.session-token", Value: "session-secret-value"},
+		{Name: "pplx.visitor-id", Value: "visitor"},
+	}
+
+	id := logger.logRequest(req, cookies)
+
+	sent, _ := io.ReadAll(req.Body)
+	if string(sent) != body {
+		t.Errorf("request body should be preserved, got %q", sent)
+	}
+
+	resp := &http.Response{
+		Status: "200 OK",
+		Header: http.Header{"Content-Type": {"text/event-stream"}, "Set-Cookie": {"cf_clearance=cf-secret-value; Path=/"}},
+		Body: io.NopCloser(strings.NewReader("event: message\r\n" +
+			`data: {"text":"Go","backend_uuid":"b1","echo":"session-secret-value"}` + "\r\n\r\n" +
+			"event: end_of_stream\r\ndata: {}")),
+	}
+	logger.logResponse(id, resp, nil, 1500*time.Millisecond)
+
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(data), "session-secret-value") {
+		t.Error("response body given to the caller should not be modified")
+	}
+
+	log := buf.String()
+	for _, want := range []string{
+		"=== #1 ",
+		"POST https://www.perplexity.ai/rest/sse/perplexity_ask",
+		"> User-Agent: Mozilla",
+		"> X-Session-Id: " + Redacted,
+		"> Cookie: __Secure-next-auth.session-token=" + Redacted + "; pplx.visitor-id=visitor",
+		`"query_str":"What is Go?"`,
+		"<<< #1 200 OK (1.5s)",
+		"< Content-Type: text/event-stream",
+		"< Set-Cookie: cf_clearance=" + Redacted + "; Path=/",
+		"< event: message",
+		`"backend_uuid":"b1"`,
+		"< event: end_of_stream",
+		"--- #1 body done",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log should contain %q\n%s", want, log)
+		}
+	}
+	for _, secret := range []string{"payload-secret", "header-secret", "session-secret-value", "cf-secret-value"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("log leaks %q\n%s", secret, log)
+		}
+	}
+	if strings.Count(log, "body done") != 1 {
+		t.Errorf("body summary should be logged once\n%s", log)
+	}
+}
+
+func TestDebugLoggerFailedRequest(t *testing.T) {
+	var buf bytes.Buffer
+	redactor, _ := NewRedactor(nil)
+	logger := NewDebugLogger(&buf, redactor)
+
+	req, _ := http.NewRequest("GET", baseURL+sessionPath, nil)
+	id := logger.logRequest(req, nil)
+	logger.logResponse(id, nil, errors.New("connection reset"), 20*time.Millisecond)
+
+	if log := buf.String(); !strings.Contains(log, "<<< #1 failed after 20ms: connection reset") {
+		t.Errorf("log = %s", log)
+	}
+}
+
+func TestDebugLoggerBinaryBody(t *testing.T) {
+	redactor, _ := NewRedactor(nil)
+	logger := NewDebugLogger(io.Discard, redactor)
+
+	if got := logger.debugBody([]byte{0xff, 0xfe, 0x00}); got != "(3 bytes of binary data)" {
+		t.Errorf("debugBody() = %q", got)
+	}
+	large := bytes.Repeat([]byte("a"), maxDebugBody+10)
+	if got := logger.debugBody(large); !strings.HasSuffix(got, "(65546 bytes total)") {
+		t.Errorf("debugBody() should truncate large bodies, got suffix %q", got[len(got)-30:])
+	}
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// publishedDateKeys are the meta_data keys that may carry a result's publication date.
+var publishedDateKeys = []string{"published_date", "date", "published_at", "publishedAt"}
+
+// publishedDateLayouts are the date layouts seen in result metadata.
+var publishedDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// FilterWebResults applies site and recency filters client-side and ranks
+// the remaining results: results from requested sites first, then newer
+// results, keeping the API order otherwise. Results without a known
+// publication date are kept, since their age cannot be checked. Each kept
+// result records its original position in Index, so citations still
+// resolve to the right source.
+func FilterWebResults(results []models.WebResult, filters *models.SearchFilters) []models.WebResult {
+	if filters.IsEmpty() {
+		return results
+	}
+
+	type ranked struct {
+		result    models.WebResult
+		preferred bool
+		date      time.Time
+	}
+
+	kept := make([]ranked, 0, len(results))
+	for i, wr := range results {
+		wr.Index = wr.CitationNumber(i)
+		host := resultHost(wr.URL)
+
+		if host != "" && matchesAnyDomain(host, filters.ExcludeSites) {
+			continue
+		}
+
+		preferred := host != "" && matchesAnyDomain(host, filters.Sites)
+		if len(filters.Sites) > 0 && host != "" && !preferred {
+			continue
+		}
+
+		date := resultDate(wr)
+		if !filters.Since.IsZero() && !date.IsZero() && date.Before(filters.Since) {
+			continue
+		}
+
+		kept = append(kept, ranked{result: wr, preferred: preferred, date: date})
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].preferred != kept[j].preferred {
+			return kept[i].preferred
+		}
+		return kept[i].date.After(kept[j].date)
+	})
+
+	filtered := make([]models.WebResult, len(kept))
+	for i, k := range kept {
+		filtered[i] = k.result
+	}
+	return filtered
+}
+
+// resultHost returns the normalized host of a result URL.
+func resultHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return models.NormalizeDomain(u.Host)
+}
+
+// matchesAnyDomain reports whether host equals or is a subdomain of any domain.
+func matchesAnyDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// resultDate extracts the publication date from result metadata, if any.
+func resultDate(wr models.WebResult) time.Time {
+	for _, key := range publishedDateKeys {
+		raw, ok := wr.MetaData[key].(string)
+		if !ok || raw == "" {
+			continue
+		}
+		for _, layout := range publishedDateLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
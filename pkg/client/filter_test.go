@@ -0,0 +1,142 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/internal/export"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestFilterWebResults(t *testing.T) {
+	results := []models.WebResult{
+		{URL: "https://old.example.com/a", MetaData: map[string]interface{}{"published_date": "2020-01-01"}},
+		{URL: "https://reddit.com/r/golang"},
+		{URL: "https://go.dev/doc", MetaData: map[string]interface{}{"date": "2025-05-01"}},
+		{URL: "https://blog.go.dev/post", MetaData: map[string]interface{}{"published_date": "2025-06-01T10:00:00Z"}},
+		{URL: "https://example.com/undated"},
+	}
+
+	t.Run("no filters returns input", func(t *testing.T) {
+		got := FilterWebResults(results, nil)
+		if len(got) != len(results) {
+			t.Errorf("len = %d, want %d", len(got), len(results))
+		}
+	})
+
+	t.Run("exclude site", func(t *testing.T) {
+		got := FilterWebResults(results, &models.SearchFilters{ExcludeSites: []string{"reddit.com"}})
+		for _, wr := range got {
+			if wr.URL == "https://reddit.com/r/golang" {
+				t.Error("excluded site should be removed")
+			}
+		}
+		if len(got) != 4 {
+			t.Errorf("len = %d, want 4", len(got))
+		}
+	})
+
+	t.Run("include site matches subdomains and ranks by date", func(t *testing.T) {
+		got := FilterWebResults(results, &models.SearchFilters{Sites: []string{"go.dev"}})
+		if len(got) != 2 {
+			t.Fatalf("len = %d, want 2: %+v", len(got), got)
+		}
+		if got[0].URL != "https://blog.go.dev/post" {
+			t.Errorf("newest result should rank first, got %s", got[0].URL)
+		}
+	})
+
+	t.Run("since drops dated results older than cutoff", func(t *testing.T) {
+		since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		got := FilterWebResults(results, &models.SearchFilters{Since: since})
+		for _, wr := range got {
+			if wr.URL == "https://old.example.com/a" {
+				t.Error("old result should be removed")
+			}
+		}
+		// Undated results are kept
+		if len(got) != 4 {
+			t.Errorf("len = %d, want 4", len(got))
+		}
+	})
+}
+
+func TestBuildSearchPayloadWithFilters(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	opts := models.SearchOptions{
+		Query: "generics",
+		Filters: &models.SearchFilters{
+			Sites:        []string{"go.dev"},
+			ExcludeSites: []string{"reddit.com"},
+			Since:        time.Now().AddDate(0, 0, -3),
+		},
+	}
+
+	payload, err := client.buildSearchPayload(opts)
+	if err != nil {
+		t.Fatalf("buildSearchPayload() error = %v", err)
+	}
+
+	var req models.SearchRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	if req.QueryStr != "generics" {
+		t.Errorf("QueryStr = %q, want %q", req.QueryStr, "generics")
+	}
+	if req.Params.DslQuery != "generics site:go.dev -site:reddit.com" {
+		t.Errorf("DslQuery = %q", req.Params.DslQuery)
+	}
+	if req.Params.SearchRecency == nil || *req.Params.SearchRecency != models.RecencyWeek {
+		t.Errorf("SearchRecency = %v, want %q", req.Params.SearchRecency, models.RecencyWeek)
+	}
+}
+
+func TestBuildSearchPayloadWithoutFilters(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	payload, err := client.buildSearchPayload(models.SearchOptions{Query: "plain"})
+	if err != nil {
+		t.Fatalf("buildSearchPayload() error = %v", err)
+	}
+
+	var req models.SearchRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if req.Params.DslQuery != "" || req.Params.SearchRecency != nil {
+		t.Errorf("expected no filters in payload, got dsl=%q recency=%v", req.Params.DslQuery, req.Params.SearchRecency)
+	}
+}
+
+func TestFilterWebResultsKeepsCitationNumbers(t *testing.T) {
+	results := []models.WebResult{
+		{URL: "https://reddit.com/r/golang"},
+		{URL: "https://go.dev/doc"},
+		{URL: "https://blog.go.dev/post", MetaData: map[string]interface{}{"published_date": "2025-06-01"}},
+	}
+
+	got := FilterWebResults(results, &models.SearchFilters{ExcludeSites: []string{"reddit.com"}})
+	answer := export.ResolveCitations("Docs [2], blog [3], forum [1].", got)
+
+	for _, want := range []string{"[\\[2\\]](https://go.dev/doc)", "[\\[3\\]](https://blog.go.dev/post)", "forum [1]."} {
+		if !strings.Contains(answer, want) {
+			t.Errorf("answer missing %q: %s", want, answer)
+		}
+	}
+	if got[0].Index != 3 || got[1].Index != 2 {
+		t.Errorf("Index = %d, %d; want 3, 2 (newest first, original numbers)", got[0].Index, got[1].Index)
+	}
+}
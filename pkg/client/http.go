@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"time"
 
 	http "github.com/bogdanfinn/fhttp"
 	tls_client "github.com/bogdanfinn/tls-client"
@@ -16,6 +17,8 @@ const (
 	searchPath   = "/rest/sse/perplexity_ask"
 	sessionPath  = "/api/auth/session"
 	uploadPath   = "/rest/uploads/create_upload_url"
+	threadPath   = "/rest/thread/"
+	libraryPath  = "/rest/thread/list_ask_threads"
 	userAgent    = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/133.0.0.0 Safari/537.36"
 )
 
@@ -53,6 +56,7 @@ type HTTPClientInterface interface {
 type HTTPClient struct {
 	client  tls_client.HttpClient
 	cookies []*http.Cookie
+	debug   *DebugLogger
 }
 
 // NewHTTPClient creates a new HTTP client with Chrome TLS fingerprint.
@@ -101,6 +105,12 @@ func cookiesSliceToMap(cookies []*http.Cookie) map[string]string {
 // buildHeaders returns common headers for Perplexity API requests.
 // It merges custom headers with default headers.
 func (c *HTTPClient) buildHeaders(customHeaders map[string]string) http.Header {
+	return defaultHeaders(customHeaders)
+}
+
+// defaultHeaders returns the browser-like headers sent with every request,
+// merged with customHeaders.
+func defaultHeaders(customHeaders map[string]string) http.Header {
 	headers := http.Header{
 		"Accept":             {"*/*"},
 		"Accept-Encoding":    {"gzip, deflate, br, zstd"},
@@ -146,7 +156,7 @@ func (c *HTTPClient) Get(urlStr string, headers map[string]string) (*http.Respon
 	}
 
 	req.Header = c.buildHeaders(headers)
-	return c.client.Do(req)
+	return c.do(req)
 }
 
 // Post performs a POST request with body.
@@ -159,7 +169,26 @@ func (c *HTTPClient) Post(urlStr string, body io.Reader, headers map[string]stri
 	}
 
 	req.Header = c.buildHeaders(headers)
-	return c.client.Do(req)
+	return c.do(req)
+}
+
+// do sends a request, logging it when a debug logger is set.
+func (c *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	if c.debug == nil {
+		return c.client.Do(req)
+	}
+
+	id := c.debug.logRequest(req, c.GetCookies())
+	started := time.Now()
+	resp, err := c.client.Do(req)
+	c.debug.logResponse(id, resp, err, time.Since(started))
+	return resp, err
+}
+
+// SetDebugLogger logs every request and response to l with secrets masked.
+// A nil logger disables logging.
+func (c *HTTPClient) SetDebugLogger(l *DebugLogger) {
+	c.debug = l
 }
 
 // PostWithReader performs a POST request with a reader and content type.
@@ -205,6 +234,9 @@ func (c *HTTPClient) GetCSRFToken() string {
 // Implements HTTPClientInterface.
 func (c *HTTPClient) Close() error {
 	// tls-client doesn't have explicit close
+	if c.debug != nil {
+		return c.debug.Close()
+	}
 	return nil
 }
 
@@ -273,7 +305,7 @@ func (m *MockHTTPClient) SetError(err error) {
 func (m *MockHTTPClient) Get(url string, headers map[string]string) (*http.Response, error) {
 	m.RequestCount++
 	m.LastRequestURL = url
-	return m.defaultResponse, m.defaultError
+	return m.next()
 }
 
 // Post simulates a POST request for testing.
@@ -284,7 +316,25 @@ func (m *MockHTTPClient) Post(url string, body io.Reader, headers map[string]str
 	if body != nil {
 		m.LastRequestBody, _ = io.ReadAll(body)
 	}
-	return m.defaultResponse, m.defaultError
+	return m.next()
+}
+
+// next returns the queued response for the current call, falling back to
+// the default response once Responses and Errors are exhausted.
+func (m *MockHTTPClient) next() (*http.Response, error) {
+	i := m.RequestCount - 1
+	var resp *http.Response
+	var err error
+	if i < len(m.Responses) {
+		resp = m.Responses[i]
+	}
+	if i < len(m.Errors) {
+		err = m.Errors[i]
+	}
+	if resp == nil && err == nil {
+		return m.defaultResponse, m.defaultError
+	}
+	return resp, err
 }
 
 // PostWithReader simulates a POST request with reader for testing.
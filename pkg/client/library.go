@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// DefaultLibraryPageSize is the number of threads requested per library page.
+const DefaultLibraryPageSize = 20
+
+// ListLibrary retrieves one page of the account's threads, newest first.
+func (c *Client) ListLibrary(ctx context.Context, offset, limit int) ([]models.LibraryThread, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultLibraryPageSize
+	}
+
+	reqBody, err := json.Marshal(models.LibraryListRequest{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal library request: %w", err)
+	}
+
+	resp, err := c.http.Post(libraryPath+"?version=2.18&source=default", bytes.NewReader(reqBody), nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var threads []models.LibraryThread
+	if err := json.NewDecoder(resp.Body).Decode(&threads); err != nil {
+		return nil, fmt.Errorf("failed to decode library: %w", err)
+	}
+
+	return threads, nil
+}
+
+// LibraryThreadAnswer extracts the answer text from a thread's first_answer
+// field, which may be a final-answer JSON document or plain text.
+func LibraryThreadAnswer(t models.LibraryThread) string {
+	text := strings.TrimSpace(t.FirstAnswer)
+	if strings.HasPrefix(text, "{") {
+		var final models.FinalAnswer
+		if err := json.Unmarshal([]byte(text), &final); err == nil {
+			return final.Answer
+		}
+	}
+	return text
+}
+
+// LibraryThreadTime parses the last query timestamp of a library thread,
+// returning the zero time on failure; callers pick their own fallback.
+func LibraryThreadTime(t models.LibraryThread) time.Time {
+	return parseThreadDate(t.LastQueryDatetime)
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestListLibrary(t *testing.T) {
+	body := `[` +
+		`{"uuid":"u1","slug":"what-is-go","title":"What is Go?","first_answer":"{\"answer\":\"Go is a language.\"}","mode":"copilot","display_model":"gpt51","last_query_datetime":"2025-03-01T10:00:00.000000"},` +
+		`{"uuid":"u2","backend_uuid":"b2","slug":"rust","title":"Rust","mode":"concise","first_entry_model_preference":"turbo","last_query_datetime":"2025-02-01T10:00:00"}` +
+		`]`
+
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	mock := NewMockHTTPClient()
+	mock.SetResponse(createTestResponse(200, body))
+	client.http = mock
+
+	threads, err := client.ListLibrary(context.Background(), 40, 20)
+	if err != nil {
+		t.Fatalf("ListLibrary() error = %v", err)
+	}
+
+	if !strings.HasPrefix(mock.LastRequestURL, "/rest/thread/list_ask_threads") {
+		t.Errorf("request URL = %q", mock.LastRequestURL)
+	}
+
+	var req models.LibraryListRequest
+	if err := json.Unmarshal(mock.LastRequestBody, &req); err != nil {
+		t.Fatalf("request body: %v", err)
+	}
+	if req.Offset != 40 || req.Limit != 20 || req.Ascending {
+		t.Errorf("request = %+v", req)
+	}
+
+	if len(threads) != 2 {
+		t.Fatalf("len(threads) = %d, want 2", len(threads))
+	}
+	if threads[0].ID() != "u1" || threads[1].ID() != "b2" {
+		t.Errorf("IDs = %q, %q", threads[0].ID(), threads[1].ID())
+	}
+	if threads[0].Model() != "gpt51" || threads[1].Model() != "turbo" {
+		t.Errorf("models = %q, %q", threads[0].Model(), threads[1].Model())
+	}
+	if got := LibraryThreadAnswer(threads[0]); got != "Go is a language." {
+		t.Errorf("LibraryThreadAnswer() = %q", got)
+	}
+	if got := LibraryThreadTime(threads[1]); !got.Equal(time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("LibraryThreadTime() = %v", got)
+	}
+}
+
+func TestListLibrary_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"unauthorized", 401, `{"detail":"unauthorized"}`},
+		{"invalid json", 200, `{"not":"a list"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(DefaultConfig())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer client.Close()
+
+			mock := NewMockHTTPClient()
+			mock.SetResponse(createTestResponse(tt.status, tt.body))
+			client.http = mock
+
+			if _, err := client.ListLibrary(context.Background(), 0, 20); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// Redacted replaces secret values in previews and logs.
+const Redacted = "[REDACTED]"
+
+// RequestPreview is a request as it would be sent, with secrets redacted.
+type RequestPreview struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// PreviewSearch builds the request a search would send without sending it.
+// Cookie values are redacted.
+func (c *Client) PreviewSearch(opts models.SearchOptions) (*RequestPreview, error) {
+	payload, err := c.buildSearchPayload(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build payload: %w", err)
+	}
+
+	preview := &RequestPreview{
+		Method:  "POST",
+		URL:     baseURL + searchPath,
+		Headers: make(map[string]string),
+		Body:    payload,
+	}
+	for name, values := range defaultHeaders(nil) {
+		preview.Headers[name] = strings.Join(values, ", ")
+	}
+	if cookie := redactedCookieHeader(c.cookies); cookie != "" {
+		preview.Headers["Cookie"] = cookie
+	}
+
+	return preview, nil
+}
+
+// SearchPayload decodes the preview body as a search request.
+func (p *RequestPreview) SearchPayload() (*models.SearchRequest, error) {
+	var req models.SearchRequest
+	if err := json.Unmarshal(p.Body, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// HeaderNames returns the preview header names in sorted order.
+func (p *RequestPreview) HeaderNames() []string {
+	names := make([]string, 0, len(p.Headers))
+	for name := range p.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// redactedCookieHeader returns a Cookie header listing the cookie names with
+// their values redacted.
+func redactedCookieHeader(cookies []*http.Cookie) string {
+	parts := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		parts = append(parts, cookie.Name+"="+Redacted)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+func TestPreviewSearch(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	client.SetCookies([]*http.Cookie{
+		{Name: "next-auth.csrf-token", Value: "secret-csrf|hash"},
+		{Name: "__Secure-next-auth.session-token", Value: "secret-session"},
+	})
+
+	preview, err := client.PreviewSearch(models.SearchOptions{
+		Query: "What is Go?",
+		Mode:  models.ModeFast,
+		Model: models.ModelClaude45Sonnet,
+	})
+	if err != nil {
+		t.Fatalf("PreviewSearch() error = %v", err)
+	}
+
+	if preview.Method != "POST" || preview.URL != "https://www.perplexity.ai/rest/sse/perplexity_ask" {
+		t.Errorf("request = %s %s", preview.Method, preview.URL)
+	}
+	if preview.Headers["User-Agent"] == "" {
+		t.Error("preview should include the default headers")
+	}
+
+	cookie := preview.Headers["Cookie"]
+	if !strings.Contains(cookie, "next-auth.csrf-token="+Redacted) || !strings.Contains(cookie, "session-token="+Redacted) {
+		t.Errorf("Cookie = %q", cookie)
+	}
+	for _, name := range preview.HeaderNames() {
+		if strings.Contains(preview.Headers[name], "secret") {
+			t.Errorf("header %s leaks a secret: %q", name, preview.Headers[name])
+		}
+	}
+	if strings.Contains(string(preview.Body), "secret") {
+		t.Error("body leaks a secret")
+	}
+
+	req, err := preview.SearchPayload()
+	if err != nil {
+		t.Fatalf("SearchPayload() error = %v", err)
+	}
+	if req.QueryStr != "What is Go?" || req.Params.Mode != "concise" {
+		t.Errorf("payload = %+v", req)
+	}
+	if req.Params.ModelPreference == nil || *req.Params.ModelPreference != "turbo" {
+		t.Error("fast mode should send turbo")
+	}
+}
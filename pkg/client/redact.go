@@ -0,0 +1,228 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// DefaultRedactPatterns are the cookie, header, query parameter and JSON
+// field names whose values are always masked. Patterns use path.Match
+// syntax and are matched case-insensitively.
+var DefaultRedactPatterns = []string{
+	"*session*",
+	"*token*",
+	"*csrf*",
+	"*secret*",
+	"*password*",
+	"*api_key*",
+	"*apikey*",
+	"cf_clearance",
+	"__cf_bm",
+	"_cfuvid",
+	"authorization",
+	"proxy-authorization",
+}
+
+// minSecretLength is the shortest value masked wherever it appears; shorter
+// values would mask unrelated text.
+const minSecretLength = 8
+
+// Redactor masks secret values in HTTP traffic by name (cookies, headers,
+// query parameters, JSON fields) and by value once a secret has been seen.
+type Redactor struct {
+	patterns []string
+
+	mu      sync.RWMutex
+	secrets map[string]bool
+}
+
+// NewRedactor creates a redactor using DefaultRedactPatterns plus extra.
+func NewRedactor(extra []string) (*Redactor, error) {
+	r := &Redactor{secrets: make(map[string]bool)}
+	for _, p := range append(append([]string(nil), DefaultRedactPatterns...), extra...) {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return r, nil
+}
+
+// Matches reports whether values named name must be masked.
+func (r *Redactor) Matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSecret masks value wherever it appears in later output.
+func (r *Redactor) AddSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+	r.mu.Lock()
+	r.secrets[value] = true
+	r.mu.Unlock()
+}
+
+// AddCookies records the values of secret cookies.
+func (r *Redactor) AddCookies(cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		if r.Matches(cookie.Name) {
+			r.AddSecret(cookie.Value)
+		}
+	}
+}
+
+// Text masks every known secret value in s.
+func (r *Redactor) Text(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Replace longer secrets first so a secret containing another is
+	// masked whole.
+	secrets := make([]string, 0, len(r.secrets))
+	for secret := range r.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+// Header masks a header value: secret headers entirely, cookies by name.
+func (r *Redactor) Header(name, value string) string {
+	switch strings.ToLower(name) {
+	case "cookie":
+		return r.cookieHeader(value)
+	case "set-cookie":
+		return r.setCookieHeader(value)
+	}
+	if r.Matches(name) {
+		return Redacted
+	}
+	return r.Text(value)
+}
+
+// cookieHeader masks the values of secret cookies in a Cookie header.
+func (r *Redactor) cookieHeader(value string) string {
+	parts := strings.Split(value, ";")
+	for i, part := range parts {
+		name, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		if r.Matches(name) {
+			r.AddSecret(val)
+			val = Redacted
+		}
+		parts[i] = name + "=" + r.Text(val)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// setCookieHeader masks the value of a secret cookie in a Set-Cookie header,
+// keeping its attributes.
+func (r *Redactor) setCookieHeader(value string) string {
+	pair, attrs, _ := strings.Cut(value, ";")
+	name, val, ok := strings.Cut(pair, "=")
+	if ok && r.Matches(strings.TrimSpace(name)) {
+		r.AddSecret(val)
+		pair = name + "=" + Redacted
+	}
+	if attrs != "" {
+		return pair + ";" + attrs
+	}
+	return pair
+}
+
+// URL masks secret query parameters and known secret values in a URL.
+func (r *Redactor) URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return r.Text(raw)
+	}
+
+	query := u.Query()
+	for name, values := range query {
+		if r.Matches(name) {
+			for i, v := range values {
+				r.AddSecret(v)
+				values[i] = Redacted
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return r.Text(u.String())
+}
+
+// JSON masks secret fields in a JSON document, including JSON nested in
+// string values. Non-JSON data is masked as text.
+func (r *Redactor) JSON(data []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return r.Text(string(data))
+	}
+
+	out, err := json.Marshal(r.redactValue(v))
+	if err != nil {
+		return r.Text(string(data))
+	}
+	return r.Text(string(out))
+}
+
+// redactValue masks secret fields in a decoded JSON value.
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, field := range val {
+			if r.Matches(key) {
+				if s, ok := field.(string); ok {
+					r.AddSecret(s)
+				}
+				if field != nil {
+					val[key] = Redacted
+				}
+				continue
+			}
+			val[key] = r.redactValue(field)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.redactValue(item)
+		}
+		return val
+	case string:
+		// The API nests JSON documents in string fields
+		trimmed := strings.TrimSpace(val)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var nested interface{}
+			if err := json.Unmarshal([]byte(trimmed), &nested); err == nil {
+				if out, err := json.Marshal(r.redactValue(nested)); err == nil {
+					return string(out)
+				}
+			}
+		}
+		return val
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestRedactorMatches(t *testing.T) {
+	r, err := NewRedactor([]string{"x-internal-*"})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	for _, name := range []string{"__Secure-next-auth.session-token", "next-auth.csrf-token", "cf_clearance", "Authorization", "read_write_token", "X-Internal-Trace"} {
+		if !r.Matches(name) {
+			t.Errorf("Matches(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"query_str", "backend_uuid", "User-Agent", "pplx.visitor-id"} {
+		if r.Matches(name) {
+			t.Errorf("Matches(%q) = true, want false", name)
+		}
+	}
+
+	if _, err := NewRedactor([]string{"[bad"}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestRedactorHeaders(t *testing.T) {
+	r, _ := NewRedactor(nil)
+
+	cookie := r.Header("Cookie", "pplx.visitor-id=abc; __Secure-next-auth.session-token=eyJhbGciOiJkaXIi.session; cf_clearance=clear-value-123")
+	if strings.Contains(cookie, "eyJhbGciOiJkaXIi") || strings.Contains(cookie, "clear-value-123") {
+		t.Errorf("Cookie not masked: %q", cookie)
+	}
+	if !strings.Contains(cookie, "pplx.visitor-id=abc") {
+		t.Errorf("non-secret cookie should be kept: %q", cookie)
+	}
+
+	setCookie := r.Header("Set-Cookie", "next-auth.csrf-token=csrf-secret-value; Path=/; HttpOnly")
+	if setCookie != "next-auth.csrf-token="+Redacted+"; Path=/; HttpOnly" {
+		t.Errorf("Set-Cookie = %q", setCookie)
+	}
+
+	if got := r.Header("Authorization", "Bearer abc"); got != Redacted {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := r.Header("Content-Type", "application/json"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	// Secrets seen in headers are masked everywhere afterwards
+	if got := r.Text("echo eyJhbGciOiJkaXIi.session and csrf-secret-value"); strings.Contains(got, "session") || strings.Contains(got, "csrf-secret") {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestRedactorJSONAndURL(t *testing.T) {
+	r, _ := NewRedactor(nil)
+	r.AddCookies([]*http.Cookie{{Name: "next-auth.csrf-token", Value: "known-csrf-token-value"}})
+
+	got := r.JSON([]byte(`{"query_str":"hi","csrfToken":"abc","nested":[{"access_token":"t0k3n"}],"text":"{\"read_write_token\":\"rw-secret\"}","echo":"known-csrf-token-value"}`))
+	for _, secret := range []string{`"abc"`, "t0k3n", "rw-secret", "known-csrf-token-value"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("JSON() leaks %s: %s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `"query_str":"hi"`) {
+		t.Errorf("JSON() should keep other fields: %s", got)
+	}
+
+	if got := r.JSON([]byte("not json known-csrf-token-value")); got != "not json "+Redacted {
+		t.Errorf("JSON() fallback = %q", got)
+	}
+
+	u := r.URL("https://www.perplexity.ai/api/auth/session?token=abc123&version=2.18")
+	if strings.Contains(u, "abc123") || !strings.Contains(u, "version=2.18") {
+		t.Errorf("URL() = %q", u)
+	}
+
+	r.AddSecret("short")
+	if got := r.Text("short"); got != "short" {
+		t.Errorf("short values should not be masked, got %q", got)
+	}
+}
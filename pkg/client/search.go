@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/diogo/perplexity-go/pkg/models"
 	"github.com/google/uuid"
@@ -17,30 +18,32 @@ import (
 func (c *Client) buildSearchPayload(opts models.SearchOptions) ([]byte, error) {
 	c.applyDefaults(&opts)
 
-	// Determine effective mode based on model
+	// Map mode and model to API values using the catalog. Unknown modes and
+	// models are passed through unchanged.
+	catalog := models.ActiveCatalog()
 	effectiveMode := string(opts.Mode)
 	modelPref := string(opts.Model)
 	isProReasoning := false
 
-	// Map mode to API values (unless overridden by special model)
-	switch opts.Mode {
-	case models.ModeFast:
-		effectiveMode = "concise"
-		modelPref = "turbo"
-	case models.ModePro, models.ModeDefault:
-		effectiveMode = "copilot"
-	case models.ModeReasoning:
-		effectiveMode = "copilot"
-		isProReasoning = true
-	case models.ModeDeepResearch:
-		effectiveMode = "copilot"
-		modelPref = "pplx_alpha"
+	mode, knownMode := catalog.Mode(opts.Mode)
+	if knownMode {
+		effectiveMode = mode.APIMode
+		isProReasoning = mode.Reasoning
+		if mode.APIModel != "" {
+			modelPref = mode.APIModel
+		}
 	}
 
-	// Handle special thinking models (forces fast mode regardless of mode setting)
-	if opts.Model == models.ModelGPT51Thinking {
-		effectiveMode = "concise"
-		modelPref = "turbo"
+	if model, ok := catalog.Model(string(opts.Model)); ok {
+		if !knownMode || mode.APIModel == "" {
+			modelPref = model.RequestID()
+		}
+		// Some models (e.g. gpt51_thinking) force their own API mode
+		// regardless of the selected mode.
+		if model.APIMode != "" {
+			effectiveMode = model.APIMode
+			modelPref = model.RequestID()
+		}
 	}
 
 	// Convert sources to strings
@@ -77,12 +80,26 @@ func (c *Client) buildSearchPayload(opts models.SearchOptions) ([]byte, error) {
 		req.Params.ModelPreference = &modelPref
 	}
 
+	// Encode source filters the API understands: site operators go into the
+	// DSL query, the since date into the recency filter.
+	if dsl := opts.Filters.DSL(); dsl != "" {
+		req.Params.DslQuery = opts.Query + " " + dsl
+	}
+	if recency := opts.Filters.RecencyFilter(time.Now()); recency != "" {
+		req.Params.SearchRecency = &recency
+	}
+
 	// Handle follow-up context
 	if opts.FollowUp != nil {
 		req.Params.BackendUUID = opts.FollowUp.BackendUUID
 		req.Params.Attachments = opts.FollowUp.Attachments
 	}
 
+	// Files uploaded for this query
+	for _, url := range opts.Attachments {
+		req.Params.Attachments = append(req.Params.Attachments, models.Attachment{URL: url})
+	}
+
 	return json.Marshal(req)
 }
 
@@ -161,14 +178,16 @@ func (c *Client) searchStreamChannel(ctx context.Context, opts models.SearchOpti
 		}
 
 		// Parse SSE stream
-		c.parseSSEStream(ctx, resp.Body, ch)
+		c.parseSSEStream(ctx, resp.Body, ch, opts.Filters)
 	}()
 
 	return ch, nil
 }
 
 // parseSSEStream parses Server-Sent Events from the response body.
-func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, ch chan<- models.StreamChunk) {
+// Web results are post-filtered against filters, since the API cannot
+// enforce every filter server-side.
+func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, ch chan<- models.StreamChunk, filters *models.SearchFilters) {
 	scanner := bufio.NewScanner(body)
 	// Use larger buffer for SSE chunks
 	buf := make([]byte, 0, 64*1024)
@@ -212,6 +231,9 @@ func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, ch chan<- m
 
 		// Parse SSE format: "event: message\r\ndata: {...}"
 		parsed := c.parseSSEChunk(chunk)
+		if len(parsed.WebResults) > 0 && !filters.IsEmpty() {
+			parsed.WebResults = FilterWebResults(parsed.WebResults, filters)
+		}
 		if parsed.Error != nil || parsed.Text != "" || parsed.Delta != "" || parsed.Done {
 			ch <- parsed
 		}
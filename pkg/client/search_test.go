@@ -108,6 +108,43 @@ func TestBuildSearchPayloadGPT5Thinking(t *testing.T) {
 	}
 }
 
+func TestBuildSearchPayloadCustomCatalog(t *testing.T) {
+	custom := &models.Catalog{
+		Models: []models.ModelInfo{
+			{ID: "newmodel", Name: "New Model", Aliases: []string{"nm"}, APIID: "new_model_v2", Modes: []models.Mode{models.ModePro}},
+		},
+	}
+	models.UseCatalog(models.DefaultCatalog().Merge(custom))
+	defer models.UseCatalog(models.DefaultCatalog())
+
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	payload, err := client.buildSearchPayload(models.SearchOptions{
+		Query: "test query",
+		Mode:  models.ModePro,
+		Model: "nm",
+	})
+	if err != nil {
+		t.Fatalf("buildSearchPayload() error = %v", err)
+	}
+
+	var req models.SearchRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	if req.Params.Mode != "copilot" {
+		t.Errorf("Mode = %q, want %q", req.Params.Mode, "copilot")
+	}
+	if req.Params.ModelPreference == nil || *req.Params.ModelPreference != "new_model_v2" {
+		t.Errorf("ModelPreference should be the catalog API id")
+	}
+}
+
 func TestBuildSearchPayloadReasoningMode(t *testing.T) {
 	cfg := DefaultConfig()
 	client, err := New(cfg)
@@ -207,6 +244,38 @@ func TestBuildSearchPayloadWithFollowUp(t *testing.T) {
 	}
 }
 
+func TestBuildSearchPayloadWithAttachments(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	opts := models.SearchOptions{
+		Query:       "review this",
+		Mode:        models.ModeDefault,
+		Attachments: []string{"https://example.com/stdin.txt"},
+		FollowUp: &models.FollowUpContext{
+			BackendUUID: "test-uuid-123",
+			Attachments: []models.Attachment{{URL: "https://example.com/file.pdf"}},
+		},
+	}
+
+	payload, err := client.buildSearchPayload(opts)
+	if err != nil {
+		t.Fatalf("buildSearchPayload() error = %v", err)
+	}
+
+	var req models.SearchRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	if len(req.Params.Attachments) != 2 || req.Params.Attachments[1].URL != "https://example.com/stdin.txt" {
+		t.Errorf("Attachments = %+v, want the follow-up file then the new upload", req.Params.Attachments)
+	}
+}
+
 func TestParseSSEChunk(t *testing.T) {
 	cfg := DefaultConfig()
 	client, err := New(cfg)
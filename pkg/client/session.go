@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	stdhttp "net/http"
+	"net/url"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// CheckSession asks /api/auth/session who the cookies belong to. It fails
+// when the request fails or the session is not logged in.
+func (c *Client) CheckSession(ctx context.Context) (*models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Get(sessionPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var session models.Session
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if session.User == nil {
+		return nil, fmt.Errorf("not logged in")
+	}
+
+	return &session, nil
+}
+
+// ProxyURL returns the proxy that the HTTPS_PROXY and NO_PROXY environment
+// variables select for Perplexity, or nil if none applies. It is only
+// reported by diagnostics; the client itself always connects directly.
+func ProxyURL() (*url.URL, error) {
+	req, err := stdhttp.NewRequest("GET", baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return stdhttp.ProxyFromEnvironment(req)
+}
+
+// CheckProxy opens a TCP connection to the proxy to confirm it is reachable.
+func CheckProxy(proxy *url.URL, timeout time.Duration) error {
+	host := proxy.Host
+	if proxy.Port() == "" {
+		port := "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(proxy.Hostname(), port)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
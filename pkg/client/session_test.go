@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCheckSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantEmail string
+		wantErr   bool
+	}{
+		{"logged in", 200, `{"user":{"id":"1","name":"Ada","email":"ada@example.com"},"expires":"2025-12-01T00:00:00.000Z"}`, "ada@example.com", false},
+		{"logged out", 200, `{}`, "", true},
+		{"server error", 500, `oops`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(DefaultConfig())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer client.Close()
+
+			mock := NewMockHTTPClient()
+			mock.SetResponse(createTestResponse(tt.status, tt.body))
+			client.http = mock
+
+			session, err := client.CheckSession(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mock.LastRequestURL != sessionPath {
+				t.Errorf("request URL = %q, want %q", mock.LastRequestURL, sessionPath)
+			}
+			if !tt.wantErr && session.User.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", session.User.Email, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestCheckProxy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	if err := CheckProxy(&url.URL{Scheme: "http", Host: addr}, time.Second); err != nil {
+		t.Errorf("CheckProxy() on listening proxy error = %v", err)
+	}
+
+	ln.Close()
+	if err := CheckProxy(&url.URL{Scheme: "http", Host: addr}, time.Second); err == nil {
+		t.Error("CheckProxy() expected error for closed port")
+	}
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/diogo/perplexity-go/pkg/models"
+)
+
+// threadDateLayouts are the timestamp layouts used by the thread endpoints.
+var threadDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02T15:04:05",
+}
+
+// ParseThreadID extracts the thread slug or UUID from a perplexity.ai URL
+// (e.g. https://www.perplexity.ai/search/<slug>) or returns the input as-is.
+func ParseThreadID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("thread URL or UUID is required")
+	}
+
+	if !strings.Contains(input, "perplexity.ai") {
+		return input, nil
+	}
+
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid thread URL: %w", err)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || (segments[0] != "search" && segments[0] != "page") || segments[1] == "" {
+		return "", fmt.Errorf("invalid thread URL: %s (expected perplexity.ai/search/<slug>)", input)
+	}
+
+	return segments[1], nil
+}
+
+// GetThread retrieves all turns of an existing thread by slug or UUID.
+func (c *Client) GetThread(ctx context.Context, id string) (*models.Thread, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := threadPath + url.PathEscape(id) + "?version=2.18&source=default&with_schematized_response=true"
+	resp, err := c.http.Get(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw models.ThreadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode thread: %w", err)
+	}
+
+	if len(raw.Entries) == 0 {
+		return nil, fmt.Errorf("thread not found or empty: %s", id)
+	}
+
+	return c.parseThread(id, raw), nil
+}
+
+// parseThread converts the raw thread payload into a Thread.
+func (c *Client) parseThread(id string, raw models.ThreadResponse) *models.Thread {
+	thread := &models.Thread{Slug: id}
+
+	for _, e := range raw.Entries {
+		if thread.Title == "" {
+			thread.Title = e.ThreadTitle
+		}
+		if e.ThreadURLSlug != "" {
+			thread.Slug = e.ThreadURLSlug
+		}
+
+		entry := models.ThreadEntry{
+			BackendUUID: e.BackendUUID,
+			Query:       e.QueryStr,
+			Mode:        e.Mode,
+			Model:       e.DisplayModel,
+			CreatedAt:   parseThreadDate(e.UpdatedDatetime),
+		}
+		entry.Answer, entry.WebResults = c.parseThreadAnswer(e)
+
+		thread.Entries = append(thread.Entries, entry)
+	}
+
+	if thread.Title == "" && len(thread.Entries) > 0 {
+		thread.Title = thread.Entries[0].Query
+	}
+
+	return thread
+}
+
+// parseThreadAnswer extracts the answer text and sources from a thread entry,
+// which may use the step-based, final-answer JSON or legacy block format.
+func (c *Client) parseThreadAnswer(e models.ThreadAPIEntry) (string, []models.WebResult) {
+	text := strings.TrimSpace(e.Text)
+
+	if strings.HasPrefix(text, "[{") && strings.Contains(text, "step_type") {
+		chunk := c.parseStepBasedResponse(text)
+		if chunk.Text != "" {
+			return chunk.Text, chunk.WebResults
+		}
+	}
+
+	if strings.HasPrefix(text, "{") {
+		var final models.FinalAnswer
+		if err := json.Unmarshal([]byte(text), &final); err == nil && final.Answer != "" {
+			return final.Answer, append(final.WebResults, final.ExtraWebResults...)
+		}
+	}
+
+	var answer string
+	var webResults []models.WebResult
+	for _, block := range c.parseBlocks(e.Blocks) {
+		if block.MarkdownBlock != nil && answer == "" {
+			answer = block.MarkdownBlock.Answer
+		}
+		if block.WebSearchResults != nil {
+			for _, r := range block.WebSearchResults.Results {
+				webResults = append(webResults, models.WebResult{URL: r.URL, Title: r.Title, Snippet: r.Snippet})
+			}
+		}
+	}
+	if answer != "" {
+		return answer, webResults
+	}
+
+	return text, webResults
+}
+
+// parseThreadDate parses a thread timestamp, returning the zero time on failure.
+func parseThreadDate(value string) time.Time {
+	for _, layout := range threadDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
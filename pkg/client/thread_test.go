@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseThreadID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"https://www.perplexity.ai/search/what-is-go-abc123", "what-is-go-abc123", false},
+		{"https://perplexity.ai/search/what-is-go-abc123?s=u", "what-is-go-abc123", false},
+		{"www.perplexity.ai/search/slug-1/", "slug-1", false},
+		{"https://www.perplexity.ai/page/some-page", "some-page", false},
+		{"3f2b8c1e-1111-2222-3333-444455556666", "3f2b8c1e-1111-2222-3333-444455556666", false},
+		{"  my-slug  ", "my-slug", false},
+		{"https://www.perplexity.ai/library", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseThreadID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreadID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseThreadID(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetThread(t *testing.T) {
+	stepText := `[{"step_type":"FINAL","content":{"answer":"{\"answer\":\"Go is a language.\",\"web_results\":[{\"name\":\"Go\",\"url\":\"https://go.dev\"}]}"},"uuid":"step-uuid"}]`
+	body := `{"status":"success","entries":[` +
+		`{"backend_uuid":"uuid-1","query_str":"What is Go?","text":` + quoteJSON(stepText) + `,"mode":"copilot","display_model":"gpt51","updated_datetime":"2025-01-02T03:04:05.123456","thread_title":"What is Go?","thread_url_slug":"what-is-go-abc"},` +
+		`{"backend_uuid":"uuid-2","query_str":"And Rust?","text":"{\"answer\":\"Rust is also a language.\"}","mode":"concise"}` +
+		`]}`
+
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	mock := NewMockHTTPClient()
+	mock.SetResponse(createTestResponse(200, body))
+	client.http = mock
+
+	thread, err := client.GetThread(context.Background(), "what-is-go-abc")
+	if err != nil {
+		t.Fatalf("GetThread() error = %v", err)
+	}
+
+	if !strings.HasPrefix(mock.LastRequestURL, "/rest/thread/what-is-go-abc?") {
+		t.Errorf("request URL = %q", mock.LastRequestURL)
+	}
+	if thread.Title != "What is Go?" || thread.Slug != "what-is-go-abc" {
+		t.Errorf("thread = %+v", thread)
+	}
+	if len(thread.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(thread.Entries))
+	}
+
+	first := thread.Entries[0]
+	if first.Answer != "Go is a language." {
+		t.Errorf("first answer = %q", first.Answer)
+	}
+	if len(first.WebResults) != 1 || first.WebResults[0].URL != "https://go.dev" {
+		t.Errorf("first web results = %+v", first.WebResults)
+	}
+	if first.Model != "gpt51" || first.Mode != "copilot" {
+		t.Errorf("first model/mode = %q/%q", first.Model, first.Mode)
+	}
+	if !first.CreatedAt.Equal(time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC)) {
+		t.Errorf("first CreatedAt = %v", first.CreatedAt)
+	}
+
+	if thread.Entries[1].Answer != "Rust is also a language." {
+		t.Errorf("second answer = %q", thread.Entries[1].Answer)
+	}
+	if thread.LastBackendUUID() != "uuid-2" {
+		t.Errorf("LastBackendUUID() = %q, want uuid-2", thread.LastBackendUUID())
+	}
+}
+
+func TestGetThread_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"not found", 404, `{"detail":"not found"}`},
+		{"invalid json", 200, `not json`},
+		{"empty thread", 200, `{"status":"success","entries":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(DefaultConfig())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer client.Close()
+
+			mock := NewMockHTTPClient()
+			mock.SetResponse(createTestResponse(tt.status, tt.body))
+			client.http = mock
+
+			if _, err := client.GetThread(context.Background(), "slug"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestGetThread_CancelledContext(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetThread(ctx, "slug"); err != context.Canceled {
+		t.Errorf("GetThread() error = %v, want context.Canceled", err)
+	}
+}
+
+// quoteJSON returns s encoded as a JSON string literal.
+func quoteJSON(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed catalog.json
+var embeddedCatalog []byte
+
+// Catalog lists the known search modes and models with the rules used to
+// map them to API parameters.
+type Catalog struct {
+	Modes  []ModeInfo  `json:"modes"`
+	Models []ModelInfo `json:"models"`
+}
+
+// ModeInfo describes a search mode.
+type ModeInfo struct {
+	ID          Mode   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// APIMode is the mode sent to the API (concise, copilot).
+	APIMode string `json:"api_mode"`
+	// APIModel, when set, replaces the selected model (e.g. turbo for fast).
+	APIModel string `json:"api_model,omitempty"`
+	// Reasoning enables the API's pro reasoning flag.
+	Reasoning bool `json:"reasoning,omitempty"`
+}
+
+// ModelInfo describes a model.
+type ModelInfo struct {
+	ID      Model    `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Modes   []Mode   `json:"modes"`
+	// Reasoning marks models that think before answering.
+	Reasoning bool `json:"reasoning,omitempty"`
+	// APIID is the model preference sent to the API; defaults to ID.
+	APIID string `json:"api_id,omitempty"`
+	// APIMode, when set, forces the API mode and model preference
+	// regardless of the selected mode.
+	APIMode string `json:"api_mode,omitempty"`
+}
+
+// RequestID returns the model preference sent to the API.
+func (m ModelInfo) RequestID() string {
+	if m.APIID != "" {
+		return m.APIID
+	}
+	return string(m.ID)
+}
+
+// SupportsMode reports whether the model is meant to be used in mode.
+func (m ModelInfo) SupportsMode(mode Mode) bool {
+	for _, s := range m.Modes {
+		if s == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultCatalog is the catalog embedded in the binary.
+var defaultCatalog = mustParseCatalog(embeddedCatalog)
+
+// activeCatalog is the catalog used for validation and requests.
+var activeCatalog = defaultCatalog
+
+// DefaultCatalog returns the built-in catalog. It must not be modified.
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// ActiveCatalog returns the catalog in use, which includes any user overrides.
+func ActiveCatalog() *Catalog {
+	return activeCatalog
+}
+
+// UseCatalog makes c the active catalog. It should be called once at startup.
+func UseCatalog(c *Catalog) {
+	activeCatalog = c
+}
+
+// ParseCatalog parses a catalog from JSON.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func mustParseCatalog(data []byte) *Catalog {
+	c, err := ParseCatalog(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded model catalog: %v", err))
+	}
+	if err := c.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid embedded model catalog: %v", err))
+	}
+	return c
+}
+
+// Merge returns a new catalog with the modes and models of o layered over c.
+// Entries of o replace entries of c with the same id; new ids are appended.
+func (c *Catalog) Merge(o *Catalog) *Catalog {
+	merged := &Catalog{
+		Modes:  append([]ModeInfo(nil), c.Modes...),
+		Models: append([]ModelInfo(nil), c.Models...),
+	}
+
+	for _, mode := range o.Modes {
+		replaced := false
+		for i := range merged.Modes {
+			if merged.Modes[i].ID == mode.ID {
+				merged.Modes[i] = mode
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Modes = append(merged.Modes, mode)
+		}
+	}
+
+	for _, model := range o.Models {
+		replaced := false
+		for i := range merged.Models {
+			if merged.Models[i].ID == model.ID {
+				merged.Models[i] = model
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Models = append(merged.Models, model)
+		}
+	}
+
+	return merged
+}
+
+// Validate checks that ids are set and unique, aliases are unambiguous and
+// models only reference known modes.
+func (c *Catalog) Validate() error {
+	modes := make(map[Mode]bool)
+	for _, mode := range c.Modes {
+		if mode.ID == "" {
+			return fmt.Errorf("mode without id")
+		}
+		if modes[mode.ID] {
+			return fmt.Errorf("duplicate mode: %s", mode.ID)
+		}
+		if mode.APIMode == "" {
+			return fmt.Errorf("mode %s: api_mode is required", mode.ID)
+		}
+		modes[mode.ID] = true
+	}
+
+	names := make(map[string]Model)
+	for _, model := range c.Models {
+		if model.ID == "" {
+			return fmt.Errorf("model without id")
+		}
+		for _, name := range append([]string{string(model.ID)}, model.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := names[key]; ok {
+				return fmt.Errorf("model %s: name %q is already used by %s", model.ID, name, other)
+			}
+			names[key] = model.ID
+		}
+		if len(model.Modes) == 0 {
+			return fmt.Errorf("model %s: at least one mode is required", model.ID)
+		}
+		for _, mode := range model.Modes {
+			if !modes[mode] {
+				return fmt.Errorf("model %s: unknown mode %s", model.ID, mode)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Mode returns the mode with the given id.
+func (c *Catalog) Mode(id Mode) (ModeInfo, bool) {
+	for _, mode := range c.Modes {
+		if mode.ID == id {
+			return mode, true
+		}
+	}
+	return ModeInfo{}, false
+}
+
+// Model returns the model with the given id or alias, ignoring case.
+func (c *Catalog) Model(name string) (ModelInfo, bool) {
+	name = strings.TrimSpace(name)
+	for _, model := range c.Models {
+		if strings.EqualFold(string(model.ID), name) {
+			return model, true
+		}
+	}
+	for _, model := range c.Models {
+		for _, alias := range model.Aliases {
+			if strings.EqualFold(alias, name) {
+				return model, true
+			}
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// ModelIDs returns the ids of all models, in catalog order.
+func (c *Catalog) ModelIDs() []Model {
+	ids := make([]Model, len(c.Models))
+	for i, model := range c.Models {
+		ids[i] = model.ID
+	}
+	return ids
+}
+
+// ModeIDs returns the ids of all modes, in catalog order.
+func (c *Catalog) ModeIDs() []Mode {
+	ids := make([]Mode, len(c.Modes))
+	for i, mode := range c.Modes {
+		ids[i] = mode.ID
+	}
+	return ids
+}
+
+// modelIDs returns the ids of the reasoning or non-reasoning models.
+func (c *Catalog) modelIDs(reasoning bool) []Model {
+	var ids []Model
+	for _, model := range c.Models {
+		if model.Reasoning == reasoning {
+			ids = append(ids, model.ID)
+		}
+	}
+	return ids
+}
+
+// ResolveModel returns the canonical id of a model id or alias in the
+// active catalog.
+func ResolveModel(name string) (Model, bool) {
+	model, ok := activeCatalog.Model(name)
+	return model.ID, ok
+}
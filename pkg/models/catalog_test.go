@@ -0,0 +1,117 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalog(t *testing.T) {
+	c := DefaultCatalog()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	for _, m := range AvailableReasoningModels {
+		info, ok := c.Model(string(m))
+		if !ok || !info.Reasoning || !info.SupportsMode(ModeReasoning) {
+			t.Errorf("reasoning model %s = %+v", m, info)
+		}
+	}
+
+	fast, ok := c.Mode(ModeFast)
+	if !ok || fast.APIMode != "concise" || fast.APIModel != "turbo" {
+		t.Errorf("fast mode = %+v", fast)
+	}
+
+	thinking, _ := c.Model(string(ModelGPT51Thinking))
+	if thinking.RequestID() != "turbo" || thinking.APIMode != "concise" {
+		t.Errorf("gpt51_thinking = %+v", thinking)
+	}
+	if info, _ := c.Model(string(ModelGPT51)); info.RequestID() != "gpt51" {
+		t.Errorf("gpt51 RequestID() = %q", info.RequestID())
+	}
+}
+
+func TestCatalogModelAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		want Model
+		ok   bool
+	}{
+		{"gpt51", ModelGPT51, true},
+		{"GPT-5.1", ModelGPT51, true},
+		{" claude ", ModelClaude45Sonnet, true},
+		{"Sonnet-Thinking", ModelClaude45SonnetThink, true},
+		{"gpt5", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ResolveModel(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolveModel(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCatalogMerge(t *testing.T) {
+	override, err := ParseCatalog([]byte(`{
+		"models": [
+			{"id": "gpt51", "name": "GPT-5.1 (custom)", "aliases": ["g"], "modes": ["pro"]},
+			{"id": "newmodel", "name": "New", "modes": ["reasoning"], "reasoning": true}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseCatalog() error = %v", err)
+	}
+
+	merged := DefaultCatalog().Merge(override)
+	if err := merged.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(merged.Models) != len(DefaultCatalog().Models)+1 {
+		t.Errorf("len(Models) = %d", len(merged.Models))
+	}
+	if info, ok := merged.Model("g"); !ok || info.Name != "GPT-5.1 (custom)" {
+		t.Errorf("Model(g) = %+v, %v", info, ok)
+	}
+	if _, ok := merged.Model("gpt-5.1"); ok {
+		t.Error("replaced model should lose its built-in aliases")
+	}
+	if info, _ := DefaultCatalog().Model("gpt51"); info.Name != "GPT-5.1" {
+		t.Error("Merge() should not modify the default catalog")
+	}
+
+	UseCatalog(merged)
+	defer UseCatalog(DefaultCatalog())
+	if !IsValidModel("newmodel") {
+		t.Error("IsValidModel(newmodel) should use the active catalog")
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog string
+		wantErr string
+	}{
+		{"duplicate alias", `{"models":[{"id":"x","modes":["pro"],"aliases":["claude"]}]}`, "already used"},
+		{"unknown mode", `{"models":[{"id":"x","modes":["turbo"]}]}`, "unknown mode"},
+		{"no modes", `{"models":[{"id":"x"}]}`, "at least one mode"},
+		{"missing id", `{"models":[{"name":"x","modes":["pro"]}]}`, "without id"},
+		{"mode without api mode", `{"modes":[{"id":"lite"}]}`, "api_mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override, err := ParseCatalog([]byte(tt.catalog))
+			if err != nil {
+				t.Fatalf("ParseCatalog() error = %v", err)
+			}
+			err = DefaultCatalog().Merge(override).Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
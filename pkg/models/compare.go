@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Comparison holds the answers of several models to the same query.
+type Comparison struct {
+	Query   string             `json:"query"`
+	Group   string             `json:"group,omitempty"`
+	Answers []ComparisonAnswer `json:"answers"`
+}
+
+// ComparisonAnswer is a single model's answer in a comparison.
+type ComparisonAnswer struct {
+	Model       Model       `json:"model"`
+	Mode        Mode        `json:"mode"`
+	Answer      string      `json:"answer,omitempty"`
+	WebResults  []WebResult `json:"web_results,omitempty"`
+	BackendUUID string      `json:"backend_uuid,omitempty"`
+	DurationMS  int64       `json:"duration_ms"`
+	Status      string      `json:"status"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// Duration returns how long the model took to answer.
+func (a ComparisonAnswer) Duration() time.Duration {
+	return time.Duration(a.DurationMS) * time.Millisecond
+}
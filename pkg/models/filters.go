@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recency filter values accepted by the search API.
+const (
+	RecencyHour  = "hour"
+	RecencyDay   = "day"
+	RecencyWeek  = "week"
+	RecencyMonth = "month"
+	RecencyYear  = "year"
+)
+
+// SearchFilters restricts which sources a search may use.
+type SearchFilters struct {
+	Sites        []string  `json:"sites,omitempty"`
+	ExcludeSites []string  `json:"exclude_sites,omitempty"`
+	Since        time.Time `json:"since,omitzero"`
+}
+
+// IsEmpty reports whether no filter is set.
+func (f *SearchFilters) IsEmpty() bool {
+	return f == nil || (len(f.Sites) == 0 && len(f.ExcludeSites) == 0 && f.Since.IsZero())
+}
+
+// String returns a short human-readable description of the filters.
+func (f *SearchFilters) String() string {
+	if f.IsEmpty() {
+		return "none"
+	}
+
+	var parts []string
+	if len(f.Sites) > 0 {
+		parts = append(parts, "site="+strings.Join(f.Sites, ","))
+	}
+	if len(f.ExcludeSites) > 0 {
+		parts = append(parts, "exclude-site="+strings.Join(f.ExcludeSites, ","))
+	}
+	if !f.Since.IsZero() {
+		parts = append(parts, "since="+f.Since.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// DSL returns the site operators understood by the search backend,
+// e.g. "(site:go.dev OR site:golang.org) -site:reddit.com".
+func (f *SearchFilters) DSL() string {
+	if f == nil {
+		return ""
+	}
+
+	var parts []string
+	if len(f.Sites) > 0 {
+		sites := make([]string, len(f.Sites))
+		for i, s := range f.Sites {
+			sites[i] = "site:" + s
+		}
+		if len(sites) == 1 {
+			parts = append(parts, sites[0])
+		} else {
+			parts = append(parts, "("+strings.Join(sites, " OR ")+")")
+		}
+	}
+	for _, s := range f.ExcludeSites {
+		parts = append(parts, "-site:"+s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// RecencyFilter maps Since to the narrowest API recency bucket that still
+// covers it. It returns "" when no bucket applies (older than a year).
+func (f *SearchFilters) RecencyFilter(now time.Time) string {
+	if f == nil || f.Since.IsZero() {
+		return ""
+	}
+
+	age := now.Sub(f.Since)
+	switch {
+	case age <= time.Hour:
+		return RecencyHour
+	case age <= 24*time.Hour:
+		return RecencyDay
+	case age <= 7*24*time.Hour:
+		return RecencyWeek
+	case age <= 31*24*time.Hour:
+		return RecencyMonth
+	case age <= 366*24*time.Hour:
+		return RecencyYear
+	}
+	return ""
+}
+
+// NormalizeDomain reduces a site argument (domain or URL) to a bare lowercase host.
+func NormalizeDomain(site string) string {
+	site = strings.TrimSpace(strings.ToLower(site))
+	if strings.Contains(site, "://") {
+		if u, err := url.Parse(site); err == nil && u.Host != "" {
+			site = u.Host
+		}
+	}
+	site = strings.TrimPrefix(site, "site:")
+	site = strings.TrimPrefix(site, "www.")
+	if idx := strings.IndexAny(site, "/:"); idx >= 0 {
+		site = site[:idx]
+	}
+	return strings.Trim(site, ".")
+}
+
+// ParseSince parses a relative age (30m, 12h, 7d, 2w, 3mo, 1y) or an absolute
+// date (2024-01-01 or RFC 3339) into the earliest allowed time.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	// Split into number and unit
+	i := 0
+	for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(value[:i])
+	if i == 0 || err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid since value: %s (expected e.g. 7d, 2w, 3mo, 1y or 2024-01-01)", value)
+	}
+
+	switch strings.ToLower(value[i:]) {
+	case "m", "min":
+		return now.Add(-time.Duration(n) * time.Minute), nil
+	case "h":
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case "d":
+		return now.AddDate(0, 0, -n), nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), nil
+	case "mo":
+		return now.AddDate(0, -n, 0), nil
+	case "y":
+		return now.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since unit in %s (use m, h, d, w, mo or y)", value)
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"30m", now.Add(-30 * time.Minute), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"3mo", now.AddDate(0, -3, 0), false},
+		{"1y", now.AddDate(-1, 0, 0), false},
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-01T10:00:00Z", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), false},
+		{"0d", time.Time{}, true},
+		{"7x", time.Time{}, true},
+		{"d", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSince(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"go.dev", "go.dev"},
+		{"  GO.dev ", "go.dev"},
+		{"www.example.com", "example.com"},
+		{"https://www.example.com/path?q=1", "example.com"},
+		{"site:arxiv.org", "arxiv.org"},
+		{"example.com/docs", "example.com"},
+		{"example.com:8080", "example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeDomain(tt.input); got != tt.want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFiltersDSL(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters *SearchFilters
+		want    string
+	}{
+		{"nil", nil, ""},
+		{"single site", &SearchFilters{Sites: []string{"go.dev"}}, "site:go.dev"},
+		{"multiple sites", &SearchFilters{Sites: []string{"go.dev", "golang.org"}}, "(site:go.dev OR site:golang.org)"},
+		{"exclude", &SearchFilters{ExcludeSites: []string{"reddit.com"}}, "-site:reddit.com"},
+		{"both", &SearchFilters{Sites: []string{"go.dev"}, ExcludeSites: []string{"a.com", "b.com"}}, "site:go.dev -site:a.com -site:b.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.DSL(); got != tt.want {
+				t.Errorf("DSL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchFiltersRecencyFilter(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  string
+	}{
+		{"unset", time.Time{}, ""},
+		{"30 minutes", now.Add(-30 * time.Minute), RecencyHour},
+		{"12 hours", now.Add(-12 * time.Hour), RecencyDay},
+		{"7 days", now.AddDate(0, 0, -7), RecencyWeek},
+		{"30 days", now.AddDate(0, 0, -30), RecencyMonth},
+		{"6 months", now.AddDate(0, -6, 0), RecencyYear},
+		{"2 years", now.AddDate(-2, 0, 0), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &SearchFilters{Since: tt.since}
+			if got := f.RecencyFilter(now); got != tt.want {
+				t.Errorf("RecencyFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchFiltersIsEmptyAndString(t *testing.T) {
+	var nilFilters *SearchFilters
+	if !nilFilters.IsEmpty() {
+		t.Error("nil filters should be empty")
+	}
+	if nilFilters.String() != "none" {
+		t.Errorf("String() = %q, want %q", nilFilters.String(), "none")
+	}
+
+	f := &SearchFilters{
+		Sites:        []string{"go.dev"},
+		ExcludeSites: []string{"reddit.com"},
+		Since:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if f.IsEmpty() {
+		t.Error("filters should not be empty")
+	}
+	want := "site=go.dev exclude-site=reddit.com since=2024-01-01"
+	if f.String() != want {
+		t.Errorf("String() = %q, want %q", f.String(), want)
+	}
+}
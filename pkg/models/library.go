@@ -0,0 +1,41 @@
+package models
+
+// LibraryListRequest is the payload sent to the library thread list endpoint.
+type LibraryListRequest struct {
+	Limit      int    `json:"limit"`
+	Ascending  bool   `json:"ascending"`
+	Offset     int    `json:"offset"`
+	SearchTerm string `json:"search_term"`
+}
+
+// LibraryThread is a thread summary as returned by the library list endpoint.
+type LibraryThread struct {
+	UUID              string `json:"uuid"`
+	BackendUUID       string `json:"backend_uuid,omitempty"`
+	Slug              string `json:"slug"`
+	Title             string `json:"title"`
+	QueryStr          string `json:"query_str,omitempty"`
+	FirstAnswer       string `json:"first_answer,omitempty"`
+	Mode              string `json:"mode"`
+	DisplayModel      string `json:"display_model"`
+	ModelPreference   string `json:"first_entry_model_preference,omitempty"`
+	LastQueryDatetime string `json:"last_query_datetime"`
+	QueryCount        int    `json:"query_count,omitempty"`
+	TotalThreads      int    `json:"total_threads,omitempty"`
+}
+
+// ID returns the backend UUID identifying the thread, falling back to its UUID.
+func (t LibraryThread) ID() string {
+	if t.BackendUUID != "" {
+		return t.BackendUUID
+	}
+	return t.UUID
+}
+
+// Model returns the model that answered the thread.
+func (t LibraryThread) Model() string {
+	if t.DisplayModel != "" {
+		return t.DisplayModel
+	}
+	return t.ModelPreference
+}
@@ -23,6 +23,7 @@ type SearchParams struct {
 	FunctioningMode    string       `json:"functioning_mode,omitempty"`
 	UseInhouseModel    bool         `json:"use_inhouse_model,omitempty"`
 	DslQuery           string       `json:"dsl_query"`
+	SearchRecency      *string      `json:"search_recency_filter,omitempty"`
 }
 
 // SearchRequest represents the payload for a Perplexity search query.
@@ -50,6 +51,7 @@ type SearchOptions struct {
 	Stream      bool
 	Attachments []string
 	FollowUp    *FollowUpContext
+	Filters     *SearchFilters
 }
 
 // FollowUpContext contains context for follow-up queries.
@@ -41,6 +41,19 @@ type WebResult struct {
 	Title     string                 `json:"title,omitempty"`
 	MetaData  map[string]interface{} `json:"meta_data,omitempty"`
 	SiteLinks []interface{}          `json:"sitelinks,omitempty"`
+	// Index is the 1-based position of the result in the server's list,
+	// which the answer's [n] markers refer to. It is set when results are
+	// filtered or reordered; zero means the result is still in place.
+	Index int `json:"index,omitempty"`
+}
+
+// CitationNumber returns the [n] marker that refers to the result found at
+// position i (0-based) of a result list.
+func (wr WebResult) CitationNumber(i int) int {
+	if wr.Index > 0 {
+		return wr.Index
+	}
+	return i + 1
 }
 
 // SearchResultsContent represents SEARCH_RESULTS step content.
@@ -125,12 +138,53 @@ type StreamChunk struct {
 	Chunks     []string    `json:"chunks,omitempty"`
 }
 
+// HistorySchemaVersion is the current HistoryEntry schema version. Entries
+// without a version predate it and may hold a truncated response.
+const HistorySchemaVersion = 2
+
+// History entry statuses.
+const (
+	HistoryStatusOK        = "ok"
+	HistoryStatusError     = "error"
+	HistoryStatusCancelled = "cancelled"
+)
+
 // HistoryEntry represents a query in the history file.
 type HistoryEntry struct {
-	Timestamp   time.Time `json:"timestamp"`
-	Query       string    `json:"query"`
-	Mode        string    `json:"mode"`
-	Model       string    `json:"model,omitempty"`
-	Response    string    `json:"response,omitempty"`
-	BackendUUID string    `json:"backend_uuid,omitempty"`
+	Version     int            `json:"version,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Query       string         `json:"query"`
+	Mode        string         `json:"mode"`
+	Model       string         `json:"model,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Sources     []Source       `json:"sources,omitempty"`
+	Response    string         `json:"response,omitempty"`
+	WebResults  []WebResult    `json:"web_results,omitempty"`
+	Attachments []string       `json:"attachments,omitempty"`
+	BackendUUID string         `json:"backend_uuid,omitempty"`
+	Filters     *SearchFilters `json:"filters,omitempty"`
+	DurationMS  int64          `json:"duration_ms,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	Slug        string         `json:"slug,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Group       string         `json:"group,omitempty"`
+}
+
+// HistorySourceLibrary marks history entries imported from the Perplexity library.
+const HistorySourceLibrary = "library"
+
+// Duration returns how long the query took.
+func (e HistoryEntry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// StatusOrDefault returns the entry status, treating entries recorded before
+// statuses existed as successful.
+func (e HistoryEntry) StatusOrDefault() string {
+	if e.Status == "" {
+		return HistoryStatusOK
+	}
+	return e.Status
 }
@@ -0,0 +1,15 @@
+package models
+
+// Session is the response of /api/auth/session. User is nil when the
+// cookies do not belong to a logged-in account.
+type Session struct {
+	User    *SessionUser `json:"user"`
+	Expires string       `json:"expires"`
+}
+
+// SessionUser identifies the logged-in account.
+type SessionUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Thread represents a Perplexity conversation thread with all of its turns.
+type Thread struct {
+	Slug    string        `json:"slug,omitempty"`
+	Title   string        `json:"title,omitempty"`
+	Entries []ThreadEntry `json:"entries"`
+}
+
+// ThreadEntry represents a single question and answer turn in a thread.
+type ThreadEntry struct {
+	BackendUUID string      `json:"backend_uuid,omitempty"`
+	Query       string      `json:"query"`
+	Answer      string      `json:"answer"`
+	Mode        string      `json:"mode,omitempty"`
+	Model       string      `json:"model,omitempty"`
+	CreatedAt   time.Time   `json:"created_at,omitzero"`
+	WebResults  []WebResult `json:"web_results,omitempty"`
+}
+
+// LastBackendUUID returns the backend UUID of the latest turn, used to
+// continue the thread with a follow-up.
+func (t *Thread) LastBackendUUID() string {
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		if t.Entries[i].BackendUUID != "" {
+			return t.Entries[i].BackendUUID
+		}
+	}
+	return ""
+}
+
+// ThreadResponse is the raw payload returned by the thread endpoint.
+type ThreadResponse struct {
+	Status  string           `json:"status"`
+	Entries []ThreadAPIEntry `json:"entries"`
+}
+
+// ThreadAPIEntry is a single turn as returned by the thread endpoint.
+type ThreadAPIEntry struct {
+	BackendUUID     string        `json:"backend_uuid"`
+	QueryStr        string        `json:"query_str"`
+	Text            string        `json:"text"`
+	Mode            string        `json:"mode"`
+	DisplayModel    string        `json:"display_model"`
+	UpdatedDatetime string        `json:"updated_datetime"`
+	ThreadTitle     string        `json:"thread_title"`
+	ThreadURLSlug   string        `json:"thread_url_slug"`
+	Blocks          []interface{} `json:"blocks,omitempty"`
+}
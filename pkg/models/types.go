@@ -42,26 +42,16 @@ const (
 	SourceSocial  Source = "social"
 )
 
-// AvailableProModels contains models available for Pro mode.
-var AvailableProModels = []Model{
-	ModelPplxPro,
-	ModelGPT51,
-	ModelGrok41NonReasoning,
-	ModelExperimental,
-	ModelClaude45Sonnet,
-}
+// AvailableProModels contains the built-in models available for Pro mode.
+var AvailableProModels = defaultCatalog.modelIDs(false)
 
-// AvailableReasoningModels contains models available for Reasoning mode.
-var AvailableReasoningModels = []Model{
-	ModelGemini30Pro,
-	ModelGPT51Thinking,
-	ModelGrok41Reasoning,
-	ModelKimiK2Thinking,
-	ModelClaude45SonnetThink,
-}
+// AvailableReasoningModels contains the built-in models available for
+// Reasoning mode.
+var AvailableReasoningModels = defaultCatalog.modelIDs(true)
 
-// AvailableModels contains all valid model names.
-var AvailableModels = append(AvailableProModels, AvailableReasoningModels...)
+// AvailableModels contains all built-in model names. The active catalog may
+// add more; see ActiveCatalog.
+var AvailableModels = defaultCatalog.ModelIDs()
 
 // AvailableSources contains all valid source names.
 var AvailableSources = []Source{
@@ -70,10 +60,10 @@ var AvailableSources = []Source{
 	SourceSocial,
 }
 
-// IsValidModel checks if a model name is valid.
+// IsValidModel checks if a model id is in the active catalog.
 func IsValidModel(m Model) bool {
-	for _, valid := range AvailableModels {
-		if m == valid {
+	for _, model := range activeCatalog.Models {
+		if m == model.ID {
 			return true
 		}
 	}
@@ -90,11 +80,8 @@ func IsValidSource(s Source) bool {
 	return false
 }
 
-// IsValidMode checks if a mode is valid.
+// IsValidMode checks if a mode is in the active catalog.
 func IsValidMode(m Mode) bool {
-	switch m {
-	case ModeFast, ModePro, ModeReasoning, ModeDeepResearch, ModeDefault:
-		return true
-	}
-	return false
+	_, ok := activeCatalog.Mode(m)
+	return ok
 }